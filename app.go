@@ -15,16 +15,24 @@ import (
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"heat-save-manager/internal/audit"
+	"heat-save-manager/internal/backups"
 	"heat-save-manager/internal/bundle"
 	"heat-save-manager/internal/config"
 	"heat-save-manager/internal/discovery"
 	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/gameproc"
 	"heat-save-manager/internal/health"
 	"heat-save-manager/internal/lifecycle"
 	"heat-save-manager/internal/marker"
+	"heat-save-manager/internal/oprunner"
 	"heat-save-manager/internal/profiles"
+	"heat-save-manager/internal/rootsync"
+	"heat-save-manager/internal/signing"
+	"heat-save-manager/internal/snapshot"
 	"heat-save-manager/internal/switcher"
 	"heat-save-manager/internal/updater"
+	"heat-save-manager/internal/workspace"
 )
 
 const (
@@ -32,8 +40,10 @@ const (
 	defaultUserAgent    = "heat-save-manager-update-check"
 	defaultHTTPTimeout  = 8 * time.Second
 	updateProgressEvent = "updater:progress"
-	languageEnglish     = "en"
-	languageSpanish     = "es"
+	operationsEvent     = "operations:changed"
+	operationProgress   = "operations:progress"
+	languageEnglish     = config.LanguageEnglish
+	languageSpanish     = config.LanguageSpanish
 )
 
 var appVersion = "dev"
@@ -45,6 +55,10 @@ type App struct {
 	profilesPath string
 	language     string
 	configStore  *config.Store
+	gameDetector gameproc.Detector
+	recovery     []lifecycle.RecoveryAction
+	operations   *oprunner.Runner
+	audit        *audit.Log
 }
 
 // NewApp creates a new App application struct
@@ -54,10 +68,18 @@ func NewApp() *App {
 		store = nil
 	}
 
-	return &App{
-		language:    config.DefaultLanguage,
-		configStore: store,
+	app := &App{
+		language:     config.DefaultLanguage,
+		configStore:  store,
+		gameDetector: gameproc.NewDefaultDetector(),
+		operations:   oprunner.New(),
 	}
+	if store != nil {
+		app.audit = audit.New(store.Dir())
+	}
+	app.operations.OnChange(app.emitOperationStatus)
+
+	return app
 }
 
 // startup is called when the app starts. The context is saved
@@ -66,10 +88,45 @@ func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	a.initDefaultPaths()
 	a.applySavedSettings()
+	a.recoverInterruptedOperations()
+}
+
+// recoverInterruptedOperations settles switches, saves, imports and deletes
+// that a crash left half-done before the UI can touch the same folders.
+func (a *App) recoverInterruptedOperations() {
+	if strings.TrimSpace(a.saveGamePath) == "" {
+		return
+	}
+
+	started := time.Now()
+	ws := a.workspace()
+	actions, err := ws.RecoverInterrupted()
+	ws.RecordRecovery(started, actions, err)
+	if err != nil {
+		return
+	}
+
+	a.recovery = actions
+}
+
+// GetRecoveryReport lists what startup recovery did with interrupted
+// operations; it is empty after a clean shutdown.
+func (a *App) GetRecoveryReport() []lifecycle.RecoveryAction {
+	if a.recovery == nil {
+		return []lifecycle.RecoveryAction{}
+	}
+
+	return a.recovery
 }
 
 type ProfileItem struct {
-	Name string `json:"name"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Tags           []string   `json:"tags"`
+	Color          string     `json:"color"`
+	CreatedAt      *time.Time `json:"createdAt,omitempty"`
+	LastSwitchedAt *time.Time `json:"lastSwitchedAt,omitempty"`
+	LastSavedAt    *time.Time `json:"lastSavedAt,omitempty"`
 }
 
 type AppPaths struct {
@@ -77,6 +134,41 @@ type AppPaths struct {
 	ProfilesPath string `json:"profilesPath"`
 }
 
+type BundleExportOptions struct {
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Passphrase  string `json:"passphrase"`
+	Sign        bool   `json:"sign"`
+}
+
+// OperationProgress is the payload of operationProgress events.
+type OperationProgress struct {
+	Kind   string `json:"kind"`
+	Target string `json:"target,omitempty"`
+	fsops.Progress
+}
+
+type SigningIdentity struct {
+	Exists      bool      `json:"exists"`
+	Author      string    `json:"author"`
+	PublicKey   string    `json:"publicKey"`
+	Fingerprint string    `json:"fingerprint"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type TrustedKeyItem struct {
+	Name        string    `json:"name"`
+	PublicKey   string    `json:"publicKey"`
+	Fingerprint string    `json:"fingerprint"`
+	AddedAt     time.Time `json:"addedAt"`
+}
+
+type GameStatus struct {
+	Running   bool               `json:"running"`
+	Checked   bool               `json:"checked"`
+	Processes []gameproc.Process `json:"processes"`
+}
+
 type UpdateInfo struct {
 	CurrentVersion  string `json:"currentVersion"`
 	LatestVersion   string `json:"latestVersion"`
@@ -112,32 +204,106 @@ func (a *App) initDefaultPaths() {
 	a.profilesPath = paths.ProfilesPath
 }
 
+// SetSaveGamePath waits for running operations so none of them sees the
+// paths change underneath it.
 func (a *App) SetSaveGamePath(saveGamePath string) error {
-	if err := a.applySaveGamePath(saveGamePath); err != nil {
-		return err
-	}
+	return a.runOperation("set-path", "", []string{saveGamePath}, func(ctx context.Context, entry *audit.Entry) error {
+		if err := a.applySaveGamePath(saveGamePath); err != nil {
+			return err
+		}
 
-	return a.updateConfig(func(cfg *config.AppConfig) {
-		cfg.SaveGamePath = a.saveGamePath
-		cfg.ProfilesPath = a.profilesPath
+		return a.updateConfig(func(cfg *config.AppConfig) {
+			cfg.SaveGamePath = a.saveGamePath
+			cfg.ProfilesPath = a.profilesPath
+		})
 	})
 }
 
 func (a *App) GetLanguage() string {
-	return normalizeLanguage(a.language)
+	return config.NormalizeLanguage(a.language)
 }
 
 func (a *App) SetLanguage(language string) error {
-	normalized, err := validateLanguage(language)
+	return a.recordChange("change-setting", "language", nil, func() error {
+		normalized, err := config.ValidateLanguage(language)
+		if err != nil {
+			return err
+		}
+
+		a.language = normalized
+
+		return a.updateConfig(func(cfg *config.AppConfig) {
+			cfg.Language = normalized
+		})
+	})
+}
+
+func (a *App) GetBackupBeforeSwitch() bool {
+	return a.loadConfigOrDefault().BackupBeforeSwitch
+}
+
+func (a *App) SetBackupBeforeSwitch(enabled bool) error {
+	return a.recordChange("change-setting", "backupBeforeSwitch", nil, func() error {
+		return a.updateConfig(func(cfg *config.AppConfig) {
+			cfg.BackupBeforeSwitch = enabled
+		})
+	})
+}
+
+func (a *App) GetSaveBeforeSwitch() bool {
+	return a.loadConfigOrDefault().SaveBeforeSwitch
+}
+
+func (a *App) SetSaveBeforeSwitch(enabled bool) error {
+	return a.recordChange("change-setting", "saveBeforeSwitch", nil, func() error {
+		return a.updateConfig(func(cfg *config.AppConfig) {
+			cfg.SaveBeforeSwitch = enabled
+		})
+	})
+}
+
+func (a *App) GetSyncCompareContent() bool {
+	return a.loadConfigOrDefault().SyncCompareContent
+}
+
+func (a *App) SetSyncCompareContent(enabled bool) error {
+	return a.recordChange("change-setting", "syncCompareContent", nil, func() error {
+		return a.updateConfig(func(cfg *config.AppConfig) {
+			cfg.SyncCompareContent = enabled
+		})
+	})
+}
+
+func (a *App) GetCheckGameRunning() bool {
+	return a.loadConfigOrDefault().CheckGameRunning
+}
+
+func (a *App) SetCheckGameRunning(enabled bool) error {
+	return a.recordChange("change-setting", "checkGameRunning", nil, func() error {
+		return a.updateConfig(func(cfg *config.AppConfig) {
+			cfg.CheckGameRunning = enabled
+		})
+	})
+}
+
+func (a *App) GetGameStatus() (GameStatus, error) {
+	status := GameStatus{Processes: []gameproc.Process{}}
+	if a.gameDetector == nil {
+		return status, nil
+	}
+
+	processes, err := a.gameDetector.FindRunning(gameproc.DefaultProcessNames)
 	if err != nil {
-		return err
+		return status, fmt.Errorf("detect running game: %w", err)
 	}
 
-	a.language = normalized
+	status.Checked = true
+	status.Running = len(processes) > 0
+	if processes != nil {
+		status.Processes = processes
+	}
 
-	return a.updateConfig(func(cfg *config.AppConfig) {
-		cfg.Language = normalized
-	})
+	return status, nil
 }
 
 func (a *App) GetAppVersion() string {
@@ -283,59 +449,208 @@ func (a *App) GetPaths() AppPaths {
 }
 
 func (a *App) ListProfiles() ([]ProfileItem, error) {
-	service := profiles.NewService(a.profilesPath)
-	items, err := service.List()
+	items, err := a.workspace().Profiles().List()
 	if err != nil {
 		return nil, err
 	}
 
 	result := make([]ProfileItem, 0, len(items))
 	for _, item := range items {
-		result = append(result, ProfileItem{Name: item.Name})
+		result = append(result, newProfileItem(item))
 	}
 
 	return result, nil
 }
 
+func newProfileItem(item profiles.Profile) ProfileItem {
+	tags := item.Metadata.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return ProfileItem{
+		Name:           item.Name,
+		Description:    item.Metadata.Description,
+		Tags:           tags,
+		Color:          item.Metadata.Color,
+		CreatedAt:      optionalTime(item.Metadata.CreatedAt),
+		LastSwitchedAt: optionalTime(item.Metadata.LastSwitchedAt),
+		LastSavedAt:    optionalTime(item.Metadata.LastSavedAt),
+	}
+}
+
+// optionalTime keeps never-set timestamps out of the JSON sent to the UI.
+func optionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+
+	return &value
+}
+
+// ScanProfiles reports every entry of the profiles folder, including the
+// ones ListProfiles leaves out, with the reason each is not a usable profile.
+func (a *App) ScanProfiles() ([]profiles.Candidate, error) {
+	return a.workspace().Profiles().Scan()
+}
+
+// RepairProfile fixes a profile that is only missing its wraps folder.
+func (a *App) RepairProfile(profileName string) error {
+	return a.runOperation("repair", profileName, []string{a.profileDir(profileName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.newLifecycleService().RepairProfile(profileName)
+	})
+}
+
+// QuarantineProfileEntry moves a broken profile or leftover artifact out of
+// the profiles folder and returns where it went.
+func (a *App) QuarantineProfileEntry(entryName string) (string, error) {
+	var destination string
+	err := a.runOperation("quarantine", entryName, []string{a.profileDir(entryName)}, func(ctx context.Context, entry *audit.Entry) error {
+		var err error
+		destination, err = a.newLifecycleService().QuarantineProfileEntry(entryName)
+		if destination != "" {
+			entry.Paths = append(entry.Paths, destination)
+		}
+		return err
+	})
+
+	return destination, err
+}
+
+// UpdateProfileDetails sets the description, tags and colour shown for a
+// profile.
+func (a *App) UpdateProfileDetails(profileName string, details profiles.Details) error {
+	return a.runOperation("edit-details", profileName, []string{a.profileDir(profileName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.newLifecycleService().UpdateProfileDetails(profileName, details)
+	})
+}
+
 func (a *App) GetActiveProfile() (string, error) {
 	return a.newMarkerStore().ReadActiveProfile()
 }
 
+// GetRootSyncStatus reports whether the root save has progress that is not in
+// the active profile's stored copy, so the UI can warn before replacing it.
+func (a *App) GetRootSyncStatus() (rootsync.Status, error) {
+	return a.workspace().RootSync().Check()
+}
+
+// IdentifyRootProfile ranks the profiles by how closely they match the root
+// save and suggests a profile when the active marker looks missing or wrong.
+func (a *App) IdentifyRootProfile() (rootsync.Identification, error) {
+	return a.workspace().RootSync().Identify()
+}
+
+// SetActiveMarker points the active marker at profileName, replacing a marker
+// that names the wrong profile. Unlike CreateMarkerFile it overwrites.
+func (a *App) SetActiveMarker(profileName string) error {
+	return a.runOperation("set-marker", profileName, []string{a.saveGamePath}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.newLifecycleService().SetActiveProfile(profileName)
+	})
+}
+
 func (a *App) SwitchProfile(profileName string) (switcher.Result, error) {
-	service := switcher.NewService(a.saveGamePath, a.profilesPath, a.newMarkerStore(), fsops.NewLocal())
+	var result switcher.Result
+	err := a.runOperation("switch", profileName, []string{a.saveGamePath, a.profileDir(profileName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.withProgress(ctx, "switch", profileName, func(ctx context.Context) error {
+			var err error
+			result, err = a.newLifecycleService().SwitchProfileContext(ctx, profileName, a.loadConfigOrDefault().SaveBeforeSwitch)
+			entry.RolledBack = result.RolledBack
+			return err
+		})
+	})
 
-	return service.Switch(switcher.Params{ProfileName: profileName})
+	return result, err
+}
+
+func (a *App) ListSwitchBackups() ([]backups.Backup, error) {
+	return a.workspace().SwitchBackups().List()
+}
+
+func (a *App) RestoreSwitchBackup(backupID string) error {
+	return a.runOperation("restore-backup", backupID, []string{a.saveGamePath}, func(ctx context.Context, entry *audit.Entry) error {
+		ws := a.workspace()
+		service := ws.Lifecycle()
+		service.SetSwitchBackups(ws.SwitchBackups())
+		return service.RestoreSwitchBackupContext(ctx, backupID)
+	})
+}
+
+func (a *App) DeleteSwitchBackup(backupID string) error {
+	return a.runOperation("delete-backup", backupID, nil, func(ctx context.Context, entry *audit.Entry) error {
+		return a.workspace().SwitchBackups().Delete(backupID)
+	})
 }
 
 func (a *App) PrepareFreshProfile(profileName string) error {
-	return a.newLifecycleService().PrepareFreshProfile(profileName)
+	return a.runOperation("fresh", profileName, []string{a.saveGamePath, a.profileDir(profileName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.newLifecycleService().PrepareFreshProfile(profileName)
+	})
 }
 
 func (a *App) PrepareFreshProfileWithoutSave(profileName string) error {
-	return a.newLifecycleService().PrepareFreshProfileWithoutSave(profileName)
+	return a.runOperation("fresh", profileName, []string{a.saveGamePath, a.profileDir(profileName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.newLifecycleService().PrepareFreshProfileWithoutSave(profileName)
+	})
 }
 
 func (a *App) SaveCurrentProfile(profileName string) error {
-	return a.newLifecycleService().SaveCurrentProfile(profileName)
+	return a.runOperation("save", profileName, []string{a.saveGamePath, a.profileDir(profileName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.withProgress(ctx, "save", profileName, func(ctx context.Context) error {
+			return a.newLifecycleService().SaveCurrentProfileContext(ctx, profileName)
+		})
+	})
 }
 
 func (a *App) RenameProfile(oldName string, newName string) error {
-	return a.newLifecycleService().RenameProfile(oldName, newName)
+	return a.runOperation("rename", oldName, []string{a.profileDir(oldName), a.profileDir(newName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.newLifecycleService().RenameProfile(oldName, newName)
+	})
 }
 
 func (a *App) DeleteProfile(profileName string) error {
-	return a.newLifecycleService().DeleteProfile(profileName)
+	return a.runOperation("delete", profileName, []string{a.profileDir(profileName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.newLifecycleService().DeleteProfile(profileName)
+	})
 }
 
 func (a *App) DeleteActiveProfile(replacementProfileName string) error {
-	return a.newLifecycleService().DeleteActiveProfile(replacementProfileName)
+	return a.runOperation("delete-active", "", []string{a.saveGamePath, a.profileDir(replacementProfileName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.newLifecycleService().DeleteActiveProfile(replacementProfileName)
+	})
+}
+
+func (a *App) ListProfileSnapshots(profileName string) ([]snapshot.Snapshot, error) {
+	return a.workspace().Snapshots().List(profileName)
+}
+
+func (a *App) InspectProfileSnapshot(profileName string, snapshotID string) (snapshot.Details, error) {
+	return a.workspace().Snapshots().Inspect(profileName, snapshotID)
+}
+
+func (a *App) RestoreProfileSnapshot(profileName string, snapshotID string) error {
+	return a.runOperation("restore-snapshot", profileName, []string{a.profileDir(profileName), a.saveGamePath}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.newLifecycleService().RestoreSnapshotContext(ctx, profileName, snapshotID)
+	})
+}
+
+func (a *App) DeleteProfileSnapshot(profileName string, snapshotID string) error {
+	return a.runOperation("delete-snapshot", profileName+"/"+snapshotID, nil, func(ctx context.Context, entry *audit.Entry) error {
+		return a.workspace().Snapshots().Delete(profileName, snapshotID)
+	})
 }
 
 func (a *App) RunHealthCheck() health.Report {
-	return health.NewService(a.saveGamePath, a.profilesPath).Run()
+	return a.workspace().Health().Run()
 }
 
 func (a *App) EnsureProfilesFolder() error {
+	return a.runOperation("ensure-profiles-folder", "", []string{a.profileDir("")}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.ensureProfilesFolder()
+	})
+}
+
+func (a *App) ensureProfilesFolder() error {
 	if strings.TrimSpace(a.saveGamePath) == "" {
 		return errors.New("savegame path is not configured")
 	}
@@ -352,11 +667,17 @@ func (a *App) EnsureProfilesFolder() error {
 }
 
 func (a *App) CreateMarkerFile(profileName string) error {
+	return a.runOperation("create-marker", profileName, []string{a.saveGamePath}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.createMarkerFile(profileName)
+	})
+}
+
+func (a *App) createMarkerFile(profileName string) error {
 	if strings.TrimSpace(a.saveGamePath) == "" {
 		return errors.New("savegame path is not configured")
 	}
 
-	if err := a.EnsureProfilesFolder(); err != nil {
+	if err := a.ensureProfilesFolder(); err != nil {
 		return err
 	}
 
@@ -420,15 +741,267 @@ func validateProfileNameInput(profileName string) (string, error) {
 		return "", errors.New("profile name contains invalid characters")
 	}
 
+	if profiles.IsArtifactName(trimmed) {
+		return "", profiles.ErrReservedProfileName
+	}
+
 	return trimmed, nil
 }
 
-func (a *App) ExportProfileBundle(profileName string, bundlePath string) error {
-	return bundle.NewService(a.profilesPath).ExportProfile(profileName, bundlePath)
+// ExportProfileBundle writes and verifies the bundle and reports its final
+// size and entry counts.
+func (a *App) ExportProfileBundle(profileName string, bundlePath string) (bundle.ExportResult, error) {
+	return a.ExportProfileBundleWithOptions(profileName, bundlePath, BundleExportOptions{})
 }
 
-func (a *App) ImportProfileBundle(profileName string, bundlePath string) error {
-	return bundle.NewService(a.profilesPath).ImportProfile(profileName, bundlePath)
+// ExportProfileBundleWithOptions exports with manifest details and, when
+// requested, encrypts the bundle and signs it with the local signing key.
+func (a *App) ExportProfileBundleWithOptions(profileName string, bundlePath string, options BundleExportOptions) (bundle.ExportResult, error) {
+	exportOptions := bundle.ExportOptions{
+		Description: options.Description,
+		Author:      options.Author,
+		Passphrase:  options.Passphrase,
+	}
+
+	if options.Sign {
+		keys, err := a.signingKeyStore()
+		if err != nil {
+			return bundle.ExportResult{}, err
+		}
+
+		identity, err := keys.Load()
+		if err != nil {
+			return bundle.ExportResult{}, err
+		}
+
+		exportOptions.Signer = &identity
+		if strings.TrimSpace(exportOptions.Author) == "" {
+			exportOptions.Author = identity.Author
+		}
+	}
+
+	var result bundle.ExportResult
+	err := a.runOperation("export", profileName, []string{a.profileDir(profileName), bundlePath}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.withProgress(ctx, "export", profileName, func(ctx context.Context) error {
+			var err error
+			result, err = a.workspace().Bundles().ExportProfileContext(ctx, profileName, bundlePath, exportOptions)
+			return err
+		})
+	})
+
+	return result, err
+}
+
+// InspectProfileBundle previews a bundle so the user can confirm the import.
+// Encrypted bundles only report that they need a passphrase.
+func (a *App) InspectProfileBundle(bundlePath string) (bundle.Inspection, error) {
+	return a.workspace().Bundles().Inspect(bundlePath, bundle.ImportOptions{})
+}
+
+func (a *App) InspectEncryptedProfileBundle(bundlePath string, passphrase string) (bundle.Inspection, error) {
+	return a.workspace().Bundles().Inspect(bundlePath, bundle.ImportOptions{Passphrase: passphrase})
+}
+
+// ImportProfileBundle imports the bundle and reports its signature state; a
+// result with Warning set imported fine but is unsigned or untrusted.
+// conflict is one of the bundle.Conflict* policies and only matters when the
+// profile already exists.
+func (a *App) ImportProfileBundle(profileName string, bundlePath string, conflict string) (bundle.ImportResult, error) {
+	return a.importProfileBundle(profileName, bundlePath, bundle.ImportOptions{Conflict: conflict})
+}
+
+func (a *App) ImportEncryptedProfileBundle(profileName string, bundlePath string, passphrase string, conflict string) (bundle.ImportResult, error) {
+	return a.importProfileBundle(profileName, bundlePath, bundle.ImportOptions{Passphrase: passphrase, Conflict: conflict})
+}
+
+func (a *App) importProfileBundle(profileName string, bundlePath string, options bundle.ImportOptions) (bundle.ImportResult, error) {
+	var result bundle.ImportResult
+	err := a.runOperation("import", profileName, []string{bundlePath, a.profileDir(profileName)}, func(ctx context.Context, entry *audit.Entry) error {
+		return a.withProgress(ctx, "import", profileName, func(ctx context.Context) error {
+			var err error
+			result, err = a.workspace().Bundles().ImportProfileContext(ctx, profileName, bundlePath, options)
+			return err
+		})
+	})
+
+	return result, err
+}
+
+// runOperation queues fn on the operation runner and records the result in
+// the audit log, including requests rejected before they could start. fn may
+// fill in details such as RolledBack on the entry it is given.
+func (a *App) runOperation(kind string, target string, paths []string, fn func(ctx context.Context, entry *audit.Entry) error) error {
+	return a.workspace().Record(kind, target, paths, func(entry *audit.Entry) error {
+		return a.operations.Run(kind, target, func(ctx context.Context) error {
+			return fn(ctx, entry)
+		})
+	})
+}
+
+// recordChange audits a call that only changes the app's own settings or
+// keys. Those never touch the SaveGame folder, so they are not queued behind
+// running operations.
+func (a *App) recordChange(kind string, target string, paths []string, fn func() error) error {
+	return a.workspace().Record(kind, target, paths, func(*audit.Entry) error {
+		return fn()
+	})
+}
+
+// profileDir is where profileName lives, or the Profiles folder when no
+// name is given.
+func (a *App) profileDir(profileName string) string {
+	if strings.TrimSpace(a.profilesPath) == "" {
+		return ""
+	}
+
+	return filepath.Join(a.profilesPath, strings.TrimSpace(profileName))
+}
+
+// GetAuditLog pages through recorded operations, newest first.
+func (a *App) GetAuditLog(filter audit.Filter) (audit.Page, error) {
+	if a.audit == nil {
+		return audit.Page{Entries: []audit.Entry{}}, nil
+	}
+
+	return a.audit.Query(filter)
+}
+
+// GetOperationStatus reports the running operation and the ones queued
+// behind it; the same status is pushed on every change as operationsEvent.
+func (a *App) GetOperationStatus() oprunner.Status {
+	return a.operations.Status()
+}
+
+// CancelOperation stops a queued operation, or asks the running one to roll
+// back. It reports whether the operation was still pending.
+func (a *App) CancelOperation(id int64) bool {
+	return a.operations.Cancel(id)
+}
+
+// withProgress runs fn with a context that reports file copy progress as
+// operationProgress events.
+func (a *App) withProgress(ctx context.Context, kind string, target string, fn func(context.Context) error) error {
+	ctx, tracker := fsops.WithProgress(ctx, func(progress fsops.Progress) {
+		a.emitOperationProgress(OperationProgress{Kind: kind, Target: target, Progress: progress})
+	})
+
+	err := fn(ctx)
+	tracker.Flush()
+	return err
+}
+
+func (a *App) emitOperationProgress(progress OperationProgress) {
+	if a.ctx == nil {
+		return
+	}
+
+	runtime.EventsEmit(a.ctx, operationProgress, progress)
+}
+
+func (a *App) emitOperationStatus(status oprunner.Status) {
+	if a.ctx == nil {
+		return
+	}
+
+	runtime.EventsEmit(a.ctx, operationsEvent, status)
+}
+
+func (a *App) GetSigningIdentity() (SigningIdentity, error) {
+	keys, err := a.signingKeyStore()
+	if err != nil {
+		return SigningIdentity{}, err
+	}
+
+	identity, err := keys.Load()
+	if err != nil {
+		if errors.Is(err, signing.ErrNoSigningKey) {
+			return SigningIdentity{}, nil
+		}
+		return SigningIdentity{}, err
+	}
+
+	return newSigningIdentity(identity), nil
+}
+
+func (a *App) GenerateSigningKey(author string) (SigningIdentity, error) {
+	var identity signing.Identity
+	err := a.recordChange("generate-signing-key", author, nil, func() error {
+		keys, err := a.signingKeyStore()
+		if err != nil {
+			return err
+		}
+
+		identity, err = keys.Generate(author)
+		return err
+	})
+	if err != nil {
+		return SigningIdentity{}, err
+	}
+
+	return newSigningIdentity(identity), nil
+}
+
+func (a *App) ListTrustedKeys() []TrustedKeyItem {
+	keys := a.loadConfigOrDefault().TrustedKeys
+	items := make([]TrustedKeyItem, 0, len(keys))
+	for _, key := range keys {
+		item := TrustedKeyItem{Name: key.Name, PublicKey: key.PublicKey, AddedAt: key.AddedAt}
+		if parsed, err := signing.ParsePublicKey(key.PublicKey); err == nil {
+			item.Fingerprint = signing.Fingerprint(parsed)
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func (a *App) AddTrustedKey(name string, publicKey string) error {
+	return a.recordChange("trust-key", name, nil, func() error {
+		cfg := a.loadConfigOrDefault()
+		keys, err := signing.AddTrustedKey(cfg.TrustedKeys, name, publicKey, time.Now())
+		if err != nil {
+			return err
+		}
+
+		return a.updateConfig(func(cfg *config.AppConfig) {
+			cfg.TrustedKeys = keys
+		})
+	})
+}
+
+func (a *App) RemoveTrustedKey(keyOrFingerprint string) error {
+	return a.recordChange("untrust-key", keyOrFingerprint, nil, func() error {
+		keys, removed := signing.RemoveTrustedKey(a.loadConfigOrDefault().TrustedKeys, keyOrFingerprint)
+		if !removed {
+			return errors.New("trusted key not found")
+		}
+
+		return a.updateConfig(func(cfg *config.AppConfig) {
+			cfg.TrustedKeys = keys
+		})
+	})
+}
+
+func (a *App) signingKeyStore() (*signing.KeyStore, error) {
+	if a.configStore == nil {
+		return nil, errors.New("config store is unavailable")
+	}
+
+	return signing.NewKeyStore(a.configStore.Dir()), nil
+}
+
+func newSigningIdentity(identity signing.Identity) SigningIdentity {
+	return SigningIdentity{
+		Exists:      true,
+		Author:      identity.Author,
+		PublicKey:   signing.EncodePublicKey(identity.PublicKey),
+		Fingerprint: identity.Fingerprint(),
+		CreatedAt:   identity.CreatedAt,
+	}
+}
+
+func (a *App) IsProfileBundleEncrypted(bundlePath string) (bool, error) {
+	return bundle.IsEncrypted(bundlePath)
 }
 
 func (a *App) PickExportBundlePath() (string, error) {
@@ -476,8 +1049,19 @@ func (a *App) PickSaveGamePath() (string, error) {
 	})
 }
 
+func (a *App) workspace() workspace.Workspace {
+	return workspace.Workspace{
+		SaveGamePath: a.saveGamePath,
+		ProfilesPath: a.profilesPath,
+		Config:       a.loadConfigOrDefault(),
+		GameDetector: a.gameDetector,
+		AppVersion:   appVersion,
+		AuditLog:     a.audit,
+	}
+}
+
 func (a *App) newLifecycleService() *lifecycle.Service {
-	return lifecycle.NewService(a.saveGamePath, a.profilesPath, a.newMarkerStore(), fsops.NewLocal())
+	return a.workspace().Lifecycle()
 }
 
 func (a *App) newMarkerStore() *marker.Store {
@@ -487,7 +1071,7 @@ func (a *App) newMarkerStore() *marker.Store {
 func (a *App) loadConfigOrDefault() config.AppConfig {
 	cfg := config.Default()
 	if a.configStore == nil {
-		cfg.Language = normalizeLanguage(cfg.Language)
+		cfg.Language = config.NormalizeLanguage(cfg.Language)
 		return cfg
 	}
 
@@ -496,7 +1080,7 @@ func (a *App) loadConfigOrDefault() config.AppConfig {
 		cfg = loaded
 	}
 
-	cfg.Language = normalizeLanguage(cfg.Language)
+	cfg.Language = config.NormalizeLanguage(cfg.Language)
 	return cfg
 }
 
@@ -511,7 +1095,7 @@ func (a *App) updateConfig(update func(*config.AppConfig)) error {
 	}
 
 	if strings.TrimSpace(cfg.Language) == "" {
-		cfg.Language = normalizeLanguage(a.language)
+		cfg.Language = config.NormalizeLanguage(a.language)
 	}
 
 	if err := a.configStore.Save(cfg); err != nil {
@@ -523,7 +1107,7 @@ func (a *App) updateConfig(update func(*config.AppConfig)) error {
 
 func (a *App) applySavedSettings() {
 	cfg := a.loadConfigOrDefault()
-	a.language = normalizeLanguage(cfg.Language)
+	a.language = config.NormalizeLanguage(cfg.Language)
 
 	if strings.TrimSpace(cfg.SaveGamePath) == "" {
 		return
@@ -532,67 +1116,18 @@ func (a *App) applySavedSettings() {
 	_ = a.applySaveGamePath(cfg.SaveGamePath)
 }
 
-func normalizeLanguage(language string) string {
-	switch strings.ToLower(strings.TrimSpace(language)) {
-	case languageSpanish, "es-es", "es-419", "es-mx":
-		return languageSpanish
-	default:
-		return languageEnglish
-	}
-}
-
-func validateLanguage(language string) (string, error) {
-	trimmed := strings.ToLower(strings.TrimSpace(language))
-	switch trimmed {
-	case languageEnglish, "en-us", "en-gb":
-		return languageEnglish, nil
-	case languageSpanish, "es-es", "es-419", "es-mx":
-		return languageSpanish, nil
-	case "":
-		return "", errors.New("language is required")
-	default:
-		return "", errors.New("unsupported language")
-	}
-}
-
 func (a *App) applySaveGamePath(saveGamePath string) error {
-	trimmed := strings.TrimSpace(saveGamePath)
-	if trimmed == "" {
-		return errors.New("savegame path is required")
-	}
-
-	if !filepath.IsAbs(trimmed) {
-		return errors.New("savegame path must be absolute")
-	}
-
-	info, err := os.Stat(trimmed)
+	paths, err := discovery.ValidateSaveGamePath(saveGamePath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return errors.New("savegame path does not exist")
-		}
-		return fmt.Errorf("read savegame path: %w", err)
-	}
-
-	if !info.IsDir() {
-		return errors.New("savegame path must be a directory")
-	}
-
-	if !strings.EqualFold(filepath.Base(trimmed), "SaveGame") {
-		return errors.New("path must point to the SaveGame folder")
-	}
-
-	parentDirName := filepath.Base(filepath.Dir(trimmed))
-	if !strings.EqualFold(parentDirName, "Need for speed heat") {
-		return errors.New("savegame path must be inside the Need for Speed Heat folder")
+		return err
 	}
 
-	profilesPath := filepath.Join(trimmed, "Profiles")
-	if err := os.MkdirAll(profilesPath, 0o755); err != nil {
+	if err := os.MkdirAll(paths.ProfilesPath, 0o755); err != nil {
 		return fmt.Errorf("ensure Profiles folder: %w", err)
 	}
 
-	a.saveGamePath = trimmed
-	a.profilesPath = profilesPath
+	a.saveGamePath = paths.SaveGamePath
+	a.profilesPath = paths.ProfilesPath
 
 	return nil
 }
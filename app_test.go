@@ -6,7 +6,9 @@ import (
 	"strings"
 	"testing"
 
+	"heat-save-manager/internal/audit"
 	"heat-save-manager/internal/config"
+	"heat-save-manager/internal/gameproc"
 )
 
 func TestApplySaveGamePathRequiresNeedForSpeedHeatParent(t *testing.T) {
@@ -91,6 +93,75 @@ func TestCreateMarkerFileAcceptsValidExistingProfile(t *testing.T) {
 	}
 }
 
+func TestMutatingCallsAreRecordedInAuditLog(t *testing.T) {
+	app := &App{audit: audit.New(t.TempDir())}
+	root := t.TempDir()
+	app.saveGamePath = filepath.Join(root, "SaveGame")
+	app.profilesPath = filepath.Join(app.saveGamePath, "Profiles")
+
+	if err := os.MkdirAll(filepath.Join(app.profilesPath, "ProfileAlpha"), 0o755); err != nil {
+		t.Fatalf("create profile directory: %v", err)
+	}
+
+	if err := app.CreateMarkerFile("ProfileAlpha"); err != nil {
+		t.Fatalf("create marker: %v", err)
+	}
+
+	if err := app.CreateMarkerFile(".."); err == nil {
+		t.Fatal("expected invalid profile name error")
+	}
+
+	page, err := app.GetAuditLog(audit.Filter{Operation: "create-marker"})
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+
+	if page.Total != 2 {
+		t.Fatalf("expected two audit entries, got %+v", page)
+	}
+
+	failed, succeeded := page.Entries[0], page.Entries[1]
+	if failed.Outcome != audit.OutcomeFailed || failed.Error == "" || failed.Target != ".." {
+		t.Fatalf("unexpected failed entry: %+v", failed)
+	}
+
+	if succeeded.Outcome != audit.OutcomeSucceeded || succeeded.Target != "ProfileAlpha" || len(succeeded.Paths) != 1 || succeeded.Paths[0] != app.saveGamePath {
+		t.Fatalf("unexpected succeeded entry: %+v", succeeded)
+	}
+}
+
+func TestSettingsKeyAndFolderChangesAreRecordedInAuditLog(t *testing.T) {
+	app := &App{audit: audit.New(t.TempDir())}
+	app.saveGamePath = filepath.Join(t.TempDir(), "SaveGame")
+
+	if err := app.SetCheckGameRunning(true); err != nil {
+		t.Fatalf("set check game running: %v", err)
+	}
+
+	if err := app.RemoveTrustedKey("missing"); err == nil {
+		t.Fatal("expected removing an unknown trusted key to fail")
+	}
+
+	if err := app.EnsureProfilesFolder(); err != nil {
+		t.Fatalf("ensure Profiles folder: %v", err)
+	}
+
+	for _, want := range []audit.Entry{
+		{Operation: "change-setting", Target: "checkGameRunning", Outcome: audit.OutcomeSucceeded},
+		{Operation: "untrust-key", Target: "missing", Outcome: audit.OutcomeFailed},
+		{Operation: "ensure-profiles-folder", Outcome: audit.OutcomeSucceeded},
+	} {
+		page, err := app.GetAuditLog(audit.Filter{Operation: want.Operation})
+		if err != nil {
+			t.Fatalf("read audit log: %v", err)
+		}
+
+		if page.Total != 1 || page.Entries[0].Target != want.Target || page.Entries[0].Outcome != want.Outcome {
+			t.Fatalf("expected one %s entry for %q with outcome %s, got %+v", want.Operation, want.Target, want.Outcome, page)
+		}
+	}
+}
+
 func TestGetLanguageDefaultsToEnglish(t *testing.T) {
 	app := &App{}
 
@@ -170,6 +241,23 @@ func TestApplySavedSettingsLoadsLanguageWithoutPath(t *testing.T) {
 	}
 }
 
+func TestNewGameGuardHonorsCheckGameRunningSetting(t *testing.T) {
+	store := config.NewStoreWithDir(filepath.Join(t.TempDir(), "config-root"))
+	app := &App{configStore: store, gameDetector: gameproc.NewProcDetector(t.TempDir())}
+
+	if guard := app.workspace().GameGuard(); guard == nil {
+		t.Fatal("expected guard when CheckGameRunning is enabled by default")
+	}
+
+	if err := app.SetCheckGameRunning(false); err != nil {
+		t.Fatalf("disable game check: %v", err)
+	}
+
+	if guard := app.workspace().GameGuard(); guard != nil {
+		t.Fatal("expected no guard when CheckGameRunning is disabled")
+	}
+}
+
 func TestSelectPreferredWindowsAssetPrefersInstallerVariants(t *testing.T) {
 	assets := []releaseAsset{
 		{Name: "HeatSaveManager-v1.0.9-windows-x64.zip", BrowserDownloadURL: "https://example.com/app.zip"},
@@ -0,0 +1,29 @@
+// Command heat-save-manager is the headless companion to the desktop app. It
+// runs the same profile operations against the same settings file and prints
+// JSON so it can be scripted.
+package main
+
+import (
+	"os"
+
+	"heat-save-manager/internal/cli"
+	"heat-save-manager/internal/config"
+	"heat-save-manager/internal/gameproc"
+)
+
+var version = "dev"
+
+func main() {
+	env := cli.Environment{
+		Stdout:       os.Stdout,
+		Stderr:       os.Stderr,
+		GameDetector: gameproc.NewDefaultDetector(),
+		Version:      version,
+	}
+
+	if store, err := config.NewStore(); err == nil {
+		env.ConfigStore = store
+	}
+
+	os.Exit(cli.Run(os.Args[1:], env))
+}
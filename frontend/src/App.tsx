@@ -3,6 +3,7 @@ import {AlertTriangle, CheckCircle2, ChevronDown, ChevronUp, CircleX, Download,
 import './App.css';
 import {EventsOn, Quit} from '../wailsjs/runtime/runtime';
 import {
+    CancelOperation,
     CheckForUpdates,
     CreateMarkerFile,
     DeleteActiveProfile,
@@ -13,16 +14,26 @@ import {
     ExportProfileBundle,
     GetActiveProfile,
     GetPaths,
+    GetRecoveryReport,
+    GetRootSyncStatus,
+    GetSaveBeforeSwitch,
+    IdentifyRootProfile,
     ImportProfileBundle,
+    InspectProfileBundle,
     ListProfiles,
     PickImportBundlePath,
     PickSaveGamePath,
     PrepareFreshProfile,
     PrepareFreshProfileWithoutSave,
+    QuarantineProfileEntry,
     OpenExternalURL,
     RenameProfile,
+    RepairProfile,
+    ScanProfiles,
+    UpdateProfileDetails,
     SaveCurrentProfile,
     SetLanguage,
+    SetActiveMarker,
     SetSaveGamePath,
     StartInAppUpdate,
     SwitchProfile,
@@ -33,6 +44,53 @@ import ThemedSelect, {type ThemedSelectOption} from './components/ThemedSelect';
 
 type Profile = {
     name: string;
+    description: string;
+    tags: string[];
+    color: string;
+    createdAt?: string;
+    lastSwitchedAt?: string;
+    lastSavedAt?: string;
+};
+
+type ProfileCandidate = {
+    name: string;
+    path: string;
+    status: string;
+    reason?: string;
+};
+
+type RootSyncStatus = {
+    state: string;
+    activeProfile?: string;
+    matchedProfile?: string;
+    changes?: string[];
+    changeCount: number;
+};
+
+type RootIdentification = {
+    activeProfile?: string;
+    markerMissing: boolean;
+    activeExists: boolean;
+    matches: {profile: string; score: number; exact: boolean}[];
+    suggested?: string;
+};
+
+type ImportConflictPolicy = 'overwrite' | 'merge-wraps' | 'rename';
+
+type BundleInspection = {
+    bundlePath: string;
+    encrypted: boolean;
+    passphraseRequired: boolean;
+    manifest?: {profileName: string; author?: string; description?: string; exportedAt: string};
+    signature: {status: string; author: string; trustedName: string; fingerprint: string};
+    fileCount: number;
+    directoryCount: number;
+    totalBytes: number;
+    layoutValid: boolean;
+    layout: {wrapperFolder?: string; renamedFolders?: {from: string; to: string}[]};
+    violations: {limit: string; maximum: number; actual: number; path?: string}[];
+    problems: string[];
+    canImport: boolean;
 };
 
 type HealthItem = {
@@ -76,6 +134,27 @@ type UpdateProgressEvent = {
     percent?: number;
 };
 
+type OperationInfo = {
+    id: number;
+    kind: string;
+    target?: string;
+    cancelling?: boolean;
+};
+
+type OperationProgressEvent = {
+    kind: string;
+    target?: string;
+    filesDone: number;
+    filesTotal: number;
+    bytesDone: number;
+    bytesTotal: number;
+};
+
+type OperationStatusEvent = {
+    current?: OperationInfo | null;
+    queued?: OperationInfo[];
+};
+
 type ErrorFeedback = {
     message: string;
     hint: string;
@@ -97,6 +176,55 @@ function toErrorFeedback(error: unknown, fallback: string, t: Translator): Error
     const detail = normalizeError(error);
     const lowered = detail.toLowerCase();
 
+    if (lowered.includes('operation is already in progress') || lowered.includes('too many operations are waiting')) {
+        return {
+            message: t('feedback.operationPending.message'),
+            hint: t('feedback.operationPending.hint'),
+        };
+    }
+
+    if (lowered.includes('root save could not be reloaded')) {
+        return {
+            message: t('feedback.activeNotReloaded.message'),
+            hint: t('feedback.activeNotReloaded.hint'),
+        };
+    }
+
+    if (lowered.includes('a profile with this name already exists')) {
+        return {
+            message: t('feedback.profileExists.message'),
+            hint: t('feedback.profileExists.hint'),
+        };
+    }
+
+    if (lowered.includes('context canceled')) {
+        return {
+            message: t('feedback.cancelled.message'),
+            hint: t('feedback.cancelled.hint'),
+        };
+    }
+
+    if (lowered.includes('interrupted operation; run recovery first')) {
+        return {
+            message: t('feedback.entryInUse.message'),
+            hint: t('feedback.entryInUse.hint'),
+        };
+    }
+
+    if (lowered.includes('exported bundle failed verification')) {
+        return {
+            message: t('feedback.exportVerification.message'),
+            hint: t('feedback.exportVerification.hint'),
+        };
+    }
+
+    if (lowered.includes('savegame folder is busy')) {
+        return {
+            message: t('feedback.busy.message'),
+            hint: t('feedback.busy.hint'),
+        };
+    }
+
     if (lowered.includes('cannot delete active profile')) {
         return {
             message: t('feedback.cannotDelete.message'),
@@ -322,6 +450,8 @@ function formatRate(bytesPerSecond: number): string {
 }
 
 const updateProgressEventName = 'updater:progress';
+const operationsEventName = 'operations:changed';
+const operationProgressEventName = 'operations:progress';
 const slowNetworkThresholdBps = 256 * 1024;
 const slowNetworkDelayMs = 5000;
 const toastVisibilityMs = 6400;
@@ -343,12 +473,24 @@ function App() {
     const [importTargetProfile, setImportTargetProfile] = useState('');
     const [importTargetNewName, setImportTargetNewName] = useState('');
     const [importBundlePath, setImportBundlePath] = useState('');
+    const [importPreview, setImportPreview] = useState<BundleInspection | null>(null);
+    const [importConflict, setImportConflict] = useState<ImportConflictPolicy>('overwrite');
     const [status, setStatus] = useState(() => createTranslator('en')('status.loadingProfiles'));
     const [recoveryHint, setRecoveryHint] = useState('');
+    const [runningOperation, setRunningOperation] = useState<OperationInfo | null>(null);
+    const [operationProgress, setOperationProgress] = useState<OperationProgressEvent | null>(null);
     const [healthReport, setHealthReport] = useState<HealthReport | null>(null);
     const [isLoading, setIsLoading] = useState(true);
     const [renameTarget, setRenameTarget] = useState<string | null>(null);
     const [renameValue, setRenameValue] = useState('');
+    const [rootSync, setRootSync] = useState<RootSyncStatus | null>(null);
+    const [saveBeforeSwitch, setSaveBeforeSwitch] = useState(true);
+    const [rootIdentity, setRootIdentity] = useState<RootIdentification | null>(null);
+    const [profileIssues, setProfileIssues] = useState<ProfileCandidate[]>([]);
+    const [detailsTarget, setDetailsTarget] = useState<string | null>(null);
+    const [detailsDescription, setDetailsDescription] = useState('');
+    const [detailsTags, setDetailsTags] = useState('');
+    const [detailsColor, setDetailsColor] = useState('');
     const [deleteTarget, setDeleteTarget] = useState<string | null>(null);
     const [deleteReplacementTarget, setDeleteReplacementTarget] = useState('');
     const [markerDialogProfile, setMarkerDialogProfile] = useState('');
@@ -388,14 +530,17 @@ function App() {
 
     const t = useMemo(() => createTranslator(language), [language]);
 
-    const isModalOpen = isSavePathSetupOpen || switchConfirmProfile !== null || renameTarget !== null || deleteTarget !== null || diagnosticsModal !== null || isExportModalOpen || isImportModalOpen || isFreshConfirmOpen || isFreshNameModalOpen;
+    const isModalOpen = isSavePathSetupOpen || switchConfirmProfile !== null || renameTarget !== null || detailsTarget !== null || deleteTarget !== null || diagnosticsModal !== null || isExportModalOpen || isImportModalOpen || isFreshConfirmOpen || isFreshNameModalOpen;
 
     const canApplyPath = saveGamePathInput.trim() !== '';
     const canExportBundle = exportProfileName.trim() !== '';
     const resolvedImportTarget = importTargetProfile === NEW_PROFILE_OPTION ? importTargetNewName.trim() : importTargetProfile.trim();
-    const canImportBundle = resolvedImportTarget !== '' && importBundlePath.trim() !== '';
+    const canImportBundle = resolvedImportTarget !== '' && importBundlePath.trim() !== '' && importPreview?.canImport === true;
     const activeProfileKey = activeProfile.trim().toLowerCase();
+    const importTargetExists = resolvedImportTarget !== '' && profiles.some((profile) => profile.name.trim().toLowerCase() === resolvedImportTarget.toLowerCase());
+    const importTargetIsActive = importTargetExists && resolvedImportTarget.toLowerCase() === activeProfileKey;
     const hasSelectedProfile = selectedProfileName.trim() !== '';
+    const selectedProfile = profiles.find((profile) => profile.name === selectedProfileName) ?? null;
     const profileSelectOptions = useMemo<ThemedSelectOption[]>(() => profiles.map((profile) => ({
         value: profile.name,
         label: profile.name,
@@ -414,6 +559,10 @@ function App() {
         ...profileSelectOptions,
         {value: NEW_PROFILE_OPTION, label: t('saveActions.createNewProfileOption')},
     ], [profileSelectOptions, t]);
+    const importConflictOptions = useMemo<ThemedSelectOption[]>(() => (['overwrite', 'merge-wraps', 'rename'] as ImportConflictPolicy[]).map((policy) => ({
+        value: policy,
+        label: t(`modal.import.conflict.${policy}`),
+    })), [t]);
     const deletableProfiles = profiles.filter((profile) => profile.name.trim().toLowerCase() !== activeProfileKey);
     const deleteRequiresReplacement = deleteTarget !== null && activeProfileKey !== '' && deleteTarget.trim().toLowerCase() === activeProfileKey;
     const deleteReplacementOptions = deleteRequiresReplacement
@@ -600,7 +749,25 @@ function App() {
             setSaveGamePath(paths.saveGamePath);
             setSaveGamePathInput(paths.saveGamePath);
             setProfiles(profileItems);
+            try {
+                const candidates = await ScanProfiles();
+                setProfileIssues(candidates.filter((candidate) => candidate.status !== 'valid'));
+            } catch {
+                setProfileIssues([]);
+            }
+            let suggestedActive = '';
+            try {
+                const identity = await IdentifyRootProfile();
+                setRootIdentity(identity);
+                suggestedActive = identity.suggested ?? '';
+            } catch {
+                setRootIdentity(null);
+            }
             setMarkerDialogProfile((current) => {
+                if (suggestedActive && profileItems.some((profile) => profile.name === suggestedActive)) {
+                    return suggestedActive;
+                }
+
                 if (current && profileItems.some((profile) => profile.name === current)) {
                     return current;
                 }
@@ -700,6 +867,25 @@ function App() {
         }
     }
 
+    async function showRecoveryReport() {
+        try {
+            const actions = await GetRecoveryReport();
+            if (actions.length === 0) {
+                return;
+            }
+
+            const failed = actions.filter((action) => action.outcome === 'failed').length;
+            if (failed > 0) {
+                showToast(t('recovery.failed', {count: failed}), 'error');
+                return;
+            }
+
+            showToast(t('recovery.done', {count: actions.length}), 'info');
+        } catch {
+            // Recovery already ran on startup; a missing report is not fatal.
+        }
+    }
+
     async function checkForUpdates() {
         let resolvedVersion = '';
 
@@ -1042,10 +1228,13 @@ function App() {
             setStatus(t('status.switchingProfile', {name: profileName}));
             setIsLoading(true);
             setRecoveryHint('');
-            await SwitchProfile(profileName);
+            const result = await SwitchProfile(profileName);
             setActiveProfile(profileName);
             setSelectedProfileName(profileName);
             setStatus(t('status.activeProfileNow', {name: profileName}));
+            if (result.BackupWarning) {
+                setRecoveryHint(t('feedback.backupNotKept.hint', {detail: result.BackupWarning}));
+            }
         } catch (error) {
             const feedback = toErrorFeedback(error, t('error.switch'), t);
             setStatus(feedback.message);
@@ -1076,6 +1265,7 @@ function App() {
         setFreshPrepareMode(null);
         setFreshProfileName('');
         setIsFreshConfirmOpen(true);
+        void refreshRootSync();
     }
 
     function closeFreshFlow() {
@@ -1210,8 +1400,12 @@ function App() {
             setIsLoading(true);
             setStatus(t('status.exportingBundle', {name: profileName}));
             setRecoveryHint('');
-            await ExportProfileBundle(profileName, bundlePath);
-            setStatus(t('status.bundleExported', {path: bundlePath}));
+            const result = await ExportProfileBundle(profileName, bundlePath);
+            setStatus(t('status.bundleExported', {
+                path: result.bundlePath,
+                files: result.files,
+                size: formatBytes(result.size),
+            }));
             setIsExportModalOpen(false);
         } catch (error) {
             const feedback = toErrorFeedback(error, t('error.exportBundle'), t);
@@ -1239,9 +1433,16 @@ function App() {
             setIsLoading(true);
             setStatus(t('status.importingBundle', {name: profileName}));
             setRecoveryHint('');
-            await ImportProfileBundle(profileName, bundlePath);
+            const result = await ImportProfileBundle(profileName, bundlePath, importTargetExists ? importConflict : 'fail');
             await loadData();
-            setStatus(t('status.bundleImported', {name: profileName}));
+            const importedName = result.profileName || profileName;
+            if (result.signature.status === 'trusted') {
+                setStatus(t('status.bundleImportedTrusted', {name: importedName, author: result.signature.trustedName}));
+            } else if (result.signature.status === 'untrusted') {
+                setStatus(t('status.bundleImportedUntrusted', {name: importedName, author: result.signature.author || result.signature.fingerprint}));
+            } else {
+                setStatus(t('status.bundleImportedUnsigned', {name: importedName}));
+            }
             setIsImportModalOpen(false);
         } catch (error) {
             const feedback = toErrorFeedback(error, t('error.importBundle'), t);
@@ -1252,11 +1453,23 @@ function App() {
         }
     }
 
+    async function inspectImportBundle(bundlePath: string) {
+        try {
+            setImportPreview(await InspectProfileBundle(bundlePath));
+        } catch (error) {
+            const feedback = toErrorFeedback(error, t('error.inspectBundle'), t);
+            setStatus(feedback.message);
+            setRecoveryHint(feedback.hint);
+        }
+    }
+
     async function onPickImportBundlePath() {
         try {
             const selected = await PickImportBundlePath();
             if (selected) {
                 setImportBundlePath(selected);
+                setImportPreview(null);
+                await inspectImportBundle(selected);
             }
         } catch (error) {
             const feedback = toErrorFeedback(error, t('error.openFilePicker'), t);
@@ -1303,6 +1516,110 @@ function App() {
         }
     }
 
+    async function refreshRootSync() {
+        setRootSync(null);
+        try {
+            const [syncStatus, saveFirst] = await Promise.all([GetRootSyncStatus(), GetSaveBeforeSwitch()]);
+            setRootSync(syncStatus);
+            setSaveBeforeSwitch(saveFirst);
+        } catch {
+            setRootSync(null);
+        }
+    }
+
+    async function onApplySuggestedMarker(profileName: string) {
+        try {
+            setIsLoading(true);
+            setRecoveryHint('');
+            await SetActiveMarker(profileName);
+            setActiveProfile(profileName);
+            await loadData();
+            setStatus(t('status.markerRewritten', {name: profileName}));
+        } catch (error) {
+            const feedback = toErrorFeedback(error, t('error.createMarker'), t);
+            setStatus(feedback.message);
+            setRecoveryHint(feedback.hint);
+        } finally {
+            setIsLoading(false);
+        }
+    }
+
+    async function onRepairProfile(entryName: string) {
+        try {
+            setIsLoading(true);
+            setRecoveryHint('');
+            await RepairProfile(entryName);
+            await loadData();
+            setStatus(t('status.profileRepaired', {name: entryName}));
+        } catch (error) {
+            const feedback = toErrorFeedback(error, t('error.repairProfile'), t);
+            setStatus(feedback.message);
+            setRecoveryHint(feedback.hint);
+        } finally {
+            setIsLoading(false);
+        }
+    }
+
+    async function onQuarantineEntry(entryName: string) {
+        try {
+            setIsLoading(true);
+            setRecoveryHint('');
+            const destination = await QuarantineProfileEntry(entryName);
+            await loadData();
+            setStatus(t('status.entryQuarantined', {name: entryName, path: destination}));
+        } catch (error) {
+            const feedback = toErrorFeedback(error, t('error.quarantineEntry'), t);
+            setStatus(feedback.message);
+            setRecoveryHint(feedback.hint);
+        } finally {
+            setIsLoading(false);
+        }
+    }
+
+    function openDetailsModal(profileName: string) {
+        const profile = profiles.find((item) => item.name === profileName);
+        setDetailsTarget(profileName);
+        setDetailsDescription(profile?.description ?? '');
+        setDetailsTags((profile?.tags ?? []).join(', '));
+        setDetailsColor(profile?.color ?? '');
+    }
+
+    function closeDetailsModal() {
+        setDetailsTarget(null);
+        setDetailsDescription('');
+        setDetailsTags('');
+        setDetailsColor('');
+    }
+
+    async function confirmProfileDetails() {
+        if (!detailsTarget) {
+            return;
+        }
+
+        try {
+            setIsLoading(true);
+            setRecoveryHint('');
+            await UpdateProfileDetails(detailsTarget, {
+                description: detailsDescription,
+                tags: detailsTags.split(','),
+                color: detailsColor,
+            });
+            await loadData();
+            setStatus(t('status.profileDetailsSaved', {name: detailsTarget}));
+            closeDetailsModal();
+        } catch (error) {
+            const feedback = toErrorFeedback(error, t('error.profileDetails'), t);
+            setStatus(feedback.message);
+            setRecoveryHint(feedback.hint);
+        } finally {
+            setIsLoading(false);
+        }
+    }
+
+    function formatProfileTime(value?: string) {
+        return value ? new Date(value).toLocaleString() : t('profiles.never');
+    }
+
     function getDeleteReplacementFallback(targetName: string) {
         return profiles.find((profile) => profile.name.trim().toLowerCase() !== targetName.trim().toLowerCase())?.name ?? '';
     }
@@ -1326,6 +1643,7 @@ function App() {
             : fallback;
 
         setDeleteTargetWithDefaults(nextTarget);
+        void refreshRootSync();
     }
 
     function closeDeleteModal() {
@@ -1376,6 +1694,7 @@ function App() {
             return profiles[0]?.name ?? NEW_PROFILE_OPTION;
         });
         setImportTargetNewName('');
+        setImportConflict('overwrite');
         setIsImportModalOpen(true);
     }
 
@@ -1433,6 +1752,11 @@ function App() {
             return;
         }
 
+        if (detailsTarget) {
+            closeDetailsModal();
+            return;
+        }
+
         if (deleteTarget) {
             closeDeleteModal();
             return;
@@ -1502,6 +1826,18 @@ function App() {
         }
     }
 
+    async function onCancelOperation() {
+        if (!runningOperation) {
+            return;
+        }
+
+        try {
+            await CancelOperation(runningOperation.id);
+        } catch (error) {
+            showToast(normalizeError(error), 'error');
+        }
+    }
+
     useEffect(() => {
         void loadLanguagePreference();
     }, []);
@@ -1512,9 +1848,41 @@ function App() {
         }
 
         void loadData();
+        void showRecoveryReport();
         void checkForUpdates();
     }, [isLanguageReady]);
 
+    useEffect(() => {
+        const unsubscribe = EventsOn(operationsEventName, (payload: OperationStatusEvent) => {
+            const queued = payload?.queued ?? [];
+            const current = payload?.current;
+            setRunningOperation(current ?? null);
+            if (!current) {
+                setOperationProgress(null);
+            }
+
+            if (!current || queued.length === 0) {
+                return;
+            }
+
+            showToast(t('operations.waiting', {operation: current.kind, count: queued.length}), 'info');
+        });
+
+        return () => {
+            unsubscribe();
+        };
+    }, [t]);
+
+    useEffect(() => {
+        const unsubscribe = EventsOn(operationProgressEventName, (payload: OperationProgressEvent) => {
+            setOperationProgress(payload ?? null);
+        });
+
+        return () => {
+            unsubscribe();
+        };
+    }, []);
+
     useEffect(() => {
         const unsubscribe = EventsOn(updateProgressEventName, (payload: UpdateProgressEvent) => {
             const stage = (payload?.stage || '').trim().toLowerCase();
@@ -1647,7 +2015,7 @@ function App() {
 
         window.addEventListener('keydown', handleKeyDown);
         return () => window.removeEventListener('keydown', handleKeyDown);
-    }, [isModalOpen, isLoading, switchConfirmProfile, renameTarget, deleteTarget, diagnosticsModal, isExportModalOpen, isImportModalOpen, isFreshConfirmOpen, isFreshNameModalOpen]);
+    }, [isModalOpen, isLoading, switchConfirmProfile, renameTarget, detailsTarget, deleteTarget, diagnosticsModal, isExportModalOpen, isImportModalOpen, isFreshConfirmOpen, isFreshNameModalOpen]);
 
     useEffect(() => {
         if (importTargetProfile === NEW_PROFILE_OPTION) {
@@ -1715,6 +2083,14 @@ function App() {
         };
     }, [toastMessage, toastSequence]);
 
+    const operationPercent = operationProgress
+        ? operationProgress.bytesTotal > 0
+            ? Math.min(100, Math.round((operationProgress.bytesDone / operationProgress.bytesTotal) * 100))
+            : operationProgress.filesTotal > 0
+                ? Math.min(100, Math.round((operationProgress.filesDone / operationProgress.filesTotal) * 100))
+                : 0
+        : 0;
+
     return (
         <div className={`app-shell${hasQuickActions ? ' quick-actions-focus' : ''}`}>
             <header className="hero">
@@ -1727,6 +2103,31 @@ function App() {
                     </button>
                 </div>
                 <p className={`status ${diagnosticsStatusClass}`}><span className="status-dot" aria-hidden="true" /> {diagnosticsStatusLabel}</p>
+                {runningOperation && operationProgress && (
+                    <div className="update-progress-wrap operation-progress" role="status" aria-live="polite">
+                        <div className="update-progress-row">
+                            <span className="update-progress-label">
+                                {runningOperation.cancelling
+                                    ? t('operations.cancelling', {operation: runningOperation.kind})
+                                    : t('operations.progress', {operation: runningOperation.kind, done: operationProgress.filesDone, total: operationProgress.filesTotal})}
+                            </span>
+                            <span className="update-progress-meta">{operationPercent}%</span>
+                            <button className="switch-btn secondary" onClick={() => void onCancelOperation()} disabled={Boolean(runningOperation.cancelling)}>
+                                {t('operations.cancel')}
+                            </button>
+                        </div>
+                        <div
+                            className="update-progress-track"
+                            role="progressbar"
+                            aria-label={t('operations.progressAriaLabel')}
+                            aria-valuemin={0}
+                            aria-valuemax={100}
+                            aria-valuenow={operationPercent}
+                        >
+                            <span className="update-progress-fill" style={{width: `${operationPercent}%`}} />
+                        </div>
+                    </div>
+                )}
                 {updateInfo?.updateAvailable && !isUpdateDismissed && (
                     <div className="update-banner" role="status" aria-live="polite">
                         <div className="update-banner-copy">
@@ -1880,6 +2281,7 @@ function App() {
 
                                             if (next.trim().toLowerCase() !== activeProfile.trim().toLowerCase()) {
                                                 setSwitchConfirmProfile(next);
+                                                void refreshRootSync();
                                             }
                                         }}
                                         disabled={isLoading || isModalOpen}
@@ -1895,6 +2297,15 @@ function App() {
                                     <Edit3 size={13} strokeWidth={2.1} />
                                     {t('common.rename')}
                                 </button>
+                                <button
+                                    className="switch-btn secondary"
+                                    onClick={() => openDetailsModal(selectedProfileName)}
+                                    disabled={isLoading || isModalOpen || !hasSelectedProfile}
+                                    aria-label={t('profiles.detailsSelectedAria')}
+                                >
+                                    <FileText size={13} strokeWidth={2.1} />
+                                    {t('profiles.details')}
+                                </button>
                                 <button
                                     className="switch-btn danger"
                                     onClick={() => openDeleteModal(selectedProfileName)}
@@ -1911,6 +2322,72 @@ function App() {
                                 <p className="field-hint">{t('profiles.emptyHint')}</p>
                             </div>
                         )}
+
+                        {rootIdentity?.suggested && (() => {
+                            const suggested = rootIdentity.suggested;
+                            const match = rootIdentity.matches.find((item) => item.profile === suggested);
+                            return (
+                                <div className="profile-issues marker-suggestion" role="status">
+                                    <p className="field-label"><Info size={13} strokeWidth={2.1} /> {t('profiles.markerSuggestion.title')}</p>
+                                    <p className="field-hint">
+                                        {match?.exact
+                                            ? t('profiles.markerSuggestion.exact', {name: suggested})
+                                            : t('profiles.markerSuggestion.closest', {name: suggested, percent: Math.round((match?.score ?? 0) * 100)})}
+                                        {' '}
+                                        {rootIdentity.markerMissing
+                                            ? t('profiles.markerSuggestion.markerMissing')
+                                            : t('profiles.markerSuggestion.markerNames', {name: rootIdentity.activeProfile || ''})}
+                                    </p>
+                                    <div className="profile-issue-actions">
+                                        <button className="switch-btn secondary" onClick={() => void onApplySuggestedMarker(suggested)} disabled={isLoading || isModalOpen}>
+                                            {t('profiles.markerSuggestion.apply', {name: suggested})}
+                                        </button>
+                                    </div>
+                                </div>
+                            );
+                        })()}
+
+                        {profileIssues.length > 0 && (
+                            <div className="profile-issues" role="status">
+                                <p className="field-label"><AlertTriangle size={13} strokeWidth={2.1} /> {t('profiles.issuesTitle', {count: profileIssues.length})}</p>
+                                <ul>
+                                    {profileIssues.map((issue) => (
+                                        <li key={issue.name} className="profile-issue">
+                                            <div className="profile-issue-copy">
+                                                <strong>{issue.name}</strong>
+                                                <span className="field-hint">{t(`profiles.issue.${issue.status}`)}</span>
+                                            </div>
+                                            <div className="profile-issue-actions">
+                                                {issue.status === 'missing-wraps' && (
+                                                    <button className="switch-btn secondary" onClick={() => void onRepairProfile(issue.name)} disabled={isLoading || isModalOpen}>
+                                                        {t('profiles.repair')}
+                                                    </button>
+                                                )}
+                                                <button className="switch-btn secondary" onClick={() => void onQuarantineEntry(issue.name)} disabled={isLoading || isModalOpen}>
+                                                    {t('profiles.quarantine')}
+                                                </button>
+                                            </div>
+                                        </li>
+                                    ))}
+                                </ul>
+                            </div>
+                        )}
+
+                        {selectedProfile && (
+                            <div className="profile-details" style={selectedProfile.color ? {borderLeftColor: selectedProfile.color} : undefined}>
+                                {selectedProfile.description && <p className="profile-details-description">{selectedProfile.description}</p>}
+                                {selectedProfile.tags.length > 0 && (
+                                    <div className="profile-details-tags">
+                                        {selectedProfile.tags.map((tag) => <span key={tag} className="profile-tag">{tag}</span>)}
+                                    </div>
+                                )}
+                                <p className="field-hint">
+                                    {t('profiles.lastSwitched', {value: formatProfileTime(selectedProfile.lastSwitchedAt)})}
+                                    {' | '}
+                                    {t('profiles.lastSaved', {value: formatProfileTime(selectedProfile.lastSavedAt)})}
+                                </p>
+                            </div>
+                        )}
                     </div>
 
                 </section>
@@ -2154,6 +2631,11 @@ function App() {
                                 {t('modal.freshConfirm.saveUnavailable')}
                             </p>
                         )}
+                        {rootSync?.state === 'unsaved-changes' && (
+                            <p className="modal-note modal-warning">
+                                {t('modal.freshConfirm.unsavedWarning', {active: rootSync.activeProfile || '', count: rootSync.changeCount})}
+                            </p>
+                        )}
                         <p className="modal-note">
                             {t('modal.freshConfirm.note')}
                         </p>
@@ -2217,6 +2699,19 @@ function App() {
                             {t('modal.switch.description', {current: activeProfile || t('common.noneSelected'), next: switchConfirmProfile})}
                         </p>
                         <p className="modal-note">{t('modal.switch.note')}</p>
+                        {rootSync?.state === 'unsaved-changes' && !saveBeforeSwitch && (
+                            <p className="modal-note modal-warning">
+                                {t('modal.switch.unsavedWarning', {active: rootSync.activeProfile || '', count: rootSync.changeCount})}
+                            </p>
+                        )}
+                        {rootSync?.state === 'matches-other-profile' && (
+                            <p className="modal-note modal-warning">
+                                {t(saveBeforeSwitch ? 'modal.switch.mismatchSaveWarning' : 'modal.switch.mismatchWarning', {
+                                    active: rootSync.activeProfile || t('common.noneSelected'),
+                                    matched: rootSync.matchedProfile || '',
+                                })}
+                            </p>
+                        )}
                         <div className="modal-actions">
                             <button className="switch-btn secondary" onClick={closeSwitchConfirmModal} disabled={isLoading}>
                                 {t('common.cancel')}
@@ -2392,6 +2887,24 @@ function App() {
                                     />
                                 </div>
                             )}
+                            {importTargetExists && (
+                                <>
+                                    <label className="field-label" htmlFor="import-conflict-modal-input">{t('modal.import.conflict.label', {name: resolvedImportTarget})}</label>
+                                    <div className="import-modal-destination">
+                                        <ThemedSelect
+                                            id="import-conflict-modal-input"
+                                            value={importConflict}
+                                            options={importConflictOptions}
+                                            onChange={(next) => setImportConflict(next as ImportConflictPolicy)}
+                                            disabled={isLoading}
+                                        />
+                                    </div>
+                                    <p className="modal-note">{t(`modal.import.conflict.${importConflict}.hint`, {name: resolvedImportTarget})}</p>
+                                    {importTargetIsActive && importConflict !== 'rename' && (
+                                        <p className="modal-note modal-warning">{t('modal.import.conflict.activeWarning', {name: resolvedImportTarget})}</p>
+                                    )}
+                                </>
+                            )}
                         </div>
                         <div className="import-modal-section import-modal-bundle-section">
                             <p className="field-label import-bundle-label">{t('modal.import.bundleLabel')}</p>
@@ -2403,6 +2916,63 @@ function App() {
                                     {t('common.browse')}
                                 </button>
                             </div>
+                            {importPreview && (
+                                <div className="import-preview">
+                                    {importPreview.passphraseRequired ? (
+                                        <p className="modal-note modal-warning">{t('modal.import.preview.passphraseRequired')}</p>
+                                    ) : (
+                                        <>
+                                            {importPreview.manifest ? (
+                                                <p className="modal-note">
+                                                    {t('modal.import.preview.source', {
+                                                        name: importPreview.manifest.profileName,
+                                                        author: importPreview.manifest.author || t('modal.import.preview.unknownAuthor'),
+                                                        date: formatProfileTime(importPreview.manifest.exportedAt),
+                                                    })}
+                                                </p>
+                                            ) : (
+                                                <p className="modal-note">{t('modal.import.preview.noManifest')}</p>
+                                            )}
+                                            {importPreview.manifest?.description && <p className="modal-note">{importPreview.manifest.description}</p>}
+                                            <p className="modal-note">
+                                                {t('modal.import.preview.contents', {
+                                                    files: importPreview.fileCount,
+                                                    folders: importPreview.directoryCount,
+                                                    size: formatBytes(importPreview.totalBytes),
+                                                })}
+                                            </p>
+                                            <p className={`modal-note${importPreview.signature.status === 'trusted' ? '' : ' modal-warning'}`}>
+                                                {t(`modal.import.preview.signature.${importPreview.signature.status || 'unsigned'}`, {
+                                                    author: importPreview.signature.trustedName || importPreview.signature.author || importPreview.signature.fingerprint,
+                                                })}
+                                            </p>
+                                            {!importPreview.layoutValid && <p className="modal-note modal-warning">{t('modal.import.preview.invalidLayout')}</p>}
+                                            {importPreview.layoutValid && importPreview.layout.wrapperFolder && (
+                                                <p className="modal-note">{t('modal.import.preview.wrapperFolder', {folder: importPreview.layout.wrapperFolder})}</p>
+                                            )}
+                                            {importPreview.layoutValid && (importPreview.layout.renamedFolders?.length ?? 0) > 0 && (
+                                                <p className="modal-note">
+                                                    {t('modal.import.preview.renamedFolders', {
+                                                        folders: (importPreview.layout.renamedFolders ?? []).map((rename) => `${rename.from} → ${rename.to}`).join(', '),
+                                                    })}
+                                                </p>
+                                            )}
+                                            {importPreview.violations.map((violation) => (
+                                                <p className="modal-note modal-warning" key={violation.limit}>
+                                                    {t(`modal.import.preview.limit.${violation.limit}`, {
+                                                        actual: violation.limit === 'entries' ? violation.actual : formatBytes(violation.actual),
+                                                        maximum: violation.limit === 'entries' ? violation.maximum : formatBytes(violation.maximum),
+                                                        path: violation.path ?? '',
+                                                    })}
+                                                </p>
+                                            ))}
+                                            {importPreview.problems.map((problem) => (
+                                                <p className="modal-note modal-warning" key={problem}>{problem}</p>
+                                            ))}
+                                        </>
+                                    )}
+                                </div>
+                            )}
                         </div>
                         <div className="modal-actions">
                             <button className="switch-btn secondary" onClick={closeImportModal} disabled={isLoading}>
@@ -2445,6 +3015,54 @@ function App() {
                 </div>
             )}
 
+            {detailsTarget && (
+                <div className="modal-overlay" role="dialog" aria-modal="true" aria-labelledby="details-modal-title" aria-describedby="details-modal-description">
+                    <div className="modal-card" onClick={(event) => event.stopPropagation()}>
+                        <h3 id="details-modal-title">{t('modal.details.title')}</h3>
+                        <p id="details-modal-description">{t('modal.details.description', {name: detailsTarget})}</p>
+                        <label className="field-label" htmlFor="details-description">{t('modal.details.notes')}</label>
+                        <textarea
+                            id="details-description"
+                            value={detailsDescription}
+                            onChange={(event) => setDetailsDescription(event.target.value)}
+                            disabled={isLoading}
+                            maxLength={500}
+                            rows={3}
+                            autoFocus
+                        />
+                        <label className="field-label" htmlFor="details-tags">{t('modal.details.tags')}</label>
+                        <input
+                            id="details-tags"
+                            value={detailsTags}
+                            onChange={(event) => setDetailsTags(event.target.value)}
+                            placeholder={t('modal.details.tagsPlaceholder')}
+                            disabled={isLoading}
+                        />
+                        <label className="field-label" htmlFor="details-color">{t('modal.details.color')}</label>
+                        <div className="profile-color-row">
+                            <input
+                                id="details-color"
+                                type="color"
+                                value={detailsColor || '#ff8a33'}
+                                onChange={(event) => setDetailsColor(event.target.value)}
+                                disabled={isLoading}
+                            />
+                            <button className="switch-btn secondary" onClick={() => setDetailsColor('')} disabled={isLoading || !detailsColor}>
+                                {t('modal.details.clearColor')}
+                            </button>
+                        </div>
+                        <div className="modal-actions">
+                            <button className="switch-btn secondary" onClick={closeDetailsModal} disabled={isLoading}>
+                                {t('common.cancel')}
+                            </button>
+                            <button className="action-btn" onClick={() => void confirmProfileDetails()} disabled={isLoading}>
+                                {t('common.save')}
+                            </button>
+                        </div>
+                    </div>
+                </div>
+            )}
+
             {deleteTarget && (
                 <div className="modal-overlay" role="dialog" aria-modal="true" aria-labelledby="delete-modal-title" aria-describedby="delete-modal-description">
                     <div className="modal-card danger delete-modal-card" onClick={(event) => event.stopPropagation()}>
@@ -2461,6 +3079,11 @@ function App() {
                         {deleteRequiresReplacement ? (
                             <>
                                 <p className="modal-note">{t('modal.delete.activeDescription', {name: deleteTarget})}</p>
+                                {rootSync?.state === 'unsaved-changes' && (
+                                    <p className="modal-note modal-warning">
+                                        {t('modal.delete.unsavedWarning', {name: deleteTarget, count: rootSync.changeCount})}
+                                    </p>
+                                )}
                                 {deleteReplacementOptions.length > 0 ? (
                                     <>
                                         <label className="field-label" htmlFor="delete-replacement-modal-select">{t('modal.delete.replacementLabel')}</label>
@@ -21,6 +21,7 @@ const translations: Record<Locale, Record<string, string>> = {
         'common.closeForNow': 'Close for now',
         'common.browse': 'Browse...',
         'common.rename': 'Rename',
+        'common.save': 'Save',
         'common.delete': 'Delete',
         'common.show': 'Show',
         'common.hide': 'Hide',
@@ -106,6 +107,24 @@ const translations: Record<Locale, Record<string, string>> = {
         'profiles.empty': 'No profiles found in the Profiles folder.',
         'profiles.emptyHint': 'Create one with Start New Save to begin.',
         'profiles.selectAria': 'Select profile',
+        'profiles.details': 'Details',
+        'profiles.detailsSelectedAria': 'Edit notes and tags of selected profile',
+        'profiles.lastSwitched': 'Last played: {value}',
+        'profiles.lastSaved': 'Last saved: {value}',
+        'profiles.never': 'never',
+        'profiles.issuesTitle': '{count} item(s) in the Profiles folder are not usable profiles',
+        'profiles.issue.missing-savegame': 'Missing its savegame folder, so it cannot be switched to.',
+        'profiles.issue.missing-wraps': 'Missing its wraps folder. Repair adds an empty one.',
+        'profiles.issue.not-directory': 'A file, not a profile folder.',
+        'profiles.issue.artifact': 'Leftover from an interrupted switch, save or import.',
+        'profiles.repair': 'Repair',
+        'profiles.markerSuggestion.title': 'The active marker may be wrong',
+        'profiles.markerSuggestion.exact': 'The root save is identical to {name}.',
+        'profiles.markerSuggestion.closest': 'The root save is closest to {name} ({percent}% of files match).',
+        'profiles.markerSuggestion.markerMissing': 'active_profile.txt is missing.',
+        'profiles.markerSuggestion.markerNames': 'active_profile.txt names {name}.',
+        'profiles.markerSuggestion.apply': 'Set {name} as active',
+        'profiles.quarantine': 'Move aside',
 
         'saveActions.title': 'Save Actions',
         'saveActions.startNewTitle': 'Start New Save',
@@ -143,6 +162,8 @@ const translations: Record<Locale, Record<string, string>> = {
         'footer.markerFile': 'Marker file: active_profile.txt',
 
         'toast.tipPrefix': 'Tip: {hint}',
+        'recovery.done': 'Recovered {count} operation(s) interrupted by an unexpected shutdown.',
+        'recovery.failed': 'Could not recover {count} interrupted operation(s). They will be retried on the next start.',
 
         'status.loadingProfiles': 'Loading profiles...',
         'status.setupRequired': 'SaveGame path setup is required.',
@@ -161,6 +182,7 @@ const translations: Record<Locale, Record<string, string>> = {
         'status.selectProfileToCreateMarker': 'Select a profile first to create active_profile.txt.',
         'status.creatingMarker': 'Creating marker for {name}...',
         'status.markerCreated': 'active_profile.txt created for {name}.',
+        'status.markerRewritten': 'active_profile.txt now points to {name}.',
         'status.switchingProfile': 'Switching to {name}...',
         'status.activeProfileNow': 'Active profile: {name}',
         'status.chooseFreshName': 'Choose a profile name before preparing a fresh save.',
@@ -173,14 +195,20 @@ const translations: Record<Locale, Record<string, string>> = {
         'status.savingCurrentInto': 'Saving current root data into {name}...',
         'status.chooseProfileToExport': 'Choose a profile to export first.',
         'status.exportingBundle': 'Exporting {name} bundle...',
-        'status.bundleExported': 'Bundle exported: {path}',
+        'status.bundleExported': 'Bundle exported and verified: {path} ({files} files, {size})',
         'status.enterImportProfileName': 'Enter a new profile name for import.',
         'status.chooseImportDestination': 'Choose destination profile first.',
         'status.chooseBundleFirst': 'Browse and choose a .zip bundle to import first.',
         'status.importingBundle': 'Importing bundle into {name}...',
         'status.bundleImported': 'Bundle imported into profile {name}.',
+        'status.bundleImportedTrusted': 'Bundle imported into profile {name}. Signed by trusted author {author}.',
+        'status.bundleImportedUntrusted': 'Bundle imported into profile {name}. Warning: signed by {author}, who is not in your trusted keys.',
+        'status.bundleImportedUnsigned': 'Bundle imported into profile {name}. Warning: the bundle is not signed.',
         'status.renamingProfile': 'Renaming {oldName} to {newName}...',
         'status.profileRenamed': 'Profile renamed to {name}.',
+        'status.profileDetailsSaved': 'Details saved for {name}.',
+        'status.profileRepaired': 'Repaired {name}; it is listed as a profile again.',
+        'status.entryQuarantined': 'Moved {name} to {path}.',
         'status.enterNameBeforeSavingCurrent': 'Enter a profile name before saving current progress.',
         'status.savedCurrentAndSetActive': 'Saved current progress to {name} and set it as active.',
         'status.savedCurrent': 'Saved current progress to {name}.',
@@ -214,8 +242,12 @@ const translations: Record<Locale, Record<string, string>> = {
         'error.saveCurrent': 'Save current failed',
         'error.exportBundle': 'Bundle export failed',
         'error.importBundle': 'Bundle import failed',
+        'error.inspectBundle': 'Could not read the bundle',
         'error.openFilePicker': 'Failed to open file picker',
         'error.rename': 'Rename failed',
+        'error.profileDetails': 'Could not save profile details',
+        'error.repairProfile': 'Repair failed',
+        'error.quarantineEntry': 'Could not move the item aside',
         'error.delete': 'Delete failed',
         'error.openUpdateLink': 'Could not open {label}',
         'error.diagnostics': 'Diagnostics failed',
@@ -230,6 +262,26 @@ const translations: Record<Locale, Record<string, string>> = {
         'feedback.profileNameInvalid.hint': 'Use letters/numbers and avoid Windows invalid filename characters.',
         'feedback.profileExists.message': 'That profile name already exists.',
         'feedback.profileExists.hint': 'Pick another name or rename the existing profile first.',
+        'feedback.operationPending.message': 'That action is already running or waiting to run.',
+        'feedback.operationPending.hint': 'Wait for the current action to finish before repeating it.',
+        'operations.waiting': 'Waiting for {operation} to finish ({count} queued).',
+        'operations.progress': 'Running {operation}: {done} of {total} files',
+        'operations.cancelling': 'Cancelling {operation} and rolling back...',
+        'operations.cancel': 'Cancel',
+        'operations.progressAriaLabel': 'Operation progress',
+        'feedback.busy.message': 'Another Heat Save Manager window or script is changing this SaveGame folder.',
+        'feedback.busy.hint': 'Wait for it to finish, then try again.',
+        'feedback.entryInUse.message': 'This item belongs to an operation that was interrupted.',
+        'feedback.entryInUse.hint': 'Restart the app so recovery can finish or undo it first.',
+        'feedback.exportVerification.message': 'The exported bundle did not pass verification, so it was discarded.',
+        'feedback.exportVerification.hint': 'Any older bundle at that path was kept. Check free disk space and try again.',
+        'feedback.activeNotReloaded.message': 'The bundle was imported, but the game save could not be reloaded from it.',
+        'feedback.activeNotReloaded.hint': 'Close the game if it is running, then switch to the profile to load it.',
+        'feedback.profileExists.message': 'A profile with this name already exists.',
+        'feedback.profileExists.hint': 'Choose how to handle the existing profile, or import under another name.',
+        'feedback.backupNotKept.hint': 'The switch worked, but its pre-switch backup was not stored: {detail}',
+        'feedback.cancelled.message': 'The operation was cancelled.',
+        'feedback.cancelled.hint': 'Files changed before cancelling were rolled back; your saves are as they were.',
         'feedback.profileNotFound.message': 'Selected profile no longer exists.',
         'feedback.profileNotFound.hint': 'Refresh profiles and try again.',
         'feedback.saveFirstNeedsActive.message': 'Save-first needs an active profile first.',
@@ -261,6 +313,7 @@ const translations: Record<Locale, Record<string, string>> = {
         'modal.freshConfirm.saveUnavailable': 'Save first is unavailable until you set an active profile in Diagnostics.',
         'modal.freshConfirm.nameConflict': 'Choose a different fresh profile name to keep your current active profile backup separate.',
         'modal.freshConfirm.note': 'Saving first updates your active profile backup with current savegame and wraps. Skipping starts fresh and discards the current root progress.',
+        'modal.freshConfirm.unsavedWarning': 'The root save has {count} change(s) not yet saved to {active}. Skipping the save discards them.',
         'modal.freshConfirm.skipSave': 'Skip save',
         'modal.freshConfirm.saveFirst': 'Save first',
         'modal.freshName.title': 'New Profile Name',
@@ -270,6 +323,9 @@ const translations: Record<Locale, Record<string, string>> = {
         'modal.switch.title': 'Switch Active Profile',
         'modal.switch.description': 'Switch active profile from {current} to {next}?',
         'modal.switch.note': 'This immediately loads the selected profile into your SaveGame root folders.',
+        'modal.switch.unsavedWarning': 'The root save has {count} change(s) not saved to {active}, and saving before switching is turned off. Those changes will be lost.',
+        'modal.switch.mismatchWarning': 'The root save matches {matched}, not the active profile {active}. The marker may be wrong.',
+        'modal.switch.mismatchSaveWarning': 'The root save matches {matched}, not the active profile {active}. Switching saves it into {active} first, overwriting that profile with {matched}\'s progress.',
         'modal.switch.button': 'Switch profile',
         'modal.switch.switching': 'Switching...',
 
@@ -302,15 +358,45 @@ const translations: Record<Locale, Record<string, string>> = {
         'modal.import.bundleLabel': 'Bundle .zip file',
         'modal.import.selectedBundle': 'Selected bundle: {path}',
         'modal.import.button': 'Import Bundle',
+        'modal.import.preview.source': 'From profile {name} by {author}, exported {date}',
+        'modal.import.preview.unknownAuthor': 'an unknown author',
+        'modal.import.preview.noManifest': 'This bundle has no manifest (made by an older version), so its contents cannot be checked before import.',
+        'modal.import.preview.contents': '{files} files in {folders} folders, {size}',
+        'modal.import.preview.signature.trusted': 'Signed by trusted key {author}',
+        'modal.import.preview.signature.untrusted': 'Signed by {author}, whose key is not in your trusted list',
+        'modal.import.preview.signature.unsigned': 'Not signed: its origin cannot be confirmed',
+        'modal.import.preview.invalidLayout': 'The bundle does not contain both savegame and wraps folders, so it cannot be imported.',
+        'modal.import.preview.wrapperFolder': 'Everything is inside a {folder} folder; it will be unwrapped on import.',
+        'modal.import.preview.renamedFolders': 'Folder names will be fixed on import: {folders}',
+        'modal.import.preview.passphraseRequired': 'This bundle is encrypted. Import it from the command line with --passphrase-env.',
+        'modal.import.preview.limit.entries': 'Too many entries: {actual} (limit {maximum})',
+        'modal.import.preview.limit.file-bytes': '{path} is too large: {actual} (limit {maximum})',
+        'modal.import.preview.limit.total-bytes': 'Bundle is too large: {actual} (limit {maximum})',
+        'modal.import.conflict.label': 'Profile {name} already exists',
+        'modal.import.conflict.overwrite': 'Overwrite it',
+        'modal.import.conflict.overwrite.hint': 'A snapshot of {name} is taken first, so you can restore it later.',
+        'modal.import.conflict.merge-wraps': 'Add wraps only',
+        'modal.import.conflict.merge-wraps.hint': 'Keeps the progress in {name} and adds the bundle\'s wraps, replacing wraps with the same name. A snapshot is taken first.',
+        'modal.import.conflict.rename': 'Import as a new profile',
+        'modal.import.conflict.rename.hint': 'Leaves {name} untouched and imports under the next free name, such as {name} (2).',
+        'modal.import.conflict.activeWarning': '{name} is the active profile: your current progress is saved into it before the snapshot, and the game save is reloaded from the imported profile afterwards.',
 
         'modal.rename.title': 'Rename Profile',
         'modal.rename.description': 'Choose a new name for {name}.',
+        'modal.details.title': 'Profile Details',
+        'modal.details.description': 'Notes, tags and colour for {name}. They stay on this PC and are not exported.',
+        'modal.details.notes': 'Notes',
+        'modal.details.tags': 'Tags',
+        'modal.details.tagsPlaceholder': 'story, drift, co-op',
+        'modal.details.color': 'Colour',
+        'modal.details.clearColor': 'No colour',
 
         'modal.delete.title': 'Delete Profile',
         'modal.delete.description': 'Choose which profile to delete.',
         'modal.delete.label': 'Profile to delete',
         'modal.delete.confirmation': 'Delete {name}? This action cannot be undone.',
         'modal.delete.activeDescription': 'Delete active profile {name}? Choose which profile becomes active next. This action cannot be undone.',
+        'modal.delete.unsavedWarning': 'The root save has {count} change(s) not saved to {name}. They will be lost along with the profile.',
         'modal.delete.replacementLabel': 'Next active profile',
         'modal.delete.onlyProfileHint': 'Create another profile before deleting the active one.',
 
@@ -330,6 +416,7 @@ const translations: Record<Locale, Record<string, string>> = {
         'common.closeForNow': 'Cerrar por ahora',
         'common.browse': 'Buscar...',
         'common.rename': 'Renombrar',
+        'common.save': 'Guardar',
         'common.delete': 'Eliminar',
         'common.show': 'Mostrar',
         'common.hide': 'Ocultar',
@@ -415,6 +502,24 @@ const translations: Record<Locale, Record<string, string>> = {
         'profiles.empty': 'No se encontraron perfiles en la carpeta Profiles.',
         'profiles.emptyHint': 'Crea uno con Iniciar nueva partida para comenzar.',
         'profiles.selectAria': 'Seleccionar perfil',
+        'profiles.details': 'Detalles',
+        'profiles.detailsSelectedAria': 'Editar notas y etiquetas del perfil seleccionado',
+        'profiles.lastSwitched': 'Jugado por ultima vez: {value}',
+        'profiles.lastSaved': 'Guardado por ultima vez: {value}',
+        'profiles.never': 'nunca',
+        'profiles.issuesTitle': '{count} elemento(s) de la carpeta Profiles no son perfiles utilizables',
+        'profiles.issue.missing-savegame': 'Le falta la carpeta savegame, así que no se puede cambiar a él.',
+        'profiles.issue.missing-wraps': 'Le falta la carpeta wraps. Reparar crea una vacía.',
+        'profiles.issue.not-directory': 'Es un archivo, no una carpeta de perfil.',
+        'profiles.issue.artifact': 'Restos de un cambio, guardado o importación interrumpido.',
+        'profiles.repair': 'Reparar',
+        'profiles.markerSuggestion.title': 'Puede que el marcador activo este mal',
+        'profiles.markerSuggestion.exact': 'La partida raiz es identica a {name}.',
+        'profiles.markerSuggestion.closest': 'La partida raiz se parece mas a {name} (coincide el {percent}% de los archivos).',
+        'profiles.markerSuggestion.markerMissing': 'Falta active_profile.txt.',
+        'profiles.markerSuggestion.markerNames': 'active_profile.txt indica {name}.',
+        'profiles.markerSuggestion.apply': 'Usar {name} como activo',
+        'profiles.quarantine': 'Apartar',
 
         'saveActions.title': 'Acciones de guardado',
         'saveActions.startNewTitle': 'Iniciar nueva partida',
@@ -452,6 +557,8 @@ const translations: Record<Locale, Record<string, string>> = {
         'footer.markerFile': 'Archivo marker: active_profile.txt',
 
         'toast.tipPrefix': 'Sugerencia: {hint}',
+        'recovery.done': 'Se recuperaron {count} operación(es) interrumpidas por un cierre inesperado.',
+        'recovery.failed': 'No se pudieron recuperar {count} operación(es) interrumpidas. Se reintentará en el próximo inicio.',
 
         'status.loadingProfiles': 'Cargando perfiles...',
         'status.setupRequired': 'Se requiere configurar la ruta SaveGame.',
@@ -470,6 +577,7 @@ const translations: Record<Locale, Record<string, string>> = {
         'status.selectProfileToCreateMarker': 'Selecciona un perfil para crear active_profile.txt.',
         'status.creatingMarker': 'Creando active_profile.txt para {name}...',
         'status.markerCreated': 'active_profile.txt creado y asignado a {name}.',
+        'status.markerRewritten': 'active_profile.txt ahora apunta a {name}.',
         'status.switchingProfile': 'Cambiando a {name}...',
         'status.activeProfileNow': 'Perfil activo: {name}',
         'status.chooseFreshName': 'Elige un nombre de perfil antes de preparar una partida nueva.',
@@ -482,14 +590,20 @@ const translations: Record<Locale, Record<string, string>> = {
         'status.savingCurrentInto': 'Guardando datos root actuales en {name}...',
         'status.chooseProfileToExport': 'Elige primero un perfil para exportar.',
         'status.exportingBundle': 'Exportando bundle de {name}...',
-        'status.bundleExported': 'Bundle exportado: {path}',
+        'status.bundleExported': 'Bundle exportado y verificado: {path} ({files} archivos, {size})',
         'status.enterImportProfileName': 'Ingresa un nombre de perfil nuevo para importar.',
         'status.chooseImportDestination': 'Elige primero un perfil de destino.',
         'status.chooseBundleFirst': 'Busca y selecciona primero un bundle .zip para importar.',
         'status.importingBundle': 'Importando bundle en {name}...',
         'status.bundleImported': 'Bundle importado en el perfil {name}.',
+        'status.bundleImportedTrusted': 'Bundle importado en el perfil {name}. Firmado por el autor de confianza {author}.',
+        'status.bundleImportedUntrusted': 'Bundle importado en el perfil {name}. Aviso: firmado por {author}, que no está en tus claves de confianza.',
+        'status.bundleImportedUnsigned': 'Bundle importado en el perfil {name}. Aviso: el bundle no está firmado.',
         'status.renamingProfile': 'Renombrando {oldName} a {newName}...',
         'status.profileRenamed': 'Perfil renombrado a {name}.',
+        'status.profileDetailsSaved': 'Detalles guardados para {name}.',
+        'status.profileRepaired': '{name} reparado; vuelve a aparecer como perfil.',
+        'status.entryQuarantined': '{name} se movió a {path}.',
         'status.enterNameBeforeSavingCurrent': 'Ingresa un nombre de perfil antes de guardar progreso actual.',
         'status.savedCurrentAndSetActive': 'Progreso actual guardado en {name} y definido como activo.',
         'status.savedCurrent': 'Progreso actual guardado en {name}.',
@@ -523,8 +637,12 @@ const translations: Record<Locale, Record<string, string>> = {
         'error.saveCurrent': 'Fallo al guardar progreso actual',
         'error.exportBundle': 'Fallo al exportar bundle',
         'error.importBundle': 'Fallo al importar bundle',
+        'error.inspectBundle': 'No se pudo leer el bundle',
         'error.openFilePicker': 'Fallo al abrir selector de archivos',
         'error.rename': 'Fallo al renombrar',
+        'error.profileDetails': 'No se pudieron guardar los detalles del perfil',
+        'error.repairProfile': 'Fallo al reparar',
+        'error.quarantineEntry': 'No se pudo apartar el elemento',
         'error.delete': 'Fallo al eliminar',
         'error.openUpdateLink': 'No se pudo abrir {label}',
         'error.diagnostics': 'Fallo el diagnostico',
@@ -539,6 +657,26 @@ const translations: Record<Locale, Record<string, string>> = {
         'feedback.profileNameInvalid.hint': 'Usa letras/numeros y evita caracteres invalidos de Windows.',
         'feedback.profileExists.message': 'Ese nombre de perfil ya existe.',
         'feedback.profileExists.hint': 'Elige otro nombre o renombra el existente primero.',
+        'feedback.operationPending.message': 'Esa acción ya está en curso o esperando su turno.',
+        'feedback.operationPending.hint': 'Espera a que termine la acción actual antes de repetirla.',
+        'operations.waiting': 'Esperando a que termine {operation} ({count} en cola).',
+        'operations.progress': 'Ejecutando {operation}: {done} de {total} archivos',
+        'operations.cancelling': 'Cancelando {operation} y revirtiendo cambios...',
+        'operations.cancel': 'Cancelar',
+        'operations.progressAriaLabel': 'Progreso de la operación',
+        'feedback.busy.message': 'Otra ventana o script de Heat Save Manager está modificando esta carpeta SaveGame.',
+        'feedback.busy.hint': 'Espera a que termine e intenta otra vez.',
+        'feedback.entryInUse.message': 'Este elemento pertenece a una operación que se interrumpió.',
+        'feedback.entryInUse.hint': 'Reinicia la app para que la recuperación la termine o la deshaga primero.',
+        'feedback.exportVerification.message': 'El bundle exportado no superó la verificación y se descartó.',
+        'feedback.exportVerification.hint': 'Se conservó cualquier bundle anterior en esa ruta. Comprueba el espacio libre e inténtalo de nuevo.',
+        'feedback.activeNotReloaded.message': 'El bundle se importó, pero no se pudo recargar la partida desde él.',
+        'feedback.activeNotReloaded.hint': 'Cierra el juego si está abierto y cambia al perfil para cargarlo.',
+        'feedback.profileExists.message': 'Ya existe un perfil con este nombre.',
+        'feedback.profileExists.hint': 'Elige qué hacer con el perfil existente o importa con otro nombre.',
+        'feedback.backupNotKept.hint': 'El cambio funcionó, pero no se guardó su copia previa: {detail}',
+        'feedback.cancelled.message': 'La operación fue cancelada.',
+        'feedback.cancelled.hint': 'Los archivos modificados antes de cancelar se revirtieron; tus partidas quedaron como estaban.',
         'feedback.profileNotFound.message': 'El perfil seleccionado ya no existe.',
         'feedback.profileNotFound.hint': 'Actualiza perfiles e intenta otra vez.',
         'feedback.saveFirstNeedsActive.message': 'Guardar primero requiere un perfil activo.',
@@ -570,6 +708,7 @@ const translations: Record<Locale, Record<string, string>> = {
         'modal.freshConfirm.saveUnavailable': 'Guardar primero no esta disponible hasta definir un perfil activo en Diagnosticos.',
         'modal.freshConfirm.nameConflict': 'Elige otro nombre para mantener separado el respaldo del perfil activo.',
         'modal.freshConfirm.note': 'Guardar primero actualiza el respaldo del perfil activo con savegame y wraps actuales. Omitir empieza en limpio y descarta el progreso actual de la raiz.',
+        'modal.freshConfirm.unsavedWarning': 'La partida raiz tiene {count} cambio(s) aun sin guardar en {active}. Omitir el guardado los descarta.',
         'modal.freshConfirm.skipSave': 'Omitir guardado',
         'modal.freshConfirm.saveFirst': 'Guardar primero',
         'modal.freshName.title': 'Nombre del nuevo perfil',
@@ -579,6 +718,9 @@ const translations: Record<Locale, Record<string, string>> = {
         'modal.switch.title': 'Cambiar perfil activo',
         'modal.switch.description': 'Cambiar perfil activo de {current} a {next}?',
         'modal.switch.note': 'Esto carga de inmediato el perfil seleccionado en las carpetas raiz de SaveGame.',
+        'modal.switch.unsavedWarning': 'La partida raiz tiene {count} cambio(s) sin guardar en {active} y guardar antes de cambiar esta desactivado. Esos cambios se perderan.',
+        'modal.switch.mismatchWarning': 'La partida raiz coincide con {matched}, no con el perfil activo {active}. Puede que el marcador este mal.',
+        'modal.switch.mismatchSaveWarning': 'La partida raiz coincide con {matched}, no con el perfil activo {active}. Al cambiar se guardara primero en {active}, sobrescribiendo ese perfil con el progreso de {matched}.',
         'modal.switch.button': 'Cambiar perfil',
         'modal.switch.switching': 'Cambiando...',
 
@@ -611,15 +753,45 @@ const translations: Record<Locale, Record<string, string>> = {
         'modal.import.bundleLabel': 'Archivo bundle .zip',
         'modal.import.selectedBundle': 'Bundle seleccionado: {path}',
         'modal.import.button': 'Importar bundle',
+        'modal.import.preview.source': 'Del perfil {name} de {author}, exportado el {date}',
+        'modal.import.preview.unknownAuthor': 'un autor desconocido',
+        'modal.import.preview.noManifest': 'Este bundle no tiene manifiesto (lo creó una versión anterior), así que su contenido no se puede comprobar antes de importar.',
+        'modal.import.preview.contents': '{files} archivos en {folders} carpetas, {size}',
+        'modal.import.preview.signature.trusted': 'Firmado por la clave de confianza {author}',
+        'modal.import.preview.signature.untrusted': 'Firmado por {author}, cuya clave no está en tu lista de confianza',
+        'modal.import.preview.signature.unsigned': 'Sin firmar: no se puede confirmar su origen',
+        'modal.import.preview.invalidLayout': 'El bundle no contiene las carpetas savegame y wraps, así que no se puede importar.',
+        'modal.import.preview.wrapperFolder': 'Todo está dentro de una carpeta {folder}; se quitará al importar.',
+        'modal.import.preview.renamedFolders': 'Se corregirán los nombres de carpeta al importar: {folders}',
+        'modal.import.preview.passphraseRequired': 'Este bundle está cifrado. Impórtalo desde la línea de comandos con --passphrase-env.',
+        'modal.import.preview.limit.entries': 'Demasiadas entradas: {actual} (límite {maximum})',
+        'modal.import.preview.limit.file-bytes': '{path} es demasiado grande: {actual} (límite {maximum})',
+        'modal.import.preview.limit.total-bytes': 'El bundle es demasiado grande: {actual} (límite {maximum})',
+        'modal.import.conflict.label': 'El perfil {name} ya existe',
+        'modal.import.conflict.overwrite': 'Sobrescribirlo',
+        'modal.import.conflict.overwrite.hint': 'Antes se crea una instantánea de {name} para que puedas restaurarlo más tarde.',
+        'modal.import.conflict.merge-wraps': 'Añadir solo los wraps',
+        'modal.import.conflict.merge-wraps.hint': 'Conserva el progreso de {name} y añade los wraps del bundle, reemplazando los que tengan el mismo nombre. Antes se crea una instantánea.',
+        'modal.import.conflict.rename': 'Importar como perfil nuevo',
+        'modal.import.conflict.rename.hint': 'Deja {name} intacto e importa con el siguiente nombre libre, como {name} (2).',
+        'modal.import.conflict.activeWarning': '{name} es el perfil activo: tu progreso actual se guarda en él antes de la instantánea y la partida se recarga desde el perfil importado al terminar.',
 
         'modal.rename.title': 'Renombrar perfil',
         'modal.rename.description': 'Elige un nombre nuevo para {name}.',
+        'modal.details.title': 'Detalles del perfil',
+        'modal.details.description': 'Notas, etiquetas y color de {name}. Se quedan en este PC y no se exportan.',
+        'modal.details.notes': 'Notas',
+        'modal.details.tags': 'Etiquetas',
+        'modal.details.tagsPlaceholder': 'historia, drift, cooperativo',
+        'modal.details.color': 'Color',
+        'modal.details.clearColor': 'Sin color',
 
         'modal.delete.title': 'Eliminar perfil',
         'modal.delete.description': 'Elige que perfil deseas eliminar.',
         'modal.delete.label': 'Perfil a eliminar',
         'modal.delete.confirmation': 'Eliminar {name}? Esta accion no se puede deshacer.',
         'modal.delete.activeDescription': 'Eliminar el perfil activo {name}? Elige que perfil quedara activo despues. Esta accion no se puede deshacer.',
+        'modal.delete.unsavedWarning': 'La partida raiz tiene {count} cambio(s) sin guardar en {name}. Se perderan junto con el perfil.',
         'modal.delete.replacementLabel': 'Siguiente perfil activo',
         'modal.delete.onlyProfileHint': 'Crea otro perfil antes de eliminar el perfil activo.',
 
@@ -0,0 +1,192 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the audit log inside the app config directory.
+const FileName = "audit.jsonl"
+
+// Outcomes recorded for an operation.
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeCancelled = "cancelled"
+	// OutcomeRejected: the operation never started, for example because
+	// another one held the SaveGame folder.
+	OutcomeRejected = "rejected"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+var ErrLogDirRequired = errors.New("audit log directory is required")
+
+// Entry is one finished operation. RolledBack is set when the operation
+// undid its own changes before returning.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Operation  string    `json:"operation"`
+	Target     string    `json:"target,omitempty"`
+	Outcome    string    `json:"outcome"`
+	RolledBack bool      `json:"rolledBack,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+	Paths      []string  `json:"paths,omitempty"`
+}
+
+// Filter selects entries for Query. Empty fields match everything; Target
+// matches case-insensitively.
+type Filter struct {
+	Operation string    `json:"operation"`
+	Target    string    `json:"target"`
+	Outcome   string    `json:"outcome"`
+	Since     time.Time `json:"since"`
+	Until     time.Time `json:"until"`
+	Offset    int       `json:"offset"`
+	Limit     int       `json:"limit"`
+}
+
+// Page is one slice of the matching entries, newest first, and how many
+// entries matched in total.
+type Page struct {
+	Entries []Entry `json:"entries"`
+	Total   int     `json:"total"`
+}
+
+// Log is an append-only JSON-lines file. A nil Log records nothing.
+type Log struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func New(dir string) *Log {
+	return &Log{dir: dir}
+}
+
+func (l *Log) Path() string {
+	return filepath.Join(l.dir, FileName)
+}
+
+// Append writes entry as one line. Each line is written with a single call
+// so concurrent app instances do not interleave within an entry.
+func (l *Log) Append(entry Entry) error {
+	if l == nil {
+		return nil
+	}
+
+	if strings.TrimSpace(l.dir) == "" {
+		return ErrLogDirRequired
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(l.Path(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Query returns the entries matching filter, newest first. Lines that do not
+// parse, such as one torn by a crash mid-write, are skipped.
+func (l *Log) Query(filter Filter) (Page, error) {
+	page := Page{Entries: []Entry{}}
+	if l == nil {
+		return page, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.Open(l.Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return page, nil
+		}
+		return page, err
+	}
+	defer file.Close()
+
+	matches := []Entry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		if filter.matches(entry) {
+			matches = append(matches, entry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return page, err
+	}
+
+	page.Total = len(matches)
+	offset := max(filter.Offset, 0)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	for i := len(matches) - 1 - offset; i >= 0 && len(page.Entries) < limit; i-- {
+		page.Entries = append(page.Entries, matches[i])
+	}
+
+	return page, nil
+}
+
+func (f Filter) matches(entry Entry) bool {
+	if f.Operation != "" && entry.Operation != f.Operation {
+		return false
+	}
+
+	if target := strings.TrimSpace(f.Target); target != "" && !strings.EqualFold(entry.Target, target) {
+		return false
+	}
+
+	if f.Outcome != "" && entry.Outcome != f.Outcome {
+		return false
+	}
+
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+
+	return true
+}
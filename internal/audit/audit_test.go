@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestQueryReturnsNewestFirstAndPages(t *testing.T) {
+	t.Parallel()
+
+	log := New(t.TempDir())
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, operation := range []string{"switch", "save", "import"} {
+		if err := log.Append(Entry{Time: base.Add(time.Duration(i) * time.Minute), Operation: operation, Outcome: OutcomeSucceeded}); err != nil {
+			t.Fatalf("append %s: %v", operation, err)
+		}
+	}
+
+	page, err := log.Query(Filter{Limit: 2})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	if page.Total != 3 || len(page.Entries) != 2 || page.Entries[0].Operation != "import" || page.Entries[1].Operation != "save" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	page, err = log.Query(Filter{Offset: 2, Limit: 2})
+	if err != nil {
+		t.Fatalf("query second page: %v", err)
+	}
+
+	if page.Total != 3 || len(page.Entries) != 1 || page.Entries[0].Operation != "switch" {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+}
+
+func TestQueryFiltersEntries(t *testing.T) {
+	t.Parallel()
+
+	log := New(t.TempDir())
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []Entry{
+		{Time: base, Operation: "switch", Target: "Alpha", Outcome: OutcomeSucceeded},
+		{Time: base.Add(time.Hour), Operation: "switch", Target: "Beta", Outcome: OutcomeFailed, RolledBack: true},
+		{Time: base.Add(2 * time.Hour), Operation: "delete", Target: "alpha", Outcome: OutcomeSucceeded},
+	}
+	for _, entry := range entries {
+		if err := log.Append(entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		filter   Filter
+		expected int
+	}{
+		{name: "operation", filter: Filter{Operation: "switch"}, expected: 2},
+		{name: "target ignores case", filter: Filter{Target: "ALPHA"}, expected: 2},
+		{name: "outcome", filter: Filter{Outcome: OutcomeFailed}, expected: 1},
+		{name: "since", filter: Filter{Since: base.Add(30 * time.Minute)}, expected: 2},
+		{name: "until", filter: Filter{Until: base.Add(30 * time.Minute)}, expected: 1},
+	}
+
+	for _, tc := range cases {
+		page, err := log.Query(tc.filter)
+		if err != nil {
+			t.Fatalf("%s: query: %v", tc.name, err)
+		}
+
+		if page.Total != tc.expected {
+			t.Fatalf("%s: expected %d entries, got %+v", tc.name, tc.expected, page)
+		}
+	}
+}
+
+func TestQuerySkipsTornLines(t *testing.T) {
+	t.Parallel()
+
+	log := New(t.TempDir())
+	if err := log.Append(Entry{Operation: "save", Outcome: OutcomeSucceeded}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	file, err := os.OpenFile(log.Path(), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	if _, err := file.WriteString(`{"operation":"swi`); err != nil {
+		t.Fatalf("write torn line: %v", err)
+	}
+	file.Close()
+
+	page, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	if page.Total != 1 || page.Entries[0].Operation != "save" {
+		t.Fatalf("expected only the complete entry, got %+v", page)
+	}
+}
+
+func TestMissingOrNilLogIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	page, err := New(t.TempDir()).Query(Filter{})
+	if err != nil || page.Total != 0 || page.Entries == nil {
+		t.Fatalf("expected empty page for missing file, got %+v err=%v", page, err)
+	}
+
+	var log *Log
+	if err := log.Append(Entry{Operation: "save"}); err != nil {
+		t.Fatalf("append on nil log: %v", err)
+	}
+
+	page, err = log.Query(Filter{})
+	if err != nil || page.Total != 0 {
+		t.Fatalf("expected empty page for nil log, got %+v err=%v", page, err)
+	}
+}
@@ -0,0 +1,641 @@
+package backups
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/journal"
+)
+
+var (
+	ErrRootPathRequired       = errors.New("backup root path is required")
+	ErrBackupIDRequired       = errors.New("backup id is required")
+	ErrBackupIDInvalid        = errors.New("backup id is invalid")
+	ErrBackupNotFound         = errors.New("backup not found")
+	ErrFileOperationsRequired = errors.New("file operations are required")
+)
+
+const (
+	DirName          = ".switch-backups"
+	metadataFileName = "backup.json"
+	savegameDirName  = "savegame"
+	wrapsDirName     = "wraps"
+
+	ReasonSwitch  = "switch"
+	ReasonRestore = "restore"
+)
+
+type Backup struct {
+	ID          string    `json:"id"`
+	Reason      string    `json:"reason"`
+	FromProfile string    `json:"fromProfile"`
+	ToProfile   string    `json:"toProfile"`
+	CreatedAt   time.Time `json:"createdAt"`
+	HasSavegame bool      `json:"hasSavegame"`
+	HasWraps    bool      `json:"hasWraps"`
+	TotalBytes  int64     `json:"totalBytes"`
+}
+
+type Retention struct {
+	MaxCount int
+	MaxAge   time.Duration
+}
+
+// MarkerWriter points the marker at the profile a restored backup belongs to.
+type MarkerWriter interface {
+	WriteActiveProfile(profileName string) error
+}
+
+type Service struct {
+	rootPath  string
+	ops       fsops.Operations
+	retention Retention
+	journal   *journal.Journal
+	marker    MarkerWriter
+	now       func() time.Time
+}
+
+func NewService(rootPath string, ops fsops.Operations, retention Retention) *Service {
+	return &Service{
+		rootPath:  rootPath,
+		ops:       ops,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// SetJournal records each restore step so an interrupted restore can be
+// finished or undone by recovery on the next start.
+func (s *Service) SetJournal(j *journal.Journal) {
+	s.journal = j
+}
+
+// SetMarker makes Restore point the marker at the profile that owned the
+// restored progress, as part of the journaled restore.
+func (s *Service) SetMarker(marker MarkerWriter) {
+	s.marker = marker
+}
+
+// Keep moves an existing pre-switch backup tree (holding optional savegame and
+// wraps folders) into the managed store and applies the retention policy.
+func (s *Service) Keep(backupRoot string, fromProfile string, toProfile string) (string, error) {
+	if err := s.validateDependencies(); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.rootPath, 0o755); err != nil {
+		return "", err
+	}
+
+	item := s.newBackup(ReasonSwitch, fromProfile, toProfile)
+	if err := s.describe(backupRoot, &item); err != nil {
+		return "", err
+	}
+
+	if err := writeMetadata(backupRoot, item); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(backupRoot, filepath.Join(s.rootPath, item.ID)); err != nil {
+		return "", err
+	}
+
+	if err := s.prune(item.ID); err != nil {
+		return item.ID, fmt.Errorf("prune backups: %w", err)
+	}
+
+	return item.ID, nil
+}
+
+// Capture copies the savegame and wraps folders under sourceRoot into a new
+// backup without touching the source.
+func (s *Service) Capture(sourceRoot string, fromProfile string, reason string) (Backup, error) {
+	return s.capture(context.Background(), sourceRoot, fromProfile, reason)
+}
+
+func (s *Service) capture(ctx context.Context, sourceRoot string, fromProfile string, reason string, protectedIDs ...string) (Backup, error) {
+	if err := s.validateDependencies(); err != nil {
+		return Backup{}, err
+	}
+
+	if err := os.MkdirAll(s.rootPath, 0o755); err != nil {
+		return Backup{}, err
+	}
+
+	stagingRoot, err := os.MkdirTemp(s.rootPath, "capture.tmp-*")
+	if err != nil {
+		return Backup{}, err
+	}
+	defer os.RemoveAll(stagingRoot)
+
+	for _, dirName := range []string{savegameDirName, wrapsDirName} {
+		source := filepath.Join(sourceRoot, dirName)
+		if info, err := os.Stat(source); err != nil || !info.IsDir() {
+			continue
+		}
+
+		if err := s.ops.CopyDir(ctx, source, filepath.Join(stagingRoot, dirName)); err != nil {
+			return Backup{}, err
+		}
+	}
+
+	item := s.newBackup(reason, fromProfile, "")
+	if err := s.describe(stagingRoot, &item); err != nil {
+		return Backup{}, err
+	}
+
+	if err := writeMetadata(stagingRoot, item); err != nil {
+		return Backup{}, err
+	}
+
+	if err := os.Rename(stagingRoot, filepath.Join(s.rootPath, item.ID)); err != nil {
+		return Backup{}, err
+	}
+
+	if err := s.prune(append(protectedIDs, item.ID)...); err != nil {
+		return item, fmt.Errorf("prune backups: %w", err)
+	}
+
+	return item, nil
+}
+
+func (s *Service) List() ([]Backup, error) {
+	if strings.TrimSpace(s.rootPath) == "" {
+		return nil, ErrRootPathRequired
+	}
+
+	entries, err := os.ReadDir(s.rootPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Backup{}, nil
+		}
+		return nil, err
+	}
+
+	items := make([]Backup, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.Contains(entry.Name(), ".tmp-") {
+			continue
+		}
+
+		item, err := readMetadata(filepath.Join(s.rootPath, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].ID > items[j].ID
+		}
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+
+	return items, nil
+}
+
+func (s *Service) Get(backupID string) (Backup, error) {
+	backupRoot, err := s.backupRoot(backupID)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	return readMetadata(backupRoot)
+}
+
+// Restore replaces the savegame and wraps folders under targetRoot with the
+// copies held by the backup. The current contents of targetRoot are captured
+// as a new backup first so the restore itself can be undone. Both folders are
+// staged next to the root before either is swapped in, and the swap is
+// journaled, so a failure or crash never leaves one folder restored and the
+// other not. A folder the backup does not hold is restored empty rather than
+// kept from the current save.
+func (s *Service) Restore(ctx context.Context, backupID string, targetRoot string, currentProfile string) (Backup, error) {
+	if s.ops == nil {
+		return Backup{}, ErrFileOperationsRequired
+	}
+
+	backupRoot, err := s.backupRoot(backupID)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	item, err := readMetadata(backupRoot)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	if _, err := s.capture(ctx, targetRoot, currentProfile, ReasonRestore, item.ID); err != nil {
+		return Backup{}, fmt.Errorf("back up current save before restore: %w", err)
+	}
+
+	workParent := filepath.Join(targetRoot, ".backup")
+	if err := os.MkdirAll(workParent, 0o755); err != nil {
+		return Backup{}, err
+	}
+
+	// The current folders are parked directly under workRoot, the staged
+	// copies under its staged folder.
+	workRoot, err := os.MkdirTemp(workParent, "restore-*")
+	if err != nil {
+		return Backup{}, err
+	}
+	stagingRoot := filepath.Join(workRoot, "staged")
+
+	markerTarget := ""
+	if item.FromProfile != "" && !strings.EqualFold(item.FromProfile, strings.TrimSpace(currentProfile)) {
+		markerTarget = item.FromProfile
+	}
+
+	record, err := s.journal.Begin(journal.Entry{
+		Operation:       journal.OperationRestoreBackup,
+		Phase:           journal.PhaseStaging,
+		SwitchTarget:    markerTarget,
+		PreviousProfile: strings.TrimSpace(currentProfile),
+		StagingRoot:     stagingRoot,
+		BackupRoot:      workRoot,
+	})
+	if err != nil {
+		_ = removeWorkRoot(workRoot)
+		return Backup{}, fmt.Errorf("record restore: %w", err)
+	}
+
+	if err := s.stage(ctx, backupRoot, stagingRoot, item); err != nil {
+		return Backup{}, abandonRestore(record, workRoot, err)
+	}
+
+	hadSavegame, err := dirExists(filepath.Join(targetRoot, savegameDirName))
+	if err != nil {
+		return Backup{}, abandonRestore(record, workRoot, err)
+	}
+
+	hadWraps, err := dirExists(filepath.Join(targetRoot, wrapsDirName))
+	if err != nil {
+		return Backup{}, abandonRestore(record, workRoot, err)
+	}
+
+	// From here on recovery puts back whatever already sits in workRoot.
+	err = record.Advance(journal.PhaseReplacing, func(entry *journal.Entry) {
+		entry.HadSavegame = hadSavegame
+		entry.HadWraps = hadWraps
+	})
+	if err != nil {
+		return Backup{}, abandonRestore(record, workRoot, fmt.Errorf("record restore: %w", err))
+	}
+
+	had := map[string]bool{savegameDirName: hadSavegame, wrapsDirName: hadWraps}
+	err = swapIn(targetRoot, stagingRoot, workRoot, had)
+	if err == nil {
+		err = record.Advance(journal.PhaseReplaced, nil)
+	}
+	if err == nil && markerTarget != "" && s.marker != nil {
+		err = s.marker.WriteActiveProfile(markerTarget)
+	}
+	if err != nil {
+		// A failed rollback leaves the entry pending so recovery retries it.
+		if rollbackErr := rollbackSwap(targetRoot, workRoot, had); rollbackErr != nil {
+			return Backup{}, fmt.Errorf("restore failed: %w; rollback failed: %v", err, rollbackErr)
+		}
+
+		return Backup{}, abandonRestore(record, workRoot, err)
+	}
+
+	if err := removeWorkRoot(workRoot); err != nil {
+		return item, fmt.Errorf("clean up restore: %w", err)
+	}
+
+	if err := record.Finish(); err != nil {
+		return item, fmt.Errorf("record restore: %w", err)
+	}
+
+	return item, nil
+}
+
+// stage copies the backup's folders into stagingRoot, creating an empty one
+// for each folder the backup does not hold.
+func (s *Service) stage(ctx context.Context, backupRoot string, stagingRoot string, item Backup) error {
+	held := map[string]bool{savegameDirName: item.HasSavegame, wrapsDirName: item.HasWraps}
+	for _, dirName := range []string{savegameDirName, wrapsDirName} {
+		target := filepath.Join(stagingRoot, dirName)
+		if !held[dirName] {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.ops.CopyDir(ctx, filepath.Join(backupRoot, dirName), target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// swapIn parks each current root folder in workRoot and renames its staged
+// copy into place. Everything lives under targetRoot, so each step is a
+// single rename.
+func swapIn(targetRoot string, stagingRoot string, workRoot string, had map[string]bool) error {
+	for _, dirName := range []string{savegameDirName, wrapsDirName} {
+		target := filepath.Join(targetRoot, dirName)
+		if had[dirName] {
+			if err := os.Rename(target, filepath.Join(workRoot, dirName)); err != nil {
+				return err
+			}
+		}
+
+		if err := os.Rename(filepath.Join(stagingRoot, dirName), target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollbackSwap puts the parked root folders back. A folder that was never
+// parked is still the original and is left alone.
+func rollbackSwap(targetRoot string, workRoot string, had map[string]bool) error {
+	for _, dirName := range []string{savegameDirName, wrapsDirName} {
+		target := filepath.Join(targetRoot, dirName)
+		parked := filepath.Join(workRoot, dirName)
+		if had[dirName] {
+			if exists, err := dirExists(parked); err != nil || !exists {
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if err := os.RemoveAll(target); err != nil {
+			return err
+		}
+
+		if !had[dirName] {
+			continue
+		}
+
+		if err := os.Rename(parked, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// abandonRestore drops a restore whose root folders are unchanged.
+func abandonRestore(record *journal.Record, workRoot string, cause error) error {
+	if err := removeWorkRoot(workRoot); err != nil {
+		return fmt.Errorf("%w; cleanup failed: %v", cause, err)
+	}
+
+	if err := record.Finish(); err != nil {
+		return fmt.Errorf("%w; record restore: %v", cause, err)
+	}
+
+	return cause
+}
+
+// removeWorkRoot removes a restore's scratch tree and its parent once empty.
+func removeWorkRoot(workRoot string) error {
+	if err := os.RemoveAll(workRoot); err != nil {
+		return err
+	}
+
+	workParent := filepath.Dir(workRoot)
+	entries, err := os.ReadDir(workParent)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if len(entries) == 0 {
+		return os.Remove(workParent)
+	}
+
+	return nil
+}
+
+func (s *Service) Delete(backupID string) error {
+	if s.ops == nil {
+		return ErrFileOperationsRequired
+	}
+
+	backupRoot, err := s.backupRoot(backupID)
+	if err != nil {
+		return err
+	}
+
+	return s.ops.RemoveDir(backupRoot)
+}
+
+func (s *Service) prune(protectedIDs ...string) error {
+	items, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Time{}
+	if s.retention.MaxAge > 0 {
+		cutoff = s.now().UTC().Add(-s.retention.MaxAge)
+	}
+
+	for index, item := range items {
+		if containsID(protectedIDs, item.ID) {
+			continue
+		}
+
+		tooMany := s.retention.MaxCount > 0 && index >= s.retention.MaxCount
+		tooOld := !cutoff.IsZero() && item.CreatedAt.Before(cutoff)
+		if !tooMany && !tooOld {
+			continue
+		}
+
+		if err := s.ops.RemoveDir(filepath.Join(s.rootPath, item.ID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) newBackup(reason string, fromProfile string, toProfile string) Backup {
+	createdAt := s.now().UTC()
+	base := strings.ReplaceAll(createdAt.Format("20060102-150405.000"), ".", "")
+	id := base
+	for attempt := 1; ; attempt++ {
+		if _, err := os.Stat(filepath.Join(s.rootPath, id)); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", base, attempt)
+	}
+
+	return Backup{
+		ID:          id,
+		Reason:      reason,
+		FromProfile: strings.TrimSpace(fromProfile),
+		ToProfile:   strings.TrimSpace(toProfile),
+		CreatedAt:   createdAt,
+	}
+}
+
+func (s *Service) describe(backupRoot string, item *Backup) error {
+	for _, dirName := range []string{savegameDirName, wrapsDirName} {
+		dir := filepath.Join(backupRoot, dirName)
+		info, err := os.Stat(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		if !info.IsDir() {
+			continue
+		}
+
+		if dirName == savegameDirName {
+			item.HasSavegame = true
+		} else {
+			item.HasWraps = true
+		}
+
+		err = filepath.WalkDir(dir, func(path string, d os.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			entryInfo, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			item.TotalBytes += entryInfo.Size()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) backupRoot(backupID string) (string, error) {
+	id, err := validateBackupID(backupID)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(s.rootPath) == "" {
+		return "", ErrRootPathRequired
+	}
+
+	backupRoot := filepath.Join(s.rootPath, id)
+	info, err := os.Stat(filepath.Join(backupRoot, metadataFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrBackupNotFound
+		}
+		return "", err
+	}
+
+	if info.IsDir() {
+		return "", ErrBackupNotFound
+	}
+
+	return backupRoot, nil
+}
+
+func (s *Service) validateDependencies() error {
+	if strings.TrimSpace(s.rootPath) == "" {
+		return ErrRootPathRequired
+	}
+
+	if s.ops == nil {
+		return ErrFileOperationsRequired
+	}
+
+	return nil
+}
+
+func containsID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dirExists(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !info.IsDir() {
+		return false, fmt.Errorf("expected directory: %s", path)
+	}
+
+	return true, nil
+}
+
+func readMetadata(backupRoot string) (Backup, error) {
+	content, err := os.ReadFile(filepath.Join(backupRoot, metadataFileName))
+	if err != nil {
+		return Backup{}, err
+	}
+
+	var item Backup
+	if err := json.Unmarshal(content, &item); err != nil {
+		return Backup{}, err
+	}
+
+	item.ID = filepath.Base(backupRoot)
+	return item, nil
+}
+
+func writeMetadata(backupRoot string, item Backup) error {
+	content, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(backupRoot, metadataFileName), content, 0o644)
+}
+
+func validateBackupID(backupID string) (string, error) {
+	trimmed := strings.TrimSpace(backupID)
+	if trimmed == "" {
+		return "", ErrBackupIDRequired
+	}
+
+	for _, r := range trimmed {
+		if (r < '0' || r > '9') && r != '-' {
+			return "", ErrBackupIDInvalid
+		}
+	}
+
+	return trimmed, nil
+}
@@ -0,0 +1,239 @@
+package backups
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/journal"
+)
+
+func TestKeepMovesBackupTreeIntoStore(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	backupRoot := filepath.Join(root, "SaveGame", ".backup", "switch-1")
+	writeFile(t, filepath.Join(backupRoot, "savegame", "slot.sav"), "old-save")
+	writeFile(t, filepath.Join(backupRoot, "wraps", "wrap.txt"), "old-wrap")
+
+	svc := NewService(filepath.Join(root, "SaveGame", DirName), fsops.NewLocal(), Retention{})
+	backupID, err := svc.Keep(backupRoot, "ProfileAlpha", "ProfileBeta")
+	if err != nil {
+		t.Fatalf("keep backup: %v", err)
+	}
+
+	if _, err := os.Stat(backupRoot); !os.IsNotExist(err) {
+		t.Fatalf("expected original backup tree to be moved, got %v", err)
+	}
+
+	item, err := svc.Get(backupID)
+	if err != nil {
+		t.Fatalf("get backup: %v", err)
+	}
+
+	if item.FromProfile != "ProfileAlpha" || item.ToProfile != "ProfileBeta" || !item.HasSavegame || !item.HasWraps {
+		t.Fatalf("unexpected backup metadata: %+v", item)
+	}
+}
+
+func TestRetentionPrunesByCountAndAge(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	source := filepath.Join(root, "source")
+	writeFile(t, filepath.Join(source, "savegame", "slot.sav"), "save")
+
+	svc := NewService(filepath.Join(root, DirName), fsops.NewLocal(), Retention{MaxCount: 2, MaxAge: 48 * time.Hour})
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	times := []time.Time{base, base.Add(72 * time.Hour), base.Add(73 * time.Hour), base.Add(74 * time.Hour)}
+	ids := make([]string, 0, len(times))
+	for _, at := range times {
+		current := at
+		svc.now = func() time.Time { return current }
+
+		item, err := svc.Capture(source, "ProfileAlpha", ReasonSwitch)
+		if err != nil {
+			t.Fatalf("capture backup: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+
+	items, err := svc.List()
+	if err != nil {
+		t.Fatalf("list backups: %v", err)
+	}
+
+	if len(items) != 2 || items[0].ID != ids[3] || items[1].ID != ids[2] {
+		t.Fatalf("expected two newest backups, got %+v", items)
+	}
+
+	svc.now = func() time.Time { return base.Add(200 * time.Hour) }
+	if _, err := svc.Capture(source, "ProfileAlpha", ReasonSwitch); err != nil {
+		t.Fatalf("capture late backup: %v", err)
+	}
+
+	items, err = svc.List()
+	if err != nil {
+		t.Fatalf("list backups after age prune: %v", err)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("expected aged backups to be pruned, got %+v", items)
+	}
+}
+
+func TestRestoreCapturesCurrentRootFirst(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "old-save")
+	writeFile(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "old-wrap")
+
+	svc := NewService(filepath.Join(saveGamePath, DirName), fsops.NewLocal(), Retention{MaxCount: 1})
+	original, err := svc.Capture(saveGamePath, "ProfileAlpha", ReasonSwitch)
+	if err != nil {
+		t.Fatalf("capture backup: %v", err)
+	}
+
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "new-save")
+
+	restored, err := svc.Restore(context.Background(), original.ID, saveGamePath, "ProfileBeta")
+	if err != nil {
+		t.Fatalf("restore backup: %v", err)
+	}
+
+	if restored.FromProfile != "ProfileAlpha" {
+		t.Fatalf("unexpected restored backup: %+v", restored)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "old-save")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "old-wrap")
+
+	items, err := svc.List()
+	if err != nil {
+		t.Fatalf("list backups: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected restored backup and safety backup to be kept, got %+v", items)
+	}
+}
+
+func TestRestoreEmptiesFoldersTheBackupDoesNotHold(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "old-save")
+
+	svc := NewService(filepath.Join(saveGamePath, DirName), fsops.NewLocal(), Retention{})
+	original, err := svc.Capture(saveGamePath, "ProfileAlpha", ReasonSwitch)
+	if err != nil {
+		t.Fatalf("capture backup: %v", err)
+	}
+
+	writeFile(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "new-wrap")
+
+	if _, err := svc.Restore(context.Background(), original.ID, saveGamePath, "ProfileBeta"); err != nil {
+		t.Fatalf("restore backup: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "old-save")
+	entries, err := os.ReadDir(filepath.Join(saveGamePath, "wraps"))
+	if err != nil {
+		t.Fatalf("read wraps: %v", err)
+	}
+
+	if len(entries) != 0 {
+		t.Fatalf("expected wraps the backup did not hold to be emptied, got %v", entries)
+	}
+}
+
+func TestRestoreRollsBackBothFoldersWhenItFails(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "old-save")
+	writeFile(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "old-wrap")
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	svc := NewService(filepath.Join(saveGamePath, DirName), fsops.NewLocal(), Retention{})
+	svc.SetJournal(j)
+	svc.SetMarker(failingMarker{})
+
+	original, err := svc.Capture(saveGamePath, "ProfileAlpha", ReasonSwitch)
+	if err != nil {
+		t.Fatalf("capture backup: %v", err)
+	}
+
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "new-save")
+	writeFile(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "new-wrap")
+
+	if _, err := svc.Restore(context.Background(), original.ID, saveGamePath, "ProfileBeta"); !errors.Is(err, errMarkerWrite) {
+		t.Fatalf("expected marker failure, got %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "new-save")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "new-wrap")
+	if _, err := os.Stat(filepath.Join(saveGamePath, ".backup")); !os.IsNotExist(err) {
+		t.Fatalf("expected restore work folder to be removed, got %v", err)
+	}
+
+	pending, err := j.Pending()
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatalf("expected empty journal, got %+v", pending)
+	}
+}
+
+var errMarkerWrite = errors.New("marker write failed")
+
+type failingMarker struct{}
+
+func (failingMarker) WriteActiveProfile(string) error {
+	return errMarkerWrite
+}
+
+func TestGetRejectsInvalidBackupID(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(t.TempDir(), fsops.NewLocal(), Retention{})
+	if _, err := svc.Get("../escape"); !errors.Is(err, ErrBackupIDInvalid) {
+		t.Fatalf("expected ErrBackupIDInvalid, got %v", err)
+	}
+
+	if _, err := svc.Get("20260301-100000000"); !errors.Is(err, ErrBackupNotFound) {
+		t.Fatalf("expected ErrBackupNotFound, got %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func assertFileContent(t *testing.T, path string, expected string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(data) != expected {
+		t.Fatalf("expected %q, got %q", expected, string(data))
+	}
+}
@@ -0,0 +1,198 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/profiles"
+	"heat-save-manager/internal/snapshot"
+)
+
+// Conflict policies decide what an import does when a profile with the
+// target name already exists.
+const (
+	// ConflictFail refuses the import. It is the default.
+	ConflictFail = "fail"
+	// ConflictOverwrite replaces the profile after snapshotting it.
+	ConflictOverwrite = "overwrite"
+	// ConflictRename imports under the first free name "<name> (2)", "<name> (3)", ...
+	ConflictRename = "rename"
+	// ConflictMergeWraps keeps the profile's savegame and adds the bundle's
+	// wraps to its own, replacing wraps with the same path.
+	ConflictMergeWraps = "merge-wraps"
+)
+
+const (
+	savegameDirName   = "savegame"
+	wrapsDirName      = "wraps"
+	maxRenameAttempts = 1000
+)
+
+var (
+	ErrProfileExists          = errors.New("a profile with this name already exists")
+	ErrUnknownConflictPolicy  = errors.New("unknown import conflict policy")
+	ErrNoFreeProfileName      = errors.New("no free profile name for the import")
+	ErrActiveProfileNotLoaded = errors.New("profile imported, but the root save could not be reloaded from it")
+)
+
+type MarkerReader interface {
+	ReadActiveProfile() (string, error)
+}
+
+// ActiveProfileKeeper keeps the root save in step when an import replaces the
+// active profile: the root's progress is saved into the profile first, so the
+// pre-import snapshot holds it, and the imported profile is loaded into the
+// root afterwards. CheckGameNotRunning is asked before anything changes,
+// because the reload is refused while the game is open.
+type ActiveProfileKeeper interface {
+	CheckGameNotRunning() error
+	SaveCurrentProfileContext(ctx context.Context, profileName string) error
+	ReloadProfileContext(ctx context.Context, profileName string) error
+}
+
+// SetSnapshotService makes imports that replace or merge into an existing
+// profile snapshot it first.
+func (s *Service) SetSnapshotService(snapshots *snapshot.Service) {
+	s.snapshots = snapshots
+}
+
+// SetMarker lets imports tell whether they target the active profile.
+func (s *Service) SetMarker(marker MarkerReader) {
+	s.marker = marker
+}
+
+// SetActiveProfileKeeper enables saving and reloading the root save around
+// imports over the active profile. Without it the root is left as it is.
+func (s *Service) SetActiveProfileKeeper(keeper ActiveProfileKeeper) {
+	s.keeper = keeper
+}
+
+func normalizeConflict(policy string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case "", ConflictFail:
+		return ConflictFail, nil
+	case ConflictOverwrite:
+		return ConflictOverwrite, nil
+	case ConflictRename:
+		return ConflictRename, nil
+	case ConflictMergeWraps:
+		return ConflictMergeWraps, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownConflictPolicy, policy)
+	}
+}
+
+// resolveTarget applies policy to name and returns the profile to write and
+// whether that profile already exists.
+func (s *Service) resolveTarget(name string, policy string) (string, bool, error) {
+	exists, err := profileExists(filepath.Join(s.profilesPath, name))
+	if err != nil || !exists {
+		return name, false, err
+	}
+
+	switch policy {
+	case ConflictFail:
+		return "", false, fmt.Errorf("%w: %s", ErrProfileExists, name)
+	case ConflictRename:
+		for attempt := 2; attempt <= maxRenameAttempts; attempt++ {
+			candidate := fmt.Sprintf("%s (%d)", name, attempt)
+			taken, err := profileExists(filepath.Join(s.profilesPath, candidate))
+			if err != nil {
+				return "", false, err
+			}
+
+			if !taken {
+				return candidate, false, nil
+			}
+		}
+
+		return "", false, fmt.Errorf("%w: %s", ErrNoFreeProfileName, name)
+	default:
+		return name, true, nil
+	}
+}
+
+func profileExists(profileRoot string) (bool, error) {
+	if _, err := os.Lstat(profileRoot); err == nil {
+		return true, nil
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// isActive reports whether name is the profile the marker points at.
+func (s *Service) isActive(name string) (bool, error) {
+	if s.marker == nil {
+		return false, nil
+	}
+
+	active, err := s.marker.ReadActiveProfile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return strings.EqualFold(strings.TrimSpace(active), name), nil
+}
+
+// mergeWraps turns a staged bundle into a copy of profileRoot whose wraps
+// also hold the bundle's wraps: the savegame comes from the profile, and
+// wraps present in both are taken from the bundle.
+func (s *Service) mergeWraps(ctx context.Context, profileRoot string, stagingRoot string) error {
+	stagedSavegame := filepath.Join(stagingRoot, savegameDirName)
+	stagedWraps := filepath.Join(stagingRoot, wrapsDirName)
+	if err := fsops.Plan(ctx, filepath.Join(profileRoot, savegameDirName), filepath.Join(profileRoot, wrapsDirName), stagedWraps); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(stagedSavegame); err != nil {
+		return err
+	}
+
+	if err := s.ops.CopyDir(ctx, filepath.Join(profileRoot, savegameDirName), stagedSavegame); err != nil {
+		return err
+	}
+
+	bundleWraps := stagedWraps + ".bundle"
+	if err := os.Rename(stagedWraps, bundleWraps); err != nil {
+		return err
+	}
+
+	if err := s.ops.CopyDir(ctx, filepath.Join(profileRoot, wrapsDirName), stagedWraps); err != nil {
+		return err
+	}
+
+	if err := s.ops.CopyDir(ctx, bundleWraps, stagedWraps); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(bundleWraps)
+}
+
+// snapshotExisting snapshots the profile an import is about to replace and
+// returns the snapshot ID, or "" when there is nothing usable to keep.
+func (s *Service) snapshotExisting(profileName string, profileRoot string) (string, error) {
+	if s.snapshots == nil {
+		return "", nil
+	}
+
+	if err := profiles.ValidateLayout(profileRoot); err != nil {
+		return "", nil
+	}
+
+	item, err := s.snapshots.Create(profileName, profileRoot, "import")
+	if err != nil {
+		return "", fmt.Errorf("snapshot profile before import: %w", err)
+	}
+
+	return item.ID, nil
+}
@@ -0,0 +1,261 @@
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var (
+	ErrPassphraseRequired = errors.New("bundle is encrypted and needs a passphrase")
+	ErrWrongPassphrase    = errors.New("passphrase is incorrect for this bundle")
+	ErrBundleCorrupted    = errors.New("encrypted bundle is corrupted or was modified")
+)
+
+// Encrypted bundles wrap the regular zip in chunked AES-256-GCM. The key is
+// derived from the passphrase with PBKDF2-SHA256; a short verifier derived
+// alongside it lets import tell a wrong passphrase apart from damaged data.
+//
+// Layout: header, then chunks of up to encryptedChunkSize plaintext bytes,
+// each sealed with a counter nonce. The header and a final-chunk flag are
+// bound into every chunk as additional data so truncation and header edits
+// are detected.
+const (
+	encryptedMagic         = "HSMCRYPT"
+	encryptedFormatVersion = 1
+	encryptedSaltSize      = 16
+	encryptedVerifierSize  = 16
+	encryptedHeaderSize    = len(encryptedMagic) + 1 + 4 + 4 + encryptedSaltSize + encryptedVerifierSize
+	encryptedChunkSize     = 1 << 20
+	encryptedKeySize       = 32
+	defaultKDFIterations   = 600_000
+	maxKDFIterations       = 10_000_000
+)
+
+type encryptionHeader struct {
+	iterations uint32
+	chunkSize  uint32
+	salt       [encryptedSaltSize]byte
+	verifier   [encryptedVerifierSize]byte
+}
+
+// IsEncrypted reports whether the file at bundlePath is an encrypted bundle.
+func IsEncrypted(bundlePath string) (bool, error) {
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(encryptedMagic))
+	if _, err := io.ReadFull(file, magic); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return string(magic) == encryptedMagic, nil
+}
+
+func encryptBundle(dst io.Writer, src io.Reader, passphrase string, iterations int) error {
+	header := encryptionHeader{
+		iterations: uint32(iterations),
+		chunkSize:  encryptedChunkSize,
+	}
+	if _, err := rand.Read(header.salt[:]); err != nil {
+		return err
+	}
+
+	aead, verifier, err := deriveBundleCipher(passphrase, header.salt[:], iterations)
+	if err != nil {
+		return err
+	}
+	copy(header.verifier[:], verifier)
+
+	headerBytes := header.marshal()
+	if _, err := dst.Write(headerBytes); err != nil {
+		return err
+	}
+
+	current := make([]byte, header.chunkSize)
+	next := make([]byte, header.chunkSize)
+	currentLen, err := readChunk(src, current)
+	if err != nil {
+		return err
+	}
+
+	for counter := uint64(0); ; counter++ {
+		nextLen, err := readChunk(src, next)
+		if err != nil {
+			return err
+		}
+
+		final := nextLen == 0
+		sealed := aead.Seal(nil, chunkNonce(counter), current[:currentLen], chunkAdditionalData(headerBytes, final))
+		if _, err := dst.Write(sealed); err != nil {
+			return err
+		}
+
+		if final {
+			return nil
+		}
+
+		current, next = next, current
+		currentLen = nextLen
+	}
+}
+
+func decryptBundle(dst io.Writer, src io.Reader, passphrase string) error {
+	reader := bufio.NewReader(src)
+	headerBytes := make([]byte, encryptedHeaderSize)
+	if _, err := io.ReadFull(reader, headerBytes); err != nil {
+		return ErrBundleCorrupted
+	}
+
+	header, err := unmarshalEncryptionHeader(headerBytes)
+	if err != nil {
+		return err
+	}
+
+	if passphrase == "" {
+		return ErrPassphraseRequired
+	}
+
+	aead, verifier, err := deriveBundleCipher(passphrase, header.salt[:], int(header.iterations))
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare(verifier, header.verifier[:]) != 1 {
+		return ErrWrongPassphrase
+	}
+
+	sealed := make([]byte, int(header.chunkSize)+aead.Overhead())
+	for counter := uint64(0); ; counter++ {
+		n, err := io.ReadFull(reader, sealed)
+		final := false
+		switch {
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			final = true
+		case errors.Is(err, io.EOF):
+			return ErrBundleCorrupted
+		case err != nil:
+			return err
+		default:
+			if _, peekErr := reader.Peek(1); errors.Is(peekErr, io.EOF) {
+				final = true
+			}
+		}
+
+		plain, err := aead.Open(nil, chunkNonce(counter), sealed[:n], chunkAdditionalData(headerBytes, final))
+		if err != nil {
+			return ErrBundleCorrupted
+		}
+
+		if _, err := dst.Write(plain); err != nil {
+			return err
+		}
+
+		if final {
+			return nil
+		}
+	}
+}
+
+func deriveBundleCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, []byte, error) {
+	derived, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, encryptedKeySize*2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, err := aes.NewCipher(derived[:encryptedKeySize])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verifier := sha256.Sum256(derived[encryptedKeySize:])
+	return aead, verifier[:encryptedVerifierSize], nil
+}
+
+func (h encryptionHeader) marshal() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(encryptedMagic)
+	buf.WriteByte(encryptedFormatVersion)
+	_ = binary.Write(&buf, binary.BigEndian, h.iterations)
+	_ = binary.Write(&buf, binary.BigEndian, h.chunkSize)
+	buf.Write(h.salt[:])
+	buf.Write(h.verifier[:])
+	return buf.Bytes()
+}
+
+func unmarshalEncryptionHeader(data []byte) (encryptionHeader, error) {
+	var header encryptionHeader
+	if len(data) != encryptedHeaderSize || string(data[:len(encryptedMagic)]) != encryptedMagic {
+		return header, ErrBundleCorrupted
+	}
+
+	offset := len(encryptedMagic)
+	if version := data[offset]; version > encryptedFormatVersion {
+		return header, fmt.Errorf("%w (encryption format %d)", ErrUnsupportedFormatVersion, version)
+	}
+	offset++
+
+	header.iterations = binary.BigEndian.Uint32(data[offset:])
+	offset += 4
+	header.chunkSize = binary.BigEndian.Uint32(data[offset:])
+	offset += 4
+	copy(header.salt[:], data[offset:])
+	offset += encryptedSaltSize
+	copy(header.verifier[:], data[offset:])
+
+	if header.iterations == 0 || header.iterations > maxKDFIterations {
+		return header, ErrBundleCorrupted
+	}
+
+	if header.chunkSize == 0 || header.chunkSize > encryptedChunkSize*16 {
+		return header, ErrBundleCorrupted
+	}
+
+	return header, nil
+}
+
+func readChunk(src io.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(src, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, nil
+	}
+
+	return n, err
+}
+
+func chunkNonce(counter uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], counter)
+	return nonce
+}
+
+func chunkAdditionalData(header []byte, final bool) []byte {
+	data := make([]byte, len(header)+1)
+	copy(data, header)
+	if final {
+		data[len(header)] = 1
+	}
+
+	return data
+}
@@ -0,0 +1,206 @@
+package bundle
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Safety limits reported by Inspect.
+const (
+	LimitEntries    = "entries"
+	LimitFileBytes  = "file-bytes"
+	LimitTotalBytes = "total-bytes"
+)
+
+// Inspection previews a bundle without importing it. CanImport is false when
+// the bundle would be rejected: it breaks a safety limit, its layout is not a
+// profile, its manifest or signature is broken, or it is encrypted and no
+// passphrase was given (PassphraseRequired).
+type Inspection struct {
+	BundlePath         string           `json:"bundlePath"`
+	Encrypted          bool             `json:"encrypted"`
+	PassphraseRequired bool             `json:"passphraseRequired"`
+	Manifest           *Manifest        `json:"manifest,omitempty"`
+	Signature          SignatureReport  `json:"signature"`
+	Entries            []InspectedEntry `json:"entries"`
+	FileCount          int              `json:"fileCount"`
+	DirectoryCount     int              `json:"directoryCount"`
+	TotalBytes         int64            `json:"totalBytes"`
+	LayoutValid        bool             `json:"layoutValid"`
+	Layout             Layout           `json:"layout"`
+	Violations         []Violation      `json:"violations"`
+	Problems           []string         `json:"problems"`
+	CanImport          bool             `json:"canImport"`
+}
+
+// InspectedEntry is one file or folder of the bundle, as its zip header
+// describes it.
+type InspectedEntry struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Directory bool   `json:"directory"`
+}
+
+// Violation is a safety limit the bundle exceeds. Path names the offending
+// file for LimitFileBytes.
+type Violation struct {
+	Limit   string `json:"limit"`
+	Maximum int64  `json:"maximum"`
+	Actual  int64  `json:"actual"`
+	Path    string `json:"path,omitempty"`
+}
+
+// Inspect reads a bundle's headers, manifest and signature and reports what
+// an import would do with it, without changing any profile. Encrypted
+// bundles are decrypted into a temporary file next to the profiles when a
+// passphrase is given; without one the result only says so.
+func (s *Service) Inspect(bundlePath string, options ImportOptions) (Inspection, error) {
+	if strings.TrimSpace(bundlePath) == "" {
+		return Inspection{}, ErrBundlePathRequired
+	}
+
+	inspection := Inspection{BundlePath: bundlePath, Entries: []InspectedEntry{}, Violations: []Violation{}, Problems: []string{}}
+
+	encrypted, err := IsEncrypted(bundlePath)
+	if err != nil {
+		return Inspection{}, err
+	}
+	inspection.Encrypted = encrypted
+
+	if encrypted && options.Passphrase == "" {
+		inspection.PassphraseRequired = true
+		return inspection, nil
+	}
+
+	if encrypted {
+		if strings.TrimSpace(s.profilesPath) == "" {
+			return Inspection{}, ErrProfilesPathRequired
+		}
+
+		if err := os.MkdirAll(s.profilesPath, 0o755); err != nil {
+			return Inspection{}, err
+		}
+	}
+
+	archivePath, cleanup, err := s.openableArchive(bundlePath, options.Passphrase)
+	if err != nil {
+		return Inspection{}, err
+	}
+	defer cleanup()
+
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return Inspection{}, err
+	}
+	defer reader.Close()
+
+	s.inspectEntries(&inspection, reader.File)
+
+	manifest, manifestContent, err := readManifest(reader.File)
+	if err != nil {
+		inspection.Problems = append(inspection.Problems, err.Error())
+	} else {
+		inspection.Manifest = manifest
+		if problem := manifestMismatch(manifest, inspection.Entries); problem != "" {
+			inspection.Problems = append(inspection.Problems, problem)
+		}
+
+		inspection.Signature, err = verifySignature(reader.File, manifestContent, s.trustedKeys)
+		if err != nil {
+			inspection.Problems = append(inspection.Problems, err.Error())
+		}
+	}
+
+	inspection.CanImport = inspection.LayoutValid && len(inspection.Violations) == 0 && len(inspection.Problems) == 0
+	return inspection, nil
+}
+
+// inspectEntries lists the entries from their zip headers and checks them
+// against the same limits and layout rules an import applies.
+func (s *Service) inspectEntries(inspection *Inspection, files []*zip.File) {
+	inspection.Layout = detectLayout(files)
+	topLevel := map[string]bool{}
+	var largest Violation
+	for _, f := range files {
+		if f.Name == ManifestFileName || f.Name == SignatureFileName {
+			continue
+		}
+
+		// Any relative root will do: only escaping it matters here.
+		if _, err := entryTarget("root", f.Name); err != nil {
+			inspection.Problems = append(inspection.Problems, fmt.Sprintf("%v: %s", err, f.Name))
+			continue
+		}
+
+		name := strings.TrimSuffix(path.Clean(f.Name), "/")
+		entry := InspectedEntry{Path: name, Directory: f.FileInfo().IsDir()}
+		if entry.Directory {
+			inspection.DirectoryCount++
+		} else {
+			entry.Size = int64(f.UncompressedSize64)
+			inspection.FileCount++
+			inspection.TotalBytes += entry.Size
+			if entry.Size > s.maxBundleFileBytes && entry.Size > largest.Actual {
+				largest = Violation{Limit: LimitFileBytes, Maximum: s.maxBundleFileBytes, Actual: entry.Size, Path: name}
+			}
+		}
+
+		inspection.Entries = append(inspection.Entries, entry)
+		if imported, ok := inspection.Layout.entryName(name, entry.Directory); ok {
+			topLevel[strings.SplitN(imported, "/", 2)[0]] = true
+		}
+	}
+
+	sort.Slice(inspection.Entries, func(i, j int) bool {
+		return inspection.Entries[i].Path < inspection.Entries[j].Path
+	})
+
+	inspection.LayoutValid = topLevel[savegameDirName] && topLevel[wrapsDirName]
+
+	if len(files) > s.maxBundleEntries {
+		inspection.Violations = append(inspection.Violations, Violation{Limit: LimitEntries, Maximum: int64(s.maxBundleEntries), Actual: int64(len(files))})
+	}
+
+	if largest.Limit != "" {
+		inspection.Violations = append(inspection.Violations, largest)
+	}
+
+	if inspection.TotalBytes > s.maxBundleTotalBytes {
+		inspection.Violations = append(inspection.Violations, Violation{Limit: LimitTotalBytes, Maximum: s.maxBundleTotalBytes, Actual: inspection.TotalBytes})
+	}
+}
+
+// manifestMismatch describes the first difference between the files listed
+// in the manifest and those in the archive, or returns "" when they agree.
+// Contents are only checked against the checksums on import.
+func manifestMismatch(manifest *Manifest, entries []InspectedEntry) string {
+	if manifest == nil {
+		return ""
+	}
+
+	listed := map[string]bool{}
+	for _, entry := range manifest.Entries {
+		listed[entry.Path] = true
+	}
+
+	for _, entry := range entries {
+		if entry.Directory {
+			continue
+		}
+
+		if !listed[entry.Path] {
+			return fmt.Sprintf("%v: %s", ErrBundleEntryNotInManifest, entry.Path)
+		}
+		delete(listed, entry.Path)
+	}
+
+	for missing := range listed {
+		return fmt.Sprintf("%v: %s", ErrBundleEntryMissingContent, missing)
+	}
+
+	return ""
+}
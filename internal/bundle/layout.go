@@ -0,0 +1,140 @@
+package bundle
+
+import (
+	"archive/zip"
+	"errors"
+	"strings"
+)
+
+var ErrDuplicateBundleEntry = errors.New("bundle has more than one entry for the same file")
+
+// Layout records how archive entry names were mapped onto the profile layout
+// on import, for bundles made by zipping the profile folder itself or by tools
+// that spell the folders differently. The zero value means the names were
+// used as they are.
+type Layout struct {
+	// WrapperFolder is the single top-level folder every entry sat in and
+	// that was stripped, such as "MyProfile".
+	WrapperFolder string `json:"wrapperFolder,omitempty"`
+	// RenamedFolders lists the top-level folders whose names were changed to
+	// the expected casing, such as "Savegame" to "savegame".
+	RenamedFolders []FolderRename `json:"renamedFolders,omitempty"`
+
+	renames map[string]string
+}
+
+type FolderRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Normalized reports whether any entry name was changed.
+func (l Layout) Normalized() bool {
+	return l.WrapperFolder != "" || len(l.RenamedFolders) > 0
+}
+
+// detectLayout looks at the entry names of files and works out the wrapper
+// folder to strip and the folder names to fix. A wrapper is only stripped
+// when every entry is inside the same top-level folder and that folder is not
+// itself savegame or wraps.
+func detectLayout(files []*zip.File) Layout {
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.Name == ManifestFileName || f.Name == SignatureFileName {
+			continue
+		}
+		names = append(names, f.Name)
+	}
+
+	var layout Layout
+	if wrapper := singleTopFolder(names); wrapper != "" && layoutFolder(wrapper) == "" {
+		layout.WrapperFolder = wrapper
+	}
+
+	for _, name := range names {
+		name, ok := layout.unwrap(name)
+		if !ok {
+			continue
+		}
+
+		first, _, found := strings.Cut(name, "/")
+		canonical := layoutFolder(first)
+		if !found || canonical == "" || canonical == first {
+			continue
+		}
+
+		if _, seen := layout.renames[first]; seen {
+			continue
+		}
+
+		if layout.renames == nil {
+			layout.renames = map[string]string{}
+		}
+		layout.renames[first] = canonical
+		layout.RenamedFolders = append(layout.RenamedFolders, FolderRename{From: first, To: canonical})
+	}
+
+	return layout
+}
+
+// entryName maps an archive path onto the profile layout. ok is false for the
+// wrapper folder itself, which has no place in the profile. dir marks folder
+// paths written without a trailing slash, as the manifest lists them.
+func (l Layout) entryName(name string, dir bool) (string, bool) {
+	name, ok := l.unwrap(name)
+	if !ok {
+		return "", false
+	}
+
+	first, rest, found := strings.Cut(name, "/")
+	canonical, rename := l.renames[first]
+	if !rename || !(found || dir) {
+		return name, true
+	}
+
+	if !found {
+		return canonical, true
+	}
+
+	return canonical + "/" + rest, true
+}
+
+func (l Layout) unwrap(name string) (string, bool) {
+	if l.WrapperFolder == "" {
+		return name, true
+	}
+
+	rest, found := strings.CutPrefix(name, l.WrapperFolder+"/")
+	if !found || rest == "" {
+		return "", false
+	}
+
+	return rest, true
+}
+
+// singleTopFolder returns the top-level folder shared by all names, or ""
+// when there is none or a file sits at the top level.
+func singleTopFolder(names []string) string {
+	top := ""
+	for _, name := range names {
+		first, _, found := strings.Cut(name, "/")
+		if !found || (top != "" && first != top) {
+			return ""
+		}
+		top = first
+	}
+
+	return top
+}
+
+// layoutFolder returns the expected name of a profile folder matching name in
+// any casing, or "" for other names.
+func layoutFolder(name string) string {
+	for _, folder := range []string{savegameDirName, wrapsDirName} {
+		if strings.EqualFold(name, folder) {
+			return folder
+		}
+	}
+
+	return ""
+}
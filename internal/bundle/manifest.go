@@ -0,0 +1,232 @@
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"heat-save-manager/internal/signing"
+)
+
+var (
+	ErrManifestInvalid           = errors.New("bundle manifest is invalid")
+	ErrUnsupportedFormatVersion  = errors.New("bundle was created by a newer version of the app")
+	ErrBundleChecksumMismatch    = errors.New("bundle contents do not match the manifest")
+	ErrBundleEntryNotInManifest  = errors.New("bundle contains a file missing from the manifest")
+	ErrBundleEntryMissingContent = errors.New("bundle is missing a file listed in the manifest")
+)
+
+const (
+	ManifestFileName = "manifest.json"
+
+	// FormatVersion is bumped whenever a bundle written by this version can no
+	// longer be read correctly by older versions.
+	FormatVersion = 1
+
+	maxManifestBytes int64 = 16 << 20
+)
+
+type Manifest struct {
+	FormatVersion int             `json:"formatVersion"`
+	AppVersion    string          `json:"appVersion"`
+	ProfileName   string          `json:"profileName"`
+	ExportedAt    time.Time       `json:"exportedAt"`
+	Description   string          `json:"description,omitempty"`
+	Author        string          `json:"author,omitempty"`
+	Entries       []ManifestEntry `json:"entries"`
+	// Directories lists every folder below the profile root, so empty ones
+	// survive the round trip. Bundles from before it was added leave it out.
+	Directories []ManifestDirectory `json:"directories,omitempty"`
+}
+
+// ManifestEntry describes one file. Mode holds the permission bits and
+// ModTime the modification time at full precision; both are zero in bundles
+// from before they were recorded, which fall back to the zip headers.
+type ManifestEntry struct {
+	Path    string      `json:"path"`
+	Size    int64       `json:"size"`
+	SHA256  string      `json:"sha256"`
+	Mode    os.FileMode `json:"mode,omitempty"`
+	ModTime time.Time   `json:"modTime,omitzero"`
+}
+
+type ManifestDirectory struct {
+	Path    string      `json:"path"`
+	Mode    os.FileMode `json:"mode,omitempty"`
+	ModTime time.Time   `json:"modTime,omitzero"`
+}
+
+type ExportOptions struct {
+	Description string
+	Author      string
+	// Passphrase encrypts the bundle when set.
+	Passphrase string
+	// Signer signs the manifest when set.
+	Signer *signing.Identity
+}
+
+type ImportOptions struct {
+	Passphrase string
+	// Conflict is the policy for a profile that already exists under the
+	// target name: ConflictFail (the default when empty), ConflictOverwrite,
+	// ConflictRename or ConflictMergeWraps.
+	Conflict string
+}
+
+type ImportResult struct {
+	ProfileName string          `json:"profileName"`
+	Manifest    *Manifest       `json:"manifest,omitempty"`
+	Signature   SignatureReport `json:"signature"`
+	// Warning is set when the bundle is unsigned or signed by an untrusted key.
+	Warning bool `json:"warning"`
+	// Conflict is the policy that was applied; ProfileName is the name the
+	// profile ended up under.
+	Conflict string `json:"conflict"`
+	// SnapshotID names the snapshot taken of the profile that was replaced.
+	SnapshotID string `json:"snapshotId,omitempty"`
+	// ActiveReloaded is set when the import replaced the active profile and
+	// the root save was reloaded from it.
+	ActiveReloaded bool `json:"activeReloaded"`
+	// Layout reports any wrapper folder or folder casing that was fixed.
+	Layout Layout `json:"layout"`
+}
+
+func writeManifest(archive *zip.Writer, manifest Manifest) ([]byte, error) {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	header := &zip.FileHeader{
+		Name:     ManifestFileName,
+		Method:   zip.Deflate,
+		Modified: manifest.ExportedAt,
+	}
+
+	writer, err := archive.CreateHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// readManifest returns the manifest stored in the bundle along with its raw
+// bytes, or nil for bundles exported before manifests existed.
+func readManifest(files []*zip.File) (*Manifest, []byte, error) {
+	content, found, err := readSmallEntry(files, ManifestFileName)
+	if err != nil || !found {
+		return nil, nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrManifestInvalid, err)
+	}
+
+	if manifest.FormatVersion < 1 {
+		return nil, nil, ErrManifestInvalid
+	}
+
+	if manifest.FormatVersion > FormatVersion {
+		return nil, nil, fmt.Errorf("%w (format %d, supported up to %d)", ErrUnsupportedFormatVersion, manifest.FormatVersion, FormatVersion)
+	}
+
+	return &manifest, content, nil
+}
+
+func readSmallEntry(files []*zip.File, name string) ([]byte, bool, error) {
+	for _, f := range files {
+		if f.Name != name {
+			continue
+		}
+
+		in, err := f.Open()
+		if err != nil {
+			return nil, true, err
+		}
+
+		content, readErr := io.ReadAll(io.LimitReader(in, maxManifestBytes+1))
+		closeErr := in.Close()
+		if readErr != nil {
+			return nil, true, readErr
+		}
+		if closeErr != nil {
+			return nil, true, closeErr
+		}
+
+		if int64(len(content)) > maxManifestBytes {
+			return nil, true, ErrBundleTooLarge
+		}
+
+		return content, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// checksumIndex maps bundle entry paths to their manifest entries, and so to
+// their expected SHA-256 digests.
+type checksumIndex map[string]ManifestEntry
+
+func newChecksumIndex(manifest *Manifest) (checksumIndex, error) {
+	if manifest == nil {
+		return nil, nil
+	}
+
+	index := make(checksumIndex, len(manifest.Entries))
+	for _, entry := range manifest.Entries {
+		if entry.Path == "" || entry.SHA256 == "" {
+			return nil, ErrManifestInvalid
+		}
+
+		if _, exists := index[entry.Path]; exists {
+			return nil, fmt.Errorf("%w: duplicate entry %s", ErrManifestInvalid, entry.Path)
+		}
+
+		index[entry.Path] = entry
+	}
+
+	return index, nil
+}
+
+func (c checksumIndex) verify(path string, digest string) error {
+	if c == nil {
+		return nil
+	}
+
+	expected, ok := c[path]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrBundleEntryNotInManifest, path)
+	}
+
+	if expected.SHA256 != digest {
+		return fmt.Errorf("%w: %s", ErrBundleChecksumMismatch, path)
+	}
+
+	delete(c, path)
+	return nil
+}
+
+// attributes returns the recorded mode and modification time of path, if the
+// manifest has them.
+func (c checksumIndex) attributes(path string) (os.FileMode, time.Time) {
+	entry := c[path]
+	return entry.Mode, entry.ModTime
+}
+
+func (c checksumIndex) ensureComplete() error {
+	for path := range c {
+		return fmt.Errorf("%w: %s", ErrBundleEntryMissingContent, path)
+	}
+
+	return nil
+}
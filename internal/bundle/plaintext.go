@@ -0,0 +1,76 @@
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"heat-save-manager/internal/savelock"
+)
+
+// plaintextPrefix names the private folders that hold the decrypted archive
+// of an encrypted bundle while it is written or read. The owning process ID
+// follows, so leftovers of a crashed process can be told from folders still
+// in use by another instance.
+const plaintextPrefix = "heat-save-manager-plain-"
+
+// createPlaintext creates an empty file for a decrypted archive in a new
+// private folder under tempDir, or the system temp folder when tempDir is
+// empty. MkdirTemp makes the folder private to the current user, and it never
+// lives under the SaveGame or Profiles folders, which may be synced or
+// shared. cleanup removes the folder with the file.
+func createPlaintext(tempDir string) (*os.File, func(), error) {
+	if strings.TrimSpace(tempDir) == "" {
+		tempDir = os.TempDir()
+	}
+
+	dir, err := os.MkdirTemp(tempDir, fmt.Sprintf("%s%d-*", plaintextPrefix, os.Getpid()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, "bundle.zip"), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	return file, func() { os.RemoveAll(dir) }, nil
+}
+
+// RemovePlaintextLeftovers deletes the decrypted archives that processes no
+// longer running left in the system temp folder, such as after a crash
+// during an encrypted import or export.
+func RemovePlaintextLeftovers() error {
+	return removePlaintextLeftovers(os.TempDir(), savelock.ProcessAlive)
+}
+
+func removePlaintextLeftovers(tempDir string, alive func(pid int) bool) error {
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), plaintextPrefix) {
+			continue
+		}
+
+		owner, _, _ := strings.Cut(strings.TrimPrefix(entry.Name(), plaintextPrefix), "-")
+		pid, err := strconv.Atoi(owner)
+		if err == nil && pid > 0 && alive(pid) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(tempDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,15 +2,25 @@ package bundle
 
 import (
 	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"heat-save-manager/internal/config"
+	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/journal"
 	"heat-save-manager/internal/profiles"
+	"heat-save-manager/internal/savelock"
+	"heat-save-manager/internal/signing"
+	"heat-save-manager/internal/snapshot"
 )
 
 var (
@@ -29,9 +39,21 @@ const (
 
 type Service struct {
 	profilesPath        string
+	appVersion          string
 	maxBundleEntries    int
 	maxBundleFileBytes  int64
 	maxBundleTotalBytes int64
+	kdfIterations       int
+	trustedKeys         []config.TrustedKey
+	ops                 fsops.Operations
+	journal             *journal.Journal
+	lock                *savelock.Locker
+	snapshots           *snapshot.Service
+	marker              MarkerReader
+	keeper              ActiveProfileKeeper
+	// tempDir holds decrypted archives; empty means the system temp folder.
+	tempDir string
+	now     func() time.Time
 }
 
 func NewService(profilesPath string) *Service {
@@ -40,48 +62,162 @@ func NewService(profilesPath string) *Service {
 		maxBundleEntries:    defaultMaxBundleEntries,
 		maxBundleFileBytes:  defaultMaxBundleFileBytes,
 		maxBundleTotalBytes: defaultMaxBundleTotalBytes,
+		kdfIterations:       defaultKDFIterations,
+		ops:                 fsops.NewLocal(),
+		now:                 time.Now,
 	}
 }
 
+// SetAppVersion records the version written into exported manifests.
+func (s *Service) SetAppVersion(version string) {
+	s.appVersion = strings.TrimSpace(version)
+}
+
+// SetTrustedKeys sets the author keys that imports report as trusted.
+func (s *Service) SetTrustedKeys(keys []config.TrustedKey) {
+	s.trustedKeys = keys
+}
+
+// SetJournal records imports so an interrupted one can be finished or undone
+// by recovery on the next start.
+func (s *Service) SetJournal(j *journal.Journal) {
+	s.journal = j
+}
+
+// SetFileOperations replaces the local file operations used to copy profile
+// folders, such as when merging wraps.
+func (s *Service) SetFileOperations(ops fsops.Operations) {
+	if ops != nil {
+		s.ops = ops
+	}
+}
+
+// SetLocker makes imports hold the SaveGame folder lock while they replace a
+// profile.
+func (s *Service) SetLocker(lock *savelock.Locker) {
+	s.lock = lock
+}
+
 func (s *Service) ExportProfile(profileName string, bundlePath string) error {
+	_, err := s.ExportProfileWithOptions(profileName, bundlePath, ExportOptions{})
+	return err
+}
+
+func (s *Service) ExportProfileWithOptions(profileName string, bundlePath string, options ExportOptions) (ExportResult, error) {
+	return s.ExportProfileContext(context.Background(), profileName, bundlePath, options)
+}
+
+// ExportProfileContext is ExportProfileWithOptions with cancellation and
+// progress reporting through ctx. The bundle is written beside bundlePath,
+// re-read and verified, and only then moved into place, so a failed or
+// cancelled export leaves any older bundle at that path untouched.
+func (s *Service) ExportProfileContext(ctx context.Context, profileName string, bundlePath string, options ExportOptions) (ExportResult, error) {
 	name, err := validateProfileName(profileName)
 	if err != nil {
-		return err
+		return ExportResult{}, err
 	}
 
 	if strings.TrimSpace(bundlePath) == "" {
-		return ErrBundlePathRequired
+		return ExportResult{}, ErrBundlePathRequired
 	}
 
 	if strings.TrimSpace(s.profilesPath) == "" {
-		return ErrProfilesPathRequired
+		return ExportResult{}, ErrProfilesPathRequired
 	}
 
 	profileRoot := filepath.Join(s.profilesPath, name)
 	if err := profiles.ValidateLayout(profileRoot); err != nil {
-		return err
+		return ExportResult{}, err
 	}
 
 	if err := os.MkdirAll(filepath.Dir(bundlePath), 0o755); err != nil {
-		return err
+		return ExportResult{}, err
+	}
+
+	if err := fsops.Plan(ctx, profileRoot); err != nil {
+		return ExportResult{}, err
+	}
+
+	manifest := Manifest{
+		FormatVersion: FormatVersion,
+		AppVersion:    s.appVersion,
+		ProfileName:   name,
+		ExportedAt:    s.now().UTC(),
+		Description:   strings.TrimSpace(options.Description),
+		Author:        strings.TrimSpace(options.Author),
+		Entries:       []ManifestEntry{},
 	}
 
-	file, err := os.Create(bundlePath)
+	var result ExportResult
+	if options.Passphrase == "" {
+		size, err := writeBundleFile(ctx, bundlePath, func(file *os.File) error {
+			return writeProfileArchive(ctx, file, profileRoot, manifest, options.Signer)
+		}, func(path string) error {
+			verified, err := verifyArchive(path)
+			result = verified
+			return err
+		})
+		if err != nil {
+			return ExportResult{}, err
+		}
+
+		result.BundlePath = bundlePath
+		result.Size = size
+		return result, nil
+	}
+
+	// The plain archive is staged in a private temp folder rather than beside
+	// the destination or the profiles, either of which may be shared.
+	plain, cleanup, err := createPlaintext(s.tempDir)
 	if err != nil {
-		return err
+		return ExportResult{}, err
 	}
-	defer file.Close()
+	defer cleanup()
+	defer plain.Close()
 
-	archive := zip.NewWriter(file)
-	defer archive.Close()
+	if err := writeProfileArchive(ctx, plain, profileRoot, manifest, options.Signer); err != nil {
+		return ExportResult{}, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return ExportResult{}, err
+	}
+
+	result, err = verifyArchive(plain.Name())
+	if err != nil {
+		return ExportResult{}, fmt.Errorf("%w: %v", ErrExportVerificationFailed, err)
+	}
+
+	plainSHA256, err := fileSHA256(plain)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	size, err := writeBundleFile(ctx, bundlePath, func(file *os.File) error {
+		return encryptBundle(file, plain, options.Passphrase, s.kdfIterations)
+	}, func(path string) error {
+		return verifyEncrypted(path, options.Passphrase, plainSHA256)
+	})
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	result.BundlePath = bundlePath
+	result.Size = size
+	result.Encrypted = true
+	return result, nil
+}
+
+func writeProfileArchive(ctx context.Context, w io.Writer, profileRoot string, manifest Manifest, signer *signing.Identity) error {
+	archive := zip.NewWriter(w)
 
-	return filepath.WalkDir(profileRoot, func(path string, d os.DirEntry, walkErr error) error {
+	err := filepath.WalkDir(profileRoot, func(path string, d os.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
 
-		if d.IsDir() {
-			return nil
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
 		relPath, err := filepath.Rel(profileRoot, path)
@@ -89,6 +225,11 @@ func (s *Service) ExportProfile(profileName string, bundlePath string) error {
 			return err
 		}
 
+		// Notes, tags and usage times belong to this install, not the saves.
+		if relPath == "." || relPath == profiles.MetadataFileName {
+			return nil
+		}
+
 		entryName := filepath.ToSlash(relPath)
 		info, err := d.Info()
 		if err != nil {
@@ -99,6 +240,26 @@ func (s *Service) ExportProfile(profileName string, bundlePath string) error {
 		if err != nil {
 			return err
 		}
+
+		if d.IsDir() {
+			header.Name = entryName + "/"
+			header.Method = zip.Store
+			if _, err := archive.CreateHeader(header); err != nil {
+				return err
+			}
+
+			manifest.Directories = append(manifest.Directories, ManifestDirectory{
+				Path:    entryName,
+				Mode:    info.Mode().Perm(),
+				ModTime: info.ModTime().UTC(),
+			})
+			return nil
+		}
+
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("only regular files can be exported: %s", path)
+		}
+
 		header.Name = entryName
 		header.Method = zip.Deflate
 
@@ -112,81 +273,363 @@ func (s *Service) ExportProfile(profileName string, bundlePath string) error {
 			return err
 		}
 
-		_, copyErr := io.Copy(writer, in)
+		hasher := sha256.New()
+		written, copyErr := fsops.Copy(ctx, io.MultiWriter(writer, hasher), in)
 		closeErr := in.Close()
 		if copyErr != nil {
 			return copyErr
 		}
 
-		return closeErr
+		if closeErr != nil {
+			return closeErr
+		}
+
+		fsops.Advance(ctx, 1, 0)
+		manifest.Entries = append(manifest.Entries, ManifestEntry{
+			Path:    entryName,
+			Size:    written,
+			SHA256:  hex.EncodeToString(hasher.Sum(nil)),
+			Mode:    info.Mode().Perm(),
+			ModTime: info.ModTime().UTC(),
+		})
+		return nil
 	})
+	if err != nil {
+		archive.Close()
+		return err
+	}
+
+	manifestContent, err := writeManifest(archive, manifest)
+	if err != nil {
+		archive.Close()
+		return err
+	}
+
+	if signer != nil {
+		if err := writeSignature(archive, signer, manifestContent); err != nil {
+			archive.Close()
+			return err
+		}
+	}
+
+	return archive.Close()
 }
 
 func (s *Service) ImportProfile(profileName string, bundlePath string) error {
-	name, err := validateProfileName(profileName)
+	_, err := s.ImportProfileWithOptions(profileName, bundlePath, ImportOptions{})
+	return err
+}
+
+func (s *Service) ImportProfileWithOptions(profileName string, bundlePath string, options ImportOptions) (ImportResult, error) {
+	return s.ImportProfileContext(context.Background(), profileName, bundlePath, options)
+}
+
+// ImportProfileContext is ImportProfileWithOptions with cancellation and
+// progress reporting through ctx. Cancelling before the staged profile is
+// swapped in leaves the existing profile untouched.
+//
+// options.Conflict decides what happens when the profile already exists. A
+// profile that is replaced or merged into is snapshotted first. When it is
+// the active profile, the root save is written into it before the snapshot
+// and reloaded from the imported copy afterwards, so neither the player's
+// progress nor the imported one is lost.
+func (s *Service) ImportProfileContext(ctx context.Context, profileName string, bundlePath string, options ImportOptions) (ImportResult, error) {
+	requested, err := validateProfileName(profileName)
 	if err != nil {
-		return err
+		return ImportResult{}, err
+	}
+
+	policy, err := normalizeConflict(options.Conflict)
+	if err != nil {
+		return ImportResult{}, err
 	}
 
 	if strings.TrimSpace(bundlePath) == "" {
-		return ErrBundlePathRequired
+		return ImportResult{}, ErrBundlePathRequired
 	}
 
 	if strings.TrimSpace(s.profilesPath) == "" {
-		return ErrProfilesPathRequired
+		return ImportResult{}, ErrProfilesPathRequired
 	}
 
-	reader, err := zip.OpenReader(bundlePath)
+	if err := os.MkdirAll(s.profilesPath, 0o755); err != nil {
+		return ImportResult{}, err
+	}
+
+	release, err := s.lock.Acquire("import")
 	if err != nil {
-		return err
+		return ImportResult{}, err
+	}
+	defer release()
+
+	name, exists, err := s.resolveTarget(requested, policy)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	active := false
+	if exists {
+		if active, err = s.isActive(name); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	if active && s.keeper != nil {
+		if err := s.keeper.CheckGameNotRunning(); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	archivePath, cleanup, err := s.openableArchive(bundlePath, options.Passphrase)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer cleanup()
+
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return ImportResult{}, err
 	}
 	defer reader.Close()
 
-	if err := os.MkdirAll(s.profilesPath, 0o755); err != nil {
-		return err
+	manifest, manifestContent, err := readManifest(reader.File)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	signature, err := verifySignature(reader.File, manifestContent, s.trustedKeys)
+	if err != nil {
+		return ImportResult{}, err
 	}
 
+	checksums, err := newChecksumIndex(manifest)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	planArchive(ctx, reader.File)
+
 	profileRoot := filepath.Join(s.profilesPath, name)
 	stagingRoot, err := os.MkdirTemp(s.profilesPath, name+".import-*")
 	if err != nil {
-		return err
+		return ImportResult{}, err
 	}
 	defer os.RemoveAll(stagingRoot)
 
-	if err := s.extractBundleToProfileRoot(reader.File, stagingRoot); err != nil {
-		return err
+	record, err := s.journal.Begin(journal.Entry{
+		Operation:   journal.OperationImport,
+		Phase:       journal.PhaseStaging,
+		Profile:     name,
+		StagingRoot: stagingRoot,
+		TargetRoot:  profileRoot,
+	})
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("record import: %w", err)
+	}
+
+	var directories []ManifestDirectory
+	if manifest != nil {
+		directories = manifest.Directories
+	}
+
+	layout := detectLayout(reader.File)
+	if err := s.extractBundleToProfileRoot(ctx, reader.File, stagingRoot, checksums, directories, layout); err != nil {
+		_ = record.Finish()
+		return ImportResult{}, err
 	}
 
 	if err := profiles.ValidateLayout(stagingRoot); err != nil {
+		_ = record.Finish()
+		return ImportResult{}, err
+	}
+
+	// The root save is only written into the active profile once the bundle
+	// is known to be good, so a bad bundle leaves the stored copy alone. The
+	// merge and metadata below then build on the freshly saved copy.
+	if active && s.keeper != nil {
+		if err := s.keeper.SaveCurrentProfileContext(ctx, name); err != nil {
+			_ = record.Finish()
+			return ImportResult{}, fmt.Errorf("save active profile before import: %w", err)
+		}
+	}
+
+	if exists && policy == ConflictMergeWraps {
+		if err := s.mergeWraps(ctx, profileRoot, stagingRoot); err != nil {
+			_ = record.Finish()
+			return ImportResult{}, fmt.Errorf("merge wraps: %w", err)
+		}
+	}
+
+	if err := s.stageMetadata(profileRoot, stagingRoot); err != nil {
+		_ = record.Finish()
+		return ImportResult{}, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		_ = record.Finish()
+		return ImportResult{}, err
+	}
+
+	result := ImportResult{
+		ProfileName: name,
+		Manifest:    manifest,
+		Signature:   signature,
+		Warning:     signature.Warning(),
+		Conflict:    policy,
+		Layout:      layout,
+	}
+
+	if exists {
+		if result.SnapshotID, err = s.snapshotExisting(name, profileRoot); err != nil {
+			_ = record.Finish()
+			return ImportResult{}, err
+		}
+	}
+
+	if err := record.Advance(journal.PhaseStaged, nil); err != nil {
+		_ = record.Finish()
+		return ImportResult{}, fmt.Errorf("record import: %w", err)
+	}
+
+	if err := journal.Replace(record, profileRoot, stagingRoot); err != nil {
+		return ImportResult{}, err
+	}
+
+	if active && s.keeper != nil {
+		if err := s.keeper.ReloadProfileContext(ctx, name); err != nil {
+			return result, fmt.Errorf("%w: %v", ErrActiveProfileNotLoaded, err)
+		}
+		result.ActiveReloaded = true
+	}
+
+	return result, nil
+}
+
+// stageMetadata keeps the metadata of the profile being overwritten, or
+// starts it for a new one, and records the import as a save.
+func (s *Service) stageMetadata(profileRoot string, stagingRoot string) error {
+	if err := profiles.CopyMetadata(profileRoot, stagingRoot); err != nil {
 		return err
 	}
 
-	return replaceProfileRootAtomic(profileRoot, stagingRoot)
+	now := s.now().UTC()
+	return profiles.UpdateMetadata(stagingRoot, func(metadata *profiles.Metadata) {
+		if metadata.CreatedAt.IsZero() {
+			metadata.CreatedAt = now
+		}
+		metadata.LastSavedAt = now
+	})
+}
+
+// openableArchive returns a path to the plain zip for bundlePath, decrypting
+// encrypted bundles into a private temp folder.
+func (s *Service) openableArchive(bundlePath string, passphrase string) (string, func(), error) {
+	encrypted, err := IsEncrypted(bundlePath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !encrypted {
+		return bundlePath, func() {}, nil
+	}
+
+	if passphrase == "" {
+		return "", nil, ErrPassphraseRequired
+	}
+
+	in, err := os.Open(bundlePath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer in.Close()
+
+	plain, cleanup, err := createPlaintext(s.tempDir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	decryptErr := decryptBundle(plain, in, passphrase)
+	closeErr := plain.Close()
+	if decryptErr != nil {
+		cleanup()
+		return "", nil, decryptErr
+	}
+
+	if closeErr != nil {
+		cleanup()
+		return "", nil, closeErr
+	}
+
+	return plain.Name(), cleanup, nil
+}
+
+// planArchive adds the regular files of an archive to the progress totals.
+// The sizes come from the zip headers, which extraction checks as it goes.
+func planArchive(ctx context.Context, files []*zip.File) {
+	count := 0
+	var size int64
+	for _, f := range files {
+		if f.Name == ManifestFileName || f.Name == SignatureFileName || f.FileInfo().IsDir() {
+			continue
+		}
+
+		count++
+		size += int64(f.UncompressedSize64)
+	}
+
+	fsops.PlanTotals(ctx, count, size)
 }
 
-func (s *Service) extractBundleToProfileRoot(files []*zip.File, profileRoot string) error {
-	if len(files) > s.maxBundleEntries {
+// extractBundleToProfileRoot writes the bundle's files below profileRoot and
+// then gives every file and folder its recorded mode and modification time.
+// Folders are finished last, deepest first, since writing into a folder
+// changes its time and a read-only folder could not be written into. Entry
+// names are mapped through layout; checksums stay keyed by the names in the
+// archive.
+func (s *Service) extractBundleToProfileRoot(ctx context.Context, files []*zip.File, profileRoot string, checksums checksumIndex, directories []ManifestDirectory, layout Layout) error {
+	if len(files) > s.maxBundleEntries || len(directories) > s.maxBundleEntries {
 		return ErrBundleTooLarge
 	}
 
-	root := filepath.Clean(profileRoot)
-	rootPrefix := root + string(os.PathSeparator)
 	var totalUncompressedBytes int64
+	folders := map[string]ManifestDirectory{}
+	written := map[string]bool{}
 
 	for _, f := range files {
-		targetPath := filepath.Join(profileRoot, filepath.FromSlash(f.Name))
-		cleanTargetPath := filepath.Clean(targetPath)
-		if cleanTargetPath != root && !strings.HasPrefix(cleanTargetPath, rootPrefix) {
-			return errors.New("bundle contains invalid file path")
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if f.Name == ManifestFileName || f.Name == SignatureFileName {
+			continue
+		}
+
+		name, ok := layout.entryName(f.Name, false)
+		if !ok {
+			continue
+		}
+
+		cleanTargetPath, err := entryTarget(profileRoot, name)
+		if err != nil {
+			return err
 		}
 
 		if f.FileInfo().IsDir() {
 			if err := os.MkdirAll(cleanTargetPath, 0o755); err != nil {
 				return err
 			}
+
+			folders[cleanTargetPath] = ManifestDirectory{Mode: f.Mode().Perm(), ModTime: f.Modified}
 			continue
 		}
 
+		// Two entries can land on one file once folder names are fixed, and
+		// the later one would silently win.
+		if written[cleanTargetPath] {
+			return fmt.Errorf("%w: %s", ErrDuplicateBundleEntry, name)
+		}
+		written[cleanTargetPath] = true
+
 		if err := os.MkdirAll(filepath.Dir(cleanTargetPath), 0o755); err != nil {
 			return err
 		}
@@ -203,20 +646,21 @@ func (s *Service) extractBundleToProfileRoot(files []*zip.File, profileRoot stri
 		}
 
 		limited := &io.LimitedReader{R: in, N: s.maxBundleFileBytes + 1}
-		written, copyErr := io.Copy(out, limited)
+		hasher := sha256.New()
+		size, copyErr := fsops.Copy(ctx, io.MultiWriter(out, hasher), limited)
 		if copyErr != nil {
 			in.Close()
 			out.Close()
 			return copyErr
 		}
 
-		if written > s.maxBundleFileBytes {
+		if size > s.maxBundleFileBytes {
 			in.Close()
 			out.Close()
 			return ErrBundleTooLarge
 		}
 
-		totalUncompressedBytes += written
+		totalUncompressedBytes += size
 		if totalUncompressedBytes > s.maxBundleTotalBytes {
 			in.Close()
 			out.Close()
@@ -231,42 +675,86 @@ func (s *Service) extractBundleToProfileRoot(files []*zip.File, profileRoot stri
 		if err := out.Close(); err != nil {
 			return err
 		}
-	}
 
-	return nil
-}
+		mode, modTime := checksums.attributes(f.Name)
+		if err := checksums.verify(f.Name, hex.EncodeToString(hasher.Sum(nil))); err != nil {
+			return err
+		}
 
-func replaceProfileRootAtomic(profileRoot string, stagingRoot string) error {
-	backupRoot := profileRoot + ".backup-" + time.Now().UTC().Format("20060102-150405.000000000")
-	hadExisting := false
+		if mode == 0 {
+			mode = f.Mode().Perm()
+		}
+		if modTime.IsZero() {
+			modTime = f.Modified
+		}
 
-	if info, err := os.Stat(profileRoot); err == nil {
-		if !info.IsDir() {
-			return fmt.Errorf("profile path exists but is not a directory: %s", profileRoot)
+		if err := applyAttributes(cleanTargetPath, mode, modTime); err != nil {
+			return err
 		}
-		hadExisting = true
-		if err := os.RemoveAll(backupRoot); err != nil {
+
+		fsops.Advance(ctx, 1, 0)
+	}
+
+	if err := checksums.ensureComplete(); err != nil {
+		return err
+	}
+
+	for _, directory := range directories {
+		name, ok := layout.entryName(directory.Path, true)
+		if !ok {
+			continue
+		}
+
+		cleanTargetPath, err := entryTarget(profileRoot, name)
+		if err != nil {
 			return err
 		}
-		if err := os.Rename(profileRoot, backupRoot); err != nil {
+
+		if err := os.MkdirAll(cleanTargetPath, 0o755); err != nil {
 			return err
 		}
-	} else if !os.IsNotExist(err) {
-		return err
+
+		folders[cleanTargetPath] = directory
 	}
 
-	if err := os.Rename(stagingRoot, profileRoot); err != nil {
-		if hadExisting {
-			if rollbackErr := os.Rename(backupRoot, profileRoot); rollbackErr != nil {
-				return fmt.Errorf("replace profile failed: %w; rollback failed: %v", err, rollbackErr)
-			}
+	paths := make([]string, 0, len(folders))
+	for path := range folders {
+		paths = append(paths, path)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+
+	for _, path := range paths {
+		if err := applyAttributes(path, folders[path].Mode, folders[path].ModTime); err != nil {
+			return err
 		}
+	}
 
-		return err
+	return nil
+}
+
+// entryTarget resolves a slash-separated bundle path inside root, refusing
+// paths that would escape it.
+func entryTarget(root string, name string) (string, error) {
+	cleanRoot := filepath.Clean(root)
+	target := filepath.Clean(filepath.Join(cleanRoot, filepath.FromSlash(name)))
+	if target != cleanRoot && !strings.HasPrefix(target, cleanRoot+string(os.PathSeparator)) {
+		return "", errors.New("bundle contains invalid file path")
+	}
+
+	return target, nil
+}
+
+// applyAttributes sets the permission bits and modification time of path,
+// skipping whichever one is unknown.
+func applyAttributes(path string, mode os.FileMode, modTime time.Time) error {
+	if mode != 0 {
+		if err := os.Chmod(path, mode.Perm()); err != nil {
+			return err
+		}
 	}
 
-	if hadExisting {
-		if err := os.RemoveAll(backupRoot); err != nil {
+	if !modTime.IsZero() {
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
 			return err
 		}
 	}
@@ -288,5 +776,9 @@ func validateProfileName(profileName string) (string, error) {
 		return "", ErrInvalidProfileName
 	}
 
+	if profiles.IsArtifactName(trimmed) {
+		return "", profiles.ErrReservedProfileName
+	}
+
 	return trimmed, nil
 }
@@ -2,12 +2,20 @@ package bundle
 
 import (
 	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"heat-save-manager/internal/fsops"
 	"heat-save-manager/internal/profiles"
+	"heat-save-manager/internal/signing"
+	"heat-save-manager/internal/snapshot"
 )
 
 func TestExportAndImportProfileRoundTrip(t *testing.T) {
@@ -38,6 +46,102 @@ func TestExportAndImportProfileRoundTrip(t *testing.T) {
 
 	assertFileContent(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
 	assertFileContent(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	if _, err := os.Stat(filepath.Join(profileRoot, ManifestFileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected manifest to stay out of the imported profile, got %v", err)
+	}
+}
+
+func TestExportImportRoundTripReproducesTreeExactly(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	sourceRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(sourceRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(sourceRoot, "savegame", "nested", "private.sav"), "private")
+	for _, dir := range []string{"wraps", filepath.Join("savegame", "empty")} {
+		if err := os.MkdirAll(filepath.Join(sourceRoot, dir), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+
+	if err := os.Chmod(filepath.Join(sourceRoot, "savegame", "nested", "private.sav"), 0o600); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if err := os.Chmod(filepath.Join(sourceRoot, "savegame", "empty"), 0o700); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+
+	stamp := time.Date(2025, 7, 4, 12, 30, 15, 123456789, time.UTC)
+	for _, rel := range []string{"savegame/slot.sav", "savegame/nested/private.sav", "savegame/nested", "savegame/empty", "savegame", "wraps"} {
+		if err := os.Chtimes(filepath.Join(sourceRoot, filepath.FromSlash(rel)), stamp, stamp); err != nil {
+			t.Fatalf("chtimes %s: %v", rel, err)
+		}
+		stamp = stamp.Add(time.Hour)
+	}
+
+	svc := NewService(profilesPath)
+	bundlePath := filepath.Join(root, "alpha.zip")
+	if err := svc.ExportProfile("ProfileAlpha", bundlePath); err != nil {
+		t.Fatalf("export profile: %v", err)
+	}
+
+	if err := svc.ImportProfile("ProfileCopy", bundlePath); err != nil {
+		t.Fatalf("import own export: %v", err)
+	}
+
+	expected := describeTree(t, sourceRoot)
+	actual := describeTree(t, filepath.Join(profilesPath, "ProfileCopy"))
+	if len(actual) != len(expected) {
+		t.Fatalf("expected tree %v, got %v", expected, actual)
+	}
+
+	for rel, want := range expected {
+		if actual[rel] != want {
+			t.Fatalf("%s: expected %s, got %s", rel, want, actual[rel])
+		}
+	}
+}
+
+// describeTree maps every path below root, except the local metadata file,
+// to its type, permissions, modification time and content.
+func describeTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+
+	described := map[string]string{}
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || rel == profiles.MetadataFileName {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		content := ""
+		if !d.IsDir() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			content = string(data)
+		}
+
+		described[filepath.ToSlash(rel)] = fmt.Sprintf("%v %s %q", info.Mode(), info.ModTime().UTC().Format(time.RFC3339Nano), content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk %s: %v", root, err)
+	}
+
+	return described
 }
 
 func TestExportProfileRequiresValidLayout(t *testing.T) {
@@ -93,7 +197,7 @@ func TestImportProfileKeepsExistingDataWhenBundleLayoutIsInvalid(t *testing.T) {
 	createZipWithEntry(t, bundlePath, "savegame/slot.sav", "new-save-only")
 
 	svc := NewService(profilesPath)
-	err := svc.ImportProfile("ProfileBroken", bundlePath)
+	_, err := svc.ImportProfileWithOptions("ProfileBroken", bundlePath, ImportOptions{Conflict: ConflictOverwrite})
 	if !errors.Is(err, profiles.ErrInvalidProfileLayout) {
 		t.Fatalf("expected ErrInvalidProfileLayout, got %v", err)
 	}
@@ -120,7 +224,7 @@ func TestImportProfileRejectsOversizedEntryCountAndKeepsExistingData(t *testing.
 
 	svc := NewService(profilesPath)
 	svc.maxBundleEntries = 1
-	err := svc.ImportProfile("ProfileBomb", bundlePath)
+	_, err := svc.ImportProfileWithOptions("ProfileBomb", bundlePath, ImportOptions{Conflict: ConflictOverwrite})
 	if !errors.Is(err, ErrBundleTooLarge) {
 		t.Fatalf("expected ErrBundleTooLarge, got %v", err)
 	}
@@ -129,6 +233,343 @@ func TestImportProfileRejectsOversizedEntryCountAndKeepsExistingData(t *testing.
 	assertFileContent(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "original-wrap")
 }
 
+func TestExportProfileWritesManifest(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	svc := NewService(profilesPath)
+	svc.SetAppVersion("v1.2.3")
+	svc.now = func() time.Time { return time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC) }
+
+	bundlePath := filepath.Join(root, "alpha.zip")
+	result, err := svc.ExportProfileWithOptions("ProfileAlpha", bundlePath, ExportOptions{Description: "Story done", Author: "Sam"})
+	if err != nil {
+		t.Fatalf("export profile: %v", err)
+	}
+
+	reader, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	defer reader.Close()
+
+	manifest, _, err := readManifest(reader.File)
+	if err != nil || manifest == nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+
+	if manifest.FormatVersion != FormatVersion || manifest.AppVersion != "v1.2.3" || manifest.ProfileName != "ProfileAlpha" {
+		t.Fatalf("unexpected manifest header: %+v", manifest)
+	}
+
+	if manifest.Description != "Story done" || manifest.Author != "Sam" || !manifest.ExportedAt.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected manifest metadata: %+v", manifest)
+	}
+
+	if len(manifest.Entries) != 2 || manifest.Entries[0].Path != "savegame/slot.sav" || len(manifest.Entries[0].SHA256) != 64 {
+		t.Fatalf("unexpected manifest entries: %+v", manifest.Entries)
+	}
+
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		t.Fatalf("stat bundle: %v", err)
+	}
+
+	if result.BundlePath != bundlePath || result.Size != info.Size() || result.Files != 2 || result.Directories != 2 || result.Encrypted || result.Signed {
+		t.Fatalf("unexpected export result: %+v", result)
+	}
+}
+
+func TestImportProfileRejectsChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "tampered.zip")
+
+	createZipWithEntries(t, bundlePath, map[string]string{
+		"savegame/slot.sav": "tampered",
+		"wraps/wrap.txt":    "wrap-data",
+		ManifestFileName: manifestJSON(t, Manifest{
+			FormatVersion: FormatVersion,
+			ProfileName:   "ProfileAlpha",
+			Entries: []ManifestEntry{
+				{Path: "savegame/slot.sav", SHA256: "0000000000000000000000000000000000000000000000000000000000000000"},
+				{Path: "wraps/wrap.txt", SHA256: "8f5cdb0d7b1a8e2f9d0e5f8c7b6a5d4c3b2a1908f7e6d5c4b3a2918f7e6d5c4b"},
+			},
+		}),
+	})
+
+	svc := NewService(profilesPath)
+	err := svc.ImportProfile("ProfileAlpha", bundlePath)
+	if !errors.Is(err, ErrBundleChecksumMismatch) {
+		t.Fatalf("expected ErrBundleChecksumMismatch, got %v", err)
+	}
+
+	if _, statErr := os.Stat(filepath.Join(profilesPath, "ProfileAlpha")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no profile after failed import, got %v", statErr)
+	}
+}
+
+func TestImportProfileRejectsFileMissingFromManifest(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	svc := NewService(profilesPath)
+	bundlePath := filepath.Join(root, "alpha.zip")
+	if err := svc.ExportProfile("ProfileAlpha", bundlePath); err != nil {
+		t.Fatalf("export profile: %v", err)
+	}
+
+	reader, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	manifest, _, err := readManifest(reader.File)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+
+	createZipWithEntries(t, bundlePath, map[string]string{
+		"savegame/slot.sav":  "save-data",
+		"savegame/extra.sav": "smuggled",
+		"wraps/wrap.txt":     "wrap-data",
+		ManifestFileName:     manifestJSON(t, *manifest),
+	})
+
+	err = svc.ImportProfile("ProfileBeta", bundlePath)
+	if !errors.Is(err, ErrBundleEntryNotInManifest) {
+		t.Fatalf("expected ErrBundleEntryNotInManifest, got %v", err)
+	}
+}
+
+func TestImportProfileRejectsNewerFormatVersion(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "future.zip")
+
+	createZipWithEntries(t, bundlePath, map[string]string{
+		"savegame/slot.sav": "save-data",
+		"wraps/wrap.txt":    "wrap-data",
+		ManifestFileName:    manifestJSON(t, Manifest{FormatVersion: FormatVersion + 1}),
+	})
+
+	svc := NewService(profilesPath)
+	err := svc.ImportProfile("ProfileFuture", bundlePath)
+	if !errors.Is(err, ErrUnsupportedFormatVersion) {
+		t.Fatalf("expected ErrUnsupportedFormatVersion, got %v", err)
+	}
+}
+
+func TestImportProfileAcceptsBundleWithoutManifest(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "legacy.zip")
+
+	createZipWithEntries(t, bundlePath, map[string]string{
+		"savegame/slot.sav": "save-data",
+		"wraps/wrap.txt":    "wrap-data",
+	})
+
+	svc := NewService(profilesPath)
+	if err := svc.ImportProfile("ProfileLegacy", bundlePath); err != nil {
+		t.Fatalf("import legacy bundle: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileLegacy", "savegame", "slot.sav"), "save-data")
+}
+
+func TestExportProfileContextLeavesNoBundleWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	svc := NewService(profilesPath)
+	bundlePath := filepath.Join(root, "alpha.zip")
+	_, err := svc.ExportProfileContext(ctx, "ProfileAlpha", bundlePath, ExportOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if _, err := os.Stat(bundlePath); !os.IsNotExist(err) {
+		t.Fatalf("expected no partial bundle, got %v", err)
+	}
+}
+
+func TestFailedExportKeepsOlderBundleAndLeavesNoTempFiles(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	bundleDir := filepath.Join(root, "bundles")
+	bundlePath := filepath.Join(bundleDir, "alpha.zip")
+	writeFile(t, bundlePath, "older bundle")
+
+	svc := NewService(profilesPath)
+	for _, options := range []ExportOptions{{}, {Passphrase: "secret"}} {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := svc.ExportProfileContext(ctx, "ProfileAlpha", bundlePath, options); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+
+		assertFileContent(t, bundlePath, "older bundle")
+	}
+
+	err := writeBundleVerifiedBy(t, bundleDir, func(path string) error {
+		return errors.New("corrupt")
+	})
+	if !errors.Is(err, ErrExportVerificationFailed) {
+		t.Fatalf("expected ErrExportVerificationFailed, got %v", err)
+	}
+
+	assertFileContent(t, bundlePath, "older bundle")
+	entries, err := os.ReadDir(bundleDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected only the older bundle to remain, got %v err=%v", entries, err)
+	}
+}
+
+// writeBundleVerifiedBy writes a bundle over alpha.zip in dir with
+// verify standing in for the real verification.
+func writeBundleVerifiedBy(t *testing.T, dir string, verify func(string) error) error {
+	t.Helper()
+
+	_, err := writeBundleFile(context.Background(), filepath.Join(dir, "alpha.zip"), func(file *os.File) error {
+		_, err := file.WriteString("new bundle")
+		return err
+	}, verify)
+	return err
+}
+
+func TestImportProfileContextKeepsExistingProfileWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	sourceRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	targetRoot := filepath.Join(profilesPath, "ProfileBeta")
+	writeFile(t, filepath.Join(sourceRoot, "savegame", "slot.sav"), "alpha-save")
+	writeFile(t, filepath.Join(sourceRoot, "wraps", "wrap.txt"), "alpha-wrap")
+	writeFile(t, filepath.Join(targetRoot, "savegame", "slot.sav"), "beta-save")
+	writeFile(t, filepath.Join(targetRoot, "wraps", "wrap.txt"), "beta-wrap")
+
+	svc := NewService(profilesPath)
+	bundlePath := filepath.Join(root, "alpha.zip")
+	if err := svc.ExportProfile("ProfileAlpha", bundlePath); err != nil {
+		t.Fatalf("export profile: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := svc.ImportProfileContext(ctx, "ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictOverwrite})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(targetRoot, "savegame", "slot.sav"), "beta-save")
+	assertFileContent(t, filepath.Join(targetRoot, "wraps", "wrap.txt"), "beta-wrap")
+
+	entries, err := os.ReadDir(profilesPath)
+	if err != nil {
+		t.Fatalf("read profiles: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected only the two profiles to remain, got %d entries", len(entries))
+	}
+}
+
+func TestImportKeepsLocalProfileMetadataOutOfBundles(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	sourceRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	targetRoot := filepath.Join(profilesPath, "ProfileBeta")
+	writeFile(t, filepath.Join(sourceRoot, "savegame", "slot.sav"), "alpha-save")
+	writeFile(t, filepath.Join(sourceRoot, "wraps", "wrap.txt"), "alpha-wrap")
+	writeFile(t, filepath.Join(targetRoot, "savegame", "slot.sav"), "beta-save")
+	writeFile(t, filepath.Join(targetRoot, "wraps", "wrap.txt"), "beta-wrap")
+
+	if err := profiles.SaveMetadata(sourceRoot, profiles.Metadata{Description: "exporter notes"}); err != nil {
+		t.Fatalf("save source metadata: %v", err)
+	}
+	if err := profiles.SaveMetadata(targetRoot, profiles.Metadata{Description: "local notes"}); err != nil {
+		t.Fatalf("save target metadata: %v", err)
+	}
+
+	svc := NewService(profilesPath)
+	bundlePath := filepath.Join(root, "alpha.zip")
+	if err := svc.ExportProfile("ProfileAlpha", bundlePath); err != nil {
+		t.Fatalf("export profile: %v", err)
+	}
+
+	reader, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	for _, f := range reader.File {
+		if f.Name == profiles.MetadataFileName {
+			t.Fatal("expected profile metadata to stay out of the bundle")
+		}
+	}
+	reader.Close()
+
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictOverwrite}); err != nil {
+		t.Fatalf("import profile: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(targetRoot, "savegame", "slot.sav"), "alpha-save")
+	metadata, err := profiles.LoadMetadata(targetRoot)
+	if err != nil {
+		t.Fatalf("load target metadata: %v", err)
+	}
+
+	if metadata.Description != "local notes" || metadata.LastSavedAt.IsZero() {
+		t.Fatalf("expected local notes kept and import recorded as a save, got %+v", metadata)
+	}
+}
+
+func manifestJSON(t *testing.T, manifest Manifest) string {
+	t.Helper()
+
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("marshal manifest: %v", err)
+	}
+
+	return string(content)
+}
+
 func createZipWithEntry(t *testing.T, zipPath string, entryName string, content string) {
 	t.Helper()
 
@@ -188,3 +629,700 @@ func assertFileContent(t *testing.T, path string, expected string) {
 		t.Fatalf("expected %q, got %q", expected, string(data))
 	}
 }
+
+func TestEncryptedBundleRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	svc := NewService(profilesPath)
+	svc.kdfIterations = 1000
+	svc.tempDir = filepath.Join(root, "tmp")
+	if err := os.MkdirAll(svc.tempDir, 0o755); err != nil {
+		t.Fatalf("create temp folder: %v", err)
+	}
+
+	bundlePath := filepath.Join(root, "shared", "alpha.zip")
+	if _, err := svc.ExportProfileWithOptions("ProfileAlpha", bundlePath, ExportOptions{Passphrase: "correct horse"}); err != nil {
+		t.Fatalf("export encrypted profile: %v", err)
+	}
+
+	encrypted, err := IsEncrypted(bundlePath)
+	if err != nil || !encrypted {
+		t.Fatalf("expected encrypted bundle, got %v (%v)", encrypted, err)
+	}
+
+	if _, err := zip.OpenReader(bundlePath); err == nil {
+		t.Fatal("expected encrypted bundle to be unreadable as a plain zip")
+	}
+
+	if err := svc.ImportProfile("ProfileBeta", bundlePath); !errors.Is(err, ErrPassphraseRequired) {
+		t.Fatalf("expected ErrPassphraseRequired, got %v", err)
+	}
+
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Passphrase: "wrong"}); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Passphrase: "correct horse"}); err != nil {
+		t.Fatalf("import encrypted profile: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileBeta", "savegame", "slot.sav"), "save-data")
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileBeta", "wraps", "wrap.txt"), "wrap-data")
+
+	entries, err := os.ReadDir(profilesPath)
+	if err != nil {
+		t.Fatalf("read profiles: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected only the two profiles to remain, got %d entries", len(entries))
+	}
+
+	// The decrypted archives lived in the private temp folder and are gone.
+	if entries, err := os.ReadDir(svc.tempDir); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no decrypted archives left, got %v (%v)", entries, err)
+	}
+}
+
+func TestRemovePlaintextLeftoversKeepsFoldersOfRunningProcesses(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	crashed := filepath.Join(tempDir, plaintextPrefix+"41-123")
+	running := filepath.Join(tempDir, plaintextPrefix+"42-456")
+	unrelated := filepath.Join(tempDir, "other-41-789")
+	for _, dir := range []string{crashed, running, unrelated} {
+		writeFile(t, filepath.Join(dir, "bundle.zip"), "plain")
+	}
+
+	alive := func(pid int) bool { return pid == 42 }
+	if err := removePlaintextLeftovers(tempDir, alive); err != nil {
+		t.Fatalf("remove leftovers: %v", err)
+	}
+
+	if _, err := os.Stat(crashed); !os.IsNotExist(err) {
+		t.Fatalf("expected the crashed process's archive to be removed, got %v", err)
+	}
+
+	for _, dir := range []string{running, unrelated} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Fatalf("expected %s to be kept: %v", dir, err)
+		}
+	}
+}
+
+func TestEncryptedBundleDetectsCorruption(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	svc := NewService(profilesPath)
+	svc.kdfIterations = 1000
+	bundlePath := filepath.Join(root, "alpha.zip")
+	if _, err := svc.ExportProfileWithOptions("ProfileAlpha", bundlePath, ExportOptions{Passphrase: "secret"}); err != nil {
+		t.Fatalf("export encrypted profile: %v", err)
+	}
+
+	content, err := os.ReadFile(bundlePath)
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+
+	flipped := append([]byte(nil), content...)
+	flipped[len(flipped)-20] ^= 0xFF
+	if err := os.WriteFile(bundlePath, flipped, 0o644); err != nil {
+		t.Fatalf("write corrupted bundle: %v", err)
+	}
+
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Passphrase: "secret"}); !errors.Is(err, ErrBundleCorrupted) {
+		t.Fatalf("expected ErrBundleCorrupted for flipped byte, got %v", err)
+	}
+
+	if err := os.WriteFile(bundlePath, content[:len(content)-1], 0o644); err != nil {
+		t.Fatalf("write truncated bundle: %v", err)
+	}
+
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Passphrase: "secret"}); !errors.Is(err, ErrBundleCorrupted) {
+		t.Fatalf("expected ErrBundleCorrupted for truncated bundle, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(profilesPath, "ProfileBeta")); !os.IsNotExist(err) {
+		t.Fatalf("expected no profile after failed import, got %v", err)
+	}
+}
+
+func TestEncryptedChunkingHandlesExactChunkBoundaries(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{0, 1, encryptedChunkSize, encryptedChunkSize + 1, 2 * encryptedChunkSize} {
+		plain := make([]byte, size)
+		for i := range plain {
+			plain[i] = byte(i % 251)
+		}
+
+		var sealed bytes.Buffer
+		if err := encryptBundle(&sealed, bytes.NewReader(plain), "pass", 1000); err != nil {
+			t.Fatalf("encrypt %d bytes: %v", size, err)
+		}
+
+		var opened bytes.Buffer
+		if err := decryptBundle(&opened, bytes.NewReader(sealed.Bytes()), "pass"); err != nil {
+			t.Fatalf("decrypt %d bytes: %v", size, err)
+		}
+
+		if !bytes.Equal(opened.Bytes(), plain) {
+			t.Fatalf("round trip mismatch for %d bytes", size)
+		}
+	}
+}
+
+func TestSignedBundleReportsTrustState(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	identity, err := signing.NewKeyStore(filepath.Join(root, "keys")).Generate("Sam")
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	svc := NewService(profilesPath)
+	bundlePath := filepath.Join(root, "signed.zip")
+	if _, err := svc.ExportProfileWithOptions("ProfileAlpha", bundlePath, ExportOptions{Signer: &identity}); err != nil {
+		t.Fatalf("export signed profile: %v", err)
+	}
+
+	result, err := svc.ImportProfileWithOptions("ProfileUntrusted", bundlePath, ImportOptions{})
+	if err != nil {
+		t.Fatalf("import signed profile: %v", err)
+	}
+
+	if result.Signature.Status != SignatureUntrusted || !result.Signature.Valid || result.Signature.Author != "Sam" || !result.Warning {
+		t.Fatalf("expected untrusted valid signature with warning, got %+v", result)
+	}
+
+	keys, err := signing.AddTrustedKey(nil, "Sam", signing.EncodePublicKey(identity.PublicKey), time.Now())
+	if err != nil {
+		t.Fatalf("trust key: %v", err)
+	}
+	svc.SetTrustedKeys(keys)
+
+	result, err = svc.ImportProfileWithOptions("ProfileTrusted", bundlePath, ImportOptions{})
+	if err != nil {
+		t.Fatalf("import trusted profile: %v", err)
+	}
+
+	if result.Signature.Status != SignatureTrusted || result.Signature.TrustedName != "Sam" || result.Warning {
+		t.Fatalf("expected trusted signature, got %+v", result)
+	}
+
+	if _, err := os.Stat(filepath.Join(profilesPath, "ProfileTrusted", SignatureFileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected signature to stay out of the imported profile, got %v", err)
+	}
+}
+
+func TestUnsignedBundleImportsWithWarning(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	svc := NewService(profilesPath)
+	bundlePath := filepath.Join(root, "unsigned.zip")
+	if err := svc.ExportProfile("ProfileAlpha", bundlePath); err != nil {
+		t.Fatalf("export profile: %v", err)
+	}
+
+	result, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{})
+	if err != nil {
+		t.Fatalf("import profile: %v", err)
+	}
+
+	if result.Signature.Status != SignatureUnsigned || result.Signature.Signed || !result.Warning {
+		t.Fatalf("expected unsigned warning, got %+v", result)
+	}
+}
+
+func TestImportRejectsTamperedSignedManifest(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+
+	identity, err := signing.NewKeyStore(filepath.Join(root, "keys")).Generate("Sam")
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	svc := NewService(profilesPath)
+	bundlePath := filepath.Join(root, "signed.zip")
+	if _, err := svc.ExportProfileWithOptions("ProfileAlpha", bundlePath, ExportOptions{Signer: &identity}); err != nil {
+		t.Fatalf("export signed profile: %v", err)
+	}
+
+	reader, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	manifest, _, err := readManifest(reader.File)
+	if err != nil {
+		reader.Close()
+		t.Fatalf("read manifest: %v", err)
+	}
+	signature, _, err := readSmallEntry(reader.File, SignatureFileName)
+	reader.Close()
+	if err != nil {
+		t.Fatalf("read signature: %v", err)
+	}
+
+	manifest.Author = "Someone Else"
+	createZipWithEntries(t, bundlePath, map[string]string{
+		"savegame/slot.sav": "save-data",
+		"wraps/wrap.txt":    "wrap-data",
+		ManifestFileName:    manifestJSON(t, *manifest),
+		SignatureFileName:   string(signature),
+	})
+
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{}); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("expected ErrSignatureInvalid, got %v", err)
+	}
+}
+
+func TestInspectPreviewsBundleWithoutImporting(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	writeFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps", "wrap.txt"), "wrap-data")
+
+	svc := NewService(profilesPath)
+	bundlePath := filepath.Join(root, "alpha.zip")
+	if _, err := svc.ExportProfileWithOptions("ProfileAlpha", bundlePath, ExportOptions{Author: "Sam"}); err != nil {
+		t.Fatalf("export profile: %v", err)
+	}
+
+	inspection, err := svc.Inspect(bundlePath, ImportOptions{})
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+
+	if !inspection.CanImport || !inspection.LayoutValid || inspection.FileCount != 2 || inspection.DirectoryCount != 2 || inspection.TotalBytes != 18 {
+		t.Fatalf("unexpected inspection: %+v", inspection)
+	}
+
+	if inspection.Manifest == nil || inspection.Manifest.Author != "Sam" || inspection.Signature.Status != SignatureUnsigned {
+		t.Fatalf("expected manifest details and unsigned status, got %+v", inspection)
+	}
+
+	if inspection.Entries[0].Path != "savegame" || !inspection.Entries[0].Directory || inspection.Entries[1].Path != "savegame/slot.sav" {
+		t.Fatalf("expected sorted entries, got %+v", inspection.Entries)
+	}
+
+	encryptedPath := filepath.Join(root, "alpha-encrypted.zip")
+	if _, err := svc.ExportProfileWithOptions("ProfileAlpha", encryptedPath, ExportOptions{Passphrase: "secret"}); err != nil {
+		t.Fatalf("export encrypted: %v", err)
+	}
+
+	inspection, err = svc.Inspect(encryptedPath, ImportOptions{})
+	if err != nil || !inspection.PassphraseRequired || inspection.CanImport {
+		t.Fatalf("expected a passphrase to be required, got %+v err=%v", inspection, err)
+	}
+
+	inspection, err = svc.Inspect(encryptedPath, ImportOptions{Passphrase: "secret"})
+	if err != nil || !inspection.CanImport || inspection.FileCount != 2 {
+		t.Fatalf("expected decrypted preview, got %+v err=%v", inspection, err)
+	}
+
+	entries, err := os.ReadDir(profilesPath)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected inspection to leave the profiles untouched, got %v err=%v", entries, err)
+	}
+}
+
+func TestInspectReportsLimitViolationsAndInvalidLayout(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	bundlePath := filepath.Join(root, "bad.zip")
+	createZipWithEntries(t, bundlePath, map[string]string{
+		"savegame/big.sav":   "0123456789",
+		"savegame/small.sav": "01234",
+		"../escape.txt":      "x",
+	})
+
+	svc := NewService(filepath.Join(root, "Profiles"))
+	svc.maxBundleEntries = 2
+	svc.maxBundleFileBytes = 8
+	svc.maxBundleTotalBytes = 12
+
+	inspection, err := svc.Inspect(bundlePath, ImportOptions{})
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+
+	if inspection.CanImport || inspection.LayoutValid || len(inspection.Problems) != 1 {
+		t.Fatalf("expected invalid layout and an escaping path, got %+v", inspection)
+	}
+
+	expected := []Violation{
+		{Limit: LimitEntries, Maximum: 2, Actual: 3},
+		{Limit: LimitFileBytes, Maximum: 8, Actual: 10, Path: "savegame/big.sav"},
+		{Limit: LimitTotalBytes, Maximum: 12, Actual: 15},
+	}
+	if len(inspection.Violations) != len(expected) {
+		t.Fatalf("expected violations %+v, got %+v", expected, inspection.Violations)
+	}
+
+	for i := range expected {
+		if inspection.Violations[i] != expected[i] {
+			t.Fatalf("expected violations %+v, got %+v", expected, inspection.Violations)
+		}
+	}
+}
+
+// writeConflictProfiles stores ProfileAlpha, exports it and stores a
+// different ProfileBeta for the bundle to collide with.
+func writeConflictProfiles(t *testing.T, svc *Service, profilesPath string, bundlePath string) {
+	t.Helper()
+
+	writeFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "alpha-save")
+	writeFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps", "shared.txt"), "alpha-shared")
+	writeFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps", "alpha.txt"), "alpha-only")
+	if err := svc.ExportProfile("ProfileAlpha", bundlePath); err != nil {
+		t.Fatalf("export profile: %v", err)
+	}
+
+	writeFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame", "slot.sav"), "beta-save")
+	writeFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps", "shared.txt"), "beta-shared")
+	writeFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps", "beta.txt"), "beta-only")
+}
+
+func TestImportConflictFailAndRename(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "alpha.zip")
+	svc := NewService(profilesPath)
+	writeConflictProfiles(t, svc, profilesPath, bundlePath)
+
+	if err := svc.ImportProfile("ProfileBeta", bundlePath); !errors.Is(err, ErrProfileExists) {
+		t.Fatalf("expected ErrProfileExists by default, got %v", err)
+	}
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileBeta", "savegame", "slot.sav"), "beta-save")
+
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: "replace"}); !errors.Is(err, ErrUnknownConflictPolicy) {
+		t.Fatalf("expected ErrUnknownConflictPolicy, got %v", err)
+	}
+
+	for _, expected := range []string{"ProfileBeta (2)", "ProfileBeta (3)"} {
+		result, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictRename})
+		if err != nil {
+			t.Fatalf("import with rename: %v", err)
+		}
+
+		if result.ProfileName != expected || result.Conflict != ConflictRename {
+			t.Fatalf("expected import as %q, got %+v", expected, result)
+		}
+		assertFileContent(t, filepath.Join(profilesPath, expected, "savegame", "slot.sav"), "alpha-save")
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileBeta", "savegame", "slot.sav"), "beta-save")
+
+	result, err := svc.ImportProfileWithOptions("ProfileGamma", bundlePath, ImportOptions{Conflict: ConflictRename})
+	if err != nil || result.ProfileName != "ProfileGamma" {
+		t.Fatalf("expected a free name to be used as is, got %+v err=%v", result, err)
+	}
+}
+
+func TestImportOverwriteSnapshotsExistingProfile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "alpha.zip")
+	svc := NewService(profilesPath)
+	snapshots := snapshot.NewService(filepath.Join(root, "Snapshots"), fsops.NewLocal())
+	svc.SetSnapshotService(snapshots)
+	writeConflictProfiles(t, svc, profilesPath, bundlePath)
+
+	result, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictOverwrite})
+	if err != nil {
+		t.Fatalf("import with overwrite: %v", err)
+	}
+
+	if result.SnapshotID == "" || result.ActiveReloaded {
+		t.Fatalf("expected a snapshot and no reload, got %+v", result)
+	}
+
+	betaRoot := filepath.Join(profilesPath, "ProfileBeta")
+	assertFileContent(t, filepath.Join(betaRoot, "savegame", "slot.sav"), "alpha-save")
+	if _, err := os.Stat(filepath.Join(betaRoot, "wraps", "beta.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected overwrite to drop the old wraps, got %v", err)
+	}
+
+	restored := filepath.Join(root, "restored")
+	if err := snapshots.CopyTo("ProfileBeta", result.SnapshotID, restored); err != nil {
+		t.Fatalf("copy snapshot: %v", err)
+	}
+	assertFileContent(t, filepath.Join(restored, "savegame", "slot.sav"), "beta-save")
+	assertFileContent(t, filepath.Join(restored, "wraps", "beta.txt"), "beta-only")
+}
+
+func TestImportMergeWrapsKeepsSavegame(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "alpha.zip")
+	svc := NewService(profilesPath)
+	ops := &countingOps{Local: fsops.NewLocal()}
+	svc.SetFileOperations(ops)
+	writeConflictProfiles(t, svc, profilesPath, bundlePath)
+
+	result, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictMergeWraps})
+	if err != nil {
+		t.Fatalf("import with merge: %v", err)
+	}
+
+	if ops.copies == 0 {
+		t.Fatal("expected the merge to copy through the injected file operations")
+	}
+
+	if result.ProfileName != "ProfileBeta" || result.Conflict != ConflictMergeWraps {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	betaRoot := filepath.Join(profilesPath, "ProfileBeta")
+	assertFileContent(t, filepath.Join(betaRoot, "savegame", "slot.sav"), "beta-save")
+	assertFileContent(t, filepath.Join(betaRoot, "wraps", "beta.txt"), "beta-only")
+	assertFileContent(t, filepath.Join(betaRoot, "wraps", "alpha.txt"), "alpha-only")
+	assertFileContent(t, filepath.Join(betaRoot, "wraps", "shared.txt"), "alpha-shared")
+}
+
+type countingOps struct {
+	*fsops.Local
+	copies int
+}
+
+func (o *countingOps) CopyDir(ctx context.Context, source string, destination string) error {
+	o.copies++
+	return o.Local.CopyDir(ctx, source, destination)
+}
+
+type fakeMarker string
+
+func (m fakeMarker) ReadActiveProfile() (string, error) {
+	return string(m), nil
+}
+
+// recordingKeeper records the calls an import makes around the active
+// profile, with the stored savegame seen at each.
+type recordingKeeper struct {
+	profileRoot string
+	calls       []string
+	reloadErr   error
+	runningErr  error
+}
+
+func (k *recordingKeeper) record(call string) {
+	content, _ := os.ReadFile(filepath.Join(k.profileRoot, "savegame", "slot.sav"))
+	k.calls = append(k.calls, call+":"+string(content))
+}
+
+func (k *recordingKeeper) CheckGameNotRunning() error {
+	return k.runningErr
+}
+
+func (k *recordingKeeper) SaveCurrentProfileContext(ctx context.Context, profileName string) error {
+	k.record("save " + profileName)
+	return nil
+}
+
+func (k *recordingKeeper) ReloadProfileContext(ctx context.Context, profileName string) error {
+	k.record("reload " + profileName)
+	return k.reloadErr
+}
+
+func TestImportOverActiveProfileSavesAndReloadsRoot(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "alpha.zip")
+	svc := NewService(profilesPath)
+	keeper := &recordingKeeper{profileRoot: filepath.Join(profilesPath, "ProfileBeta")}
+	svc.SetMarker(fakeMarker("ProfileBeta"))
+	svc.SetActiveProfileKeeper(keeper)
+	writeConflictProfiles(t, svc, profilesPath, bundlePath)
+
+	result, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictOverwrite})
+	if err != nil {
+		t.Fatalf("import over active profile: %v", err)
+	}
+
+	if !result.ActiveReloaded {
+		t.Fatalf("expected the root save to be reloaded, got %+v", result)
+	}
+
+	expected := []string{"save ProfileBeta:beta-save", "reload ProfileBeta:alpha-save"}
+	if fmt.Sprint(keeper.calls) != fmt.Sprint(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, keeper.calls)
+	}
+
+	keeper.calls = nil
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictRename}); err != nil {
+		t.Fatalf("import with rename: %v", err)
+	}
+
+	if len(keeper.calls) != 0 {
+		t.Fatalf("expected a renamed import to leave the root alone, got %v", keeper.calls)
+	}
+
+	keeper.reloadErr = errors.New("game is running")
+	result, err = svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictOverwrite})
+	if !errors.Is(err, ErrActiveProfileNotLoaded) || result.ProfileName != "ProfileBeta" || result.ActiveReloaded {
+		t.Fatalf("expected the import to stand and the reload to be reported, got %+v err=%v", result, err)
+	}
+}
+
+func TestImportOverActiveProfileSavesOnlyAfterTheBundleChecksOut(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	profileRoot := filepath.Join(profilesPath, "ProfileBeta")
+	bundlePath := filepath.Join(root, "broken-layout.zip")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "beta-save")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "beta-wrap")
+	createZipWithEntry(t, bundlePath, "savegame/slot.sav", "new-save-only")
+
+	svc := NewService(profilesPath)
+	keeper := &recordingKeeper{profileRoot: profileRoot}
+	svc.SetMarker(fakeMarker("ProfileBeta"))
+	svc.SetActiveProfileKeeper(keeper)
+
+	_, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictOverwrite})
+	if !errors.Is(err, profiles.ErrInvalidProfileLayout) {
+		t.Fatalf("expected ErrInvalidProfileLayout, got %v", err)
+	}
+
+	if len(keeper.calls) != 0 {
+		t.Fatalf("expected a bad bundle to leave the active profile unsaved, got %v", keeper.calls)
+	}
+}
+
+func TestImportOverActiveProfileRefusesWhileGameIsRunning(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "alpha.zip")
+	svc := NewService(profilesPath)
+	runningErr := errors.New("game is running")
+	keeper := &recordingKeeper{profileRoot: filepath.Join(profilesPath, "ProfileBeta"), runningErr: runningErr}
+	svc.SetMarker(fakeMarker("ProfileBeta"))
+	svc.SetActiveProfileKeeper(keeper)
+	writeConflictProfiles(t, svc, profilesPath, bundlePath)
+
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictOverwrite}); !errors.Is(err, runningErr) {
+		t.Fatalf("expected the import to be refused, got %v", err)
+	}
+
+	if len(keeper.calls) != 0 {
+		t.Fatalf("expected the root to be left alone, got %v", keeper.calls)
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileBeta", "savegame", "slot.sav"), "beta-save")
+
+	if _, err := svc.ImportProfileWithOptions("ProfileBeta", bundlePath, ImportOptions{Conflict: ConflictRename}); err != nil {
+		t.Fatalf("expected an import under a new name to go ahead, got %v", err)
+	}
+}
+
+func TestImportUnwrapsTopLevelFolderAndFixesFolderCase(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "zipped-folder.zip")
+	createZipWithEntries(t, bundlePath, map[string]string{
+		"MyProfile/":                  "",
+		"MyProfile/SaveGame/slot.sav": "save-data",
+		"MyProfile/WRAPS/wrap.txt":    "wrap-data",
+		"MyProfile/WRAPS/empty/":      "",
+	})
+
+	svc := NewService(profilesPath)
+	inspection, err := svc.Inspect(bundlePath, ImportOptions{})
+	if err != nil || !inspection.CanImport || inspection.Layout.WrapperFolder != "MyProfile" {
+		t.Fatalf("expected the preview to accept the wrapped bundle, got %+v err=%v", inspection, err)
+	}
+
+	result, err := svc.ImportProfileWithOptions("ProfileWrapped", bundlePath, ImportOptions{})
+	if err != nil {
+		t.Fatalf("import wrapped bundle: %v", err)
+	}
+
+	renamed := map[string]string{}
+	for _, rename := range result.Layout.RenamedFolders {
+		renamed[rename.From] = rename.To
+	}
+
+	if result.Layout.WrapperFolder != "MyProfile" || len(renamed) != 2 || renamed["SaveGame"] != "savegame" || renamed["WRAPS"] != "wraps" {
+		t.Fatalf("unexpected layout report: %+v", result.Layout)
+	}
+
+	profileRoot := filepath.Join(profilesPath, "ProfileWrapped")
+	assertFileContent(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	assertFileContent(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap-data")
+	if info, err := os.Stat(filepath.Join(profileRoot, "wraps", "empty")); err != nil || !info.IsDir() {
+		t.Fatalf("expected the empty wraps folder to be kept, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(profileRoot, "MyProfile")); !os.IsNotExist(err) {
+		t.Fatalf("expected the wrapper folder to be stripped, got %v", err)
+	}
+}
+
+func TestImportRejectsEntriesThatCollideAfterCaseNormalization(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profilesPath := filepath.Join(root, "Profiles")
+	bundlePath := filepath.Join(root, "colliding.zip")
+	createZipWithEntries(t, bundlePath, map[string]string{
+		"savegame/slot.sav": "lower",
+		"Savegame/slot.sav": "upper",
+		"wraps/wrap.txt":    "wrap-data",
+	})
+
+	svc := NewService(profilesPath)
+	if err := svc.ImportProfile("ProfileCollide", bundlePath); !errors.Is(err, ErrDuplicateBundleEntry) {
+		t.Fatalf("expected ErrDuplicateBundleEntry, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(profilesPath, "ProfileCollide")); !os.IsNotExist(err) {
+		t.Fatalf("expected no profile to be created, got %v", err)
+	}
+}
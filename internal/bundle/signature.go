@@ -0,0 +1,120 @@
+package bundle
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"heat-save-manager/internal/config"
+	"heat-save-manager/internal/signing"
+)
+
+var (
+	ErrSignatureInvalid        = errors.New("bundle signature does not match its contents")
+	ErrSignatureWithoutPayload = errors.New("bundle is signed but has no manifest")
+)
+
+// SignatureFileName holds an ed25519 signature over the exact bytes of
+// manifest.json. The manifest lists a SHA-256 per file, so the signature
+// covers the whole bundle.
+const SignatureFileName = "manifest.sig"
+
+const (
+	SignatureUnsigned  = "unsigned"
+	SignatureUntrusted = "untrusted"
+	SignatureTrusted   = "trusted"
+)
+
+type SignatureReport struct {
+	Status      string `json:"status"`
+	Signed      bool   `json:"signed"`
+	Valid       bool   `json:"valid"`
+	Trusted     bool   `json:"trusted"`
+	Author      string `json:"author"`
+	Fingerprint string `json:"fingerprint"`
+	TrustedName string `json:"trustedName"`
+	PublicKey   string `json:"publicKey"`
+}
+
+// Warning is true when the bundle imported but its origin is not vouched for.
+func (r SignatureReport) Warning() bool {
+	return r.Status != SignatureTrusted
+}
+
+type signatureEnvelope struct {
+	Author    string `json:"author"`
+	PublicKey string `json:"publicKey"`
+	Signature string `json:"signature"`
+}
+
+func writeSignature(archive *zip.Writer, signer *signing.Identity, manifestContent []byte) error {
+	content, err := json.MarshalIndent(signatureEnvelope{
+		Author:    signer.Author,
+		PublicKey: signing.EncodePublicKey(signer.PublicKey),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(signer.PrivateKey, manifestContent)),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	writer, err := archive.Create(SignatureFileName)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(content)
+	return err
+}
+
+// verifySignature checks the bundle's signature, if any, against the manifest
+// bytes. A present but invalid signature is an error; unsigned and untrusted
+// bundles are reported rather than rejected.
+func verifySignature(files []*zip.File, manifestContent []byte, trusted []config.TrustedKey) (SignatureReport, error) {
+	content, found, err := readSmallEntry(files, SignatureFileName)
+	if err != nil {
+		return SignatureReport{}, err
+	}
+
+	if !found {
+		return SignatureReport{Status: SignatureUnsigned}, nil
+	}
+
+	if manifestContent == nil {
+		return SignatureReport{}, ErrSignatureWithoutPayload
+	}
+
+	var envelope signatureEnvelope
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return SignatureReport{}, fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+
+	publicKey, err := signing.ParsePublicKey(envelope.PublicKey)
+	if err != nil {
+		return SignatureReport{}, fmt.Errorf("%w: %v", ErrSignatureInvalid, err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(envelope.Signature)
+	if err != nil || !ed25519.Verify(publicKey, manifestContent, signature) {
+		return SignatureReport{}, ErrSignatureInvalid
+	}
+
+	report := SignatureReport{
+		Status:      SignatureUntrusted,
+		Signed:      true,
+		Valid:       true,
+		Author:      envelope.Author,
+		Fingerprint: signing.Fingerprint(publicKey),
+		PublicKey:   signing.EncodePublicKey(publicKey),
+	}
+
+	if key, ok := signing.FindTrustedKey(trusted, publicKey); ok {
+		report.Status = SignatureTrusted
+		report.Trusted = true
+		report.TrustedName = key.Name
+	}
+
+	return report, nil
+}
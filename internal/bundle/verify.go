@@ -0,0 +1,200 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrExportVerificationFailed = errors.New("exported bundle failed verification")
+
+// ExportResult describes a bundle that was written and verified.
+type ExportResult struct {
+	BundlePath  string `json:"bundlePath"`
+	Size        int64  `json:"size"`
+	Files       int    `json:"files"`
+	Directories int    `json:"directories"`
+	Encrypted   bool   `json:"encrypted"`
+	Signed      bool   `json:"signed"`
+}
+
+// writeBundleFile fills a temporary file next to path with write, checks it
+// with verify and only then renames it over path, so a failed or cancelled
+// export never leaves a truncated bundle behind or replaces an older one.
+func writeBundleFile(ctx context.Context, path string, write func(*os.File) error, verify func(string) error) (int64, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".export-*")
+	if err != nil {
+		return 0, err
+	}
+
+	tmpPath := file.Name()
+	fail := func(err error) (int64, error) {
+		file.Close()
+		os.Remove(tmpPath)
+		return 0, err
+	}
+
+	if err := file.Chmod(0o644); err != nil {
+		return fail(err)
+	}
+
+	if err := write(file); err != nil {
+		return fail(err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return fail(err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fail(err)
+	}
+
+	if err := verify(tmpPath); err != nil {
+		return fail(fmt.Errorf("%w: %v", ErrExportVerificationFailed, err))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fail(err)
+	}
+
+	return info.Size(), nil
+}
+
+// verifyArchive re-reads a plain bundle the way an import would: every entry
+// must decompress with a matching CRC, match the manifest's checksums, and
+// together form a valid profile with nothing missing or extra.
+func verifyArchive(path string) (ExportResult, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return ExportResult{}, err
+	}
+	defer reader.Close()
+
+	manifest, _, err := readManifest(reader.File)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	if manifest == nil {
+		return ExportResult{}, errors.New("bundle has no manifest")
+	}
+
+	checksums, err := newChecksumIndex(manifest)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	result := ExportResult{Files: len(manifest.Entries), Directories: len(manifest.Directories)}
+	topLevel := map[string]bool{}
+	for _, f := range reader.File {
+		if f.Name == SignatureFileName {
+			result.Signed = true
+		}
+
+		hasher := sha256.New()
+		in, err := f.Open()
+		if err != nil {
+			return ExportResult{}, err
+		}
+
+		_, copyErr := io.Copy(hasher, in)
+		closeErr := in.Close()
+		if copyErr != nil {
+			return ExportResult{}, fmt.Errorf("%s: %w", f.Name, copyErr)
+		}
+
+		if closeErr != nil {
+			return ExportResult{}, closeErr
+		}
+
+		if f.Name == ManifestFileName || f.Name == SignatureFileName {
+			continue
+		}
+
+		topLevel[strings.SplitN(strings.TrimSuffix(f.Name, "/"), "/", 2)[0]] = true
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		if err := checksums.verify(f.Name, hex.EncodeToString(hasher.Sum(nil))); err != nil {
+			return ExportResult{}, err
+		}
+	}
+
+	if err := checksums.ensureComplete(); err != nil {
+		return ExportResult{}, err
+	}
+
+	expected := result.Files + result.Directories + 1
+	if result.Signed {
+		expected++
+	}
+
+	if len(reader.File) != expected {
+		return ExportResult{}, fmt.Errorf("bundle has %d entries, expected %d", len(reader.File), expected)
+	}
+
+	for _, required := range []string{"savegame", "wraps"} {
+		if !topLevel[required] {
+			return ExportResult{}, fmt.Errorf("bundle is missing the %s folder", required)
+		}
+	}
+
+	return result, nil
+}
+
+// verifyEncrypted decrypts an encrypted bundle and checks that it yields
+// exactly the plain archive it was made from.
+func verifyEncrypted(path string, passphrase string, plainSHA256 []byte) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	hasher := sha256.New()
+	if err := decryptBundle(hasher, in, passphrase); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(hasher.Sum(nil), plainSHA256) {
+		return errors.New("decrypted bundle does not match the archive")
+	}
+
+	return nil
+}
+
+func fileSHA256(file *os.File) ([]byte, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return hasher.Sum(nil), nil
+}
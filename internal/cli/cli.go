@@ -0,0 +1,1041 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/audit"
+	"heat-save-manager/internal/backups"
+	"heat-save-manager/internal/bundle"
+	"heat-save-manager/internal/config"
+	"heat-save-manager/internal/discovery"
+	"heat-save-manager/internal/gameproc"
+	"heat-save-manager/internal/lifecycle"
+	"heat-save-manager/internal/marker"
+	"heat-save-manager/internal/profiles"
+	"heat-save-manager/internal/savelock"
+	"heat-save-manager/internal/signing"
+	"heat-save-manager/internal/snapshot"
+	"heat-save-manager/internal/switcher"
+	"heat-save-manager/internal/workspace"
+)
+
+// Exit codes are part of the scripting contract; do not renumber them.
+const (
+	ExitOK            = 0
+	ExitFailure       = 1
+	ExitUsage         = 2
+	ExitNotConfigured = 3
+	ExitNotFound      = 4
+	ExitConflict      = 5
+	ExitGameRunning   = 6
+	ExitUnhealthy     = 7
+	ExitBusy          = 8
+)
+
+var (
+	errUsage         = errors.New("usage error")
+	errNotConfigured = errors.New("savegame path is not configured")
+	errConfigStore   = errors.New("config store is unavailable")
+	errNotFound      = errors.New("not found")
+)
+
+const usageText = `Usage: heat-save-manager [--savegame PATH] <command> [flags] [args]
+
+Commands:
+  list                                  List profiles and mark the active one
+  scan                                  List every entry of the Profiles folder with its status
+  active                                Print the active profile
+  status                                Compare the root save with the active profile's stored copy
+  identify [--apply]                    Find the profile the root save belongs to; --apply rewrites
+                                        the marker to the suggested profile
+  switch NAME [--no-save] [--force]     Switch the root save to profile NAME
+  save [NAME]                           Save the root save into NAME (default: active)
+  fresh NAME [--no-save] [--force]      Start a new empty profile NAME
+  rename OLD NEW                        Rename a profile
+  delete NAME [--replacement R] [--force]
+                                        Delete a profile; the active profile needs a replacement
+  export NAME PATH [--description D] [--author A] [--passphrase-env VAR] [--sign]
+                                        Export a profile bundle to PATH, encrypted when VAR is given
+  inspect PATH [--passphrase-env VAR]   Preview the bundle at PATH without importing it
+  import NAME PATH [--passphrase-env VAR] [--on-conflict POLICY]
+                                        Import the bundle at PATH as profile NAME; when NAME
+                                        exists POLICY is fail (default), overwrite, rename
+                                        or merge-wraps
+  health                                Run diagnostics (exit 7 when not ready)
+  recover                               Finish or undo operations interrupted by a crash
+  config get [KEY]                      Print settings or one setting
+  config set KEY VALUE                  Change a setting
+  keys generate AUTHOR | show           Create or show the local bundle signing key
+  keys list | trust NAME PUBKEY | untrust KEY
+                                        Manage trusted bundle author keys
+  version                               Print the version
+
+All commands write a single JSON object to stdout.
+`
+
+type Environment struct {
+	Stdout       io.Writer
+	Stderr       io.Writer
+	ConfigStore  *config.Store
+	GameDetector gameproc.Detector
+	Version      string
+}
+
+type response struct {
+	OK      bool        `json:"ok"`
+	Command string      `json:"command"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *errorBody  `json:"error,omitempty"`
+}
+
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ProfileEntry struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+type SwitchResult struct {
+	ProfileName string    `json:"profileName"`
+	SwitchedAt  time.Time `json:"switchedAt"`
+	RolledBack  bool      `json:"rolledBack"`
+	BackupID    string    `json:"backupId,omitempty"`
+	// BackupWarning reports a pre-switch backup that could not be kept.
+	BackupWarning string `json:"backupWarning,omitempty"`
+}
+
+type runner struct {
+	env          Environment
+	saveGamePath string
+}
+
+func Run(args []string, env Environment) int {
+	if env.Stdout == nil {
+		env.Stdout = os.Stdout
+	}
+	if env.Stderr == nil {
+		env.Stderr = os.Stderr
+	}
+
+	global := flag.NewFlagSet("heat-save-manager", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	saveGamePath := global.String("savegame", "", "SaveGame folder to operate on")
+	if err := global.Parse(args); err != nil {
+		return writeFailure(env, "", fmt.Errorf("%w: %v", errUsage, err))
+	}
+
+	rest := global.Args()
+	if len(rest) == 0 {
+		fmt.Fprint(env.Stderr, usageText)
+		return writeFailure(env, "", fmt.Errorf("%w: command is required", errUsage))
+	}
+
+	r := &runner{env: env, saveGamePath: *saveGamePath}
+	command := strings.ToLower(rest[0])
+	result, err := r.dispatch(command, rest[1:])
+	if err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(env.Stderr, usageText)
+		}
+		return writeFailure(env, command, err)
+	}
+
+	code := ExitOK
+	if report, ok := result.(healthResult); ok && !report.Ready {
+		code = ExitUnhealthy
+	}
+
+	writeJSON(env.Stdout, response{OK: code == ExitOK, Command: command, Result: result})
+	return code
+}
+
+func (r *runner) dispatch(command string, args []string) (interface{}, error) {
+	switch command {
+	case "list":
+		return r.list(args)
+	case "scan":
+		return r.scan(args)
+	case "status":
+		return r.status(args)
+	case "identify":
+		return r.identify(args)
+	case "active":
+		return r.active(args)
+	case "switch":
+		return r.switchProfile(args)
+	case "save":
+		return r.save(args)
+	case "fresh":
+		return r.fresh(args)
+	case "rename":
+		return r.rename(args)
+	case "delete":
+		return r.deleteProfile(args)
+	case "export":
+		return r.export(args)
+	case "inspect":
+		return r.inspectBundle(args)
+	case "import":
+		return r.importBundle(args)
+	case "health":
+		return r.health(args)
+	case "recover":
+		return r.recover(args)
+	case "config":
+		return r.configCommand(args)
+	case "keys":
+		return r.keysCommand(args)
+	case "version":
+		return map[string]string{"version": r.env.Version}, nil
+	case "help":
+		return map[string]string{"usage": usageText}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown command %q", errUsage, command)
+	}
+}
+
+func (r *runner) list(args []string) (interface{}, error) {
+	if _, err := parseCommand("list", args, 0, 0, nil); err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := ws.Profiles().List()
+	if err != nil {
+		return nil, err
+	}
+
+	active, _ := ws.MarkerStore().ReadActiveProfile()
+	entries := make([]ProfileEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, ProfileEntry{
+			Name:   item.Name,
+			Active: strings.EqualFold(item.Name, strings.TrimSpace(active)),
+		})
+	}
+
+	return entries, nil
+}
+
+func (r *runner) scan(args []string) (interface{}, error) {
+	if _, err := parseCommand("scan", args, 0, 0, nil); err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return ws.Profiles().Scan()
+}
+
+func (r *runner) status(args []string) (interface{}, error) {
+	if _, err := parseCommand("status", args, 0, 0, nil); err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return ws.RootSync().Check()
+}
+
+func (r *runner) identify(args []string) (interface{}, error) {
+	var apply bool
+	if _, err := parseCommand("identify", args, 0, 0, func(fs *flag.FlagSet) {
+		fs.BoolVar(&apply, "apply", false, "rewrite the active marker to the suggested profile")
+	}); err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	identification, err := ws.RootSync().Identify()
+	if err != nil {
+		return nil, err
+	}
+
+	if apply && identification.Suggested != "" {
+		err := ws.Record("set-marker", identification.Suggested, []string{ws.SaveGamePath}, func(*audit.Entry) error {
+			return ws.Lifecycle().SetActiveProfile(identification.Suggested)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return identification, nil
+}
+
+func (r *runner) active(args []string) (interface{}, error) {
+	if _, err := parseCommand("active", args, 0, 0, nil); err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := ws.MarkerStore().ReadActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"profile": active}, nil
+}
+
+func (r *runner) switchProfile(args []string) (interface{}, error) {
+	var noSave, force bool
+	positional, err := parseCommand("switch", args, 1, 1, func(fs *flag.FlagSet) {
+		fs.BoolVar(&noSave, "no-save", false, "do not save the outgoing profile first")
+		fs.BoolVar(&force, "force", false, "skip the game-running check")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(force)
+	if err != nil {
+		return nil, err
+	}
+
+	var result switcher.Result
+	err = ws.Record("switch", positional[0], []string{ws.SaveGamePath, ws.ProfileDir(positional[0])}, func(entry *audit.Entry) error {
+		var err error
+		result, err = ws.Lifecycle().SwitchProfile(positional[0], ws.Config.SaveBeforeSwitch && !noSave)
+		entry.RolledBack = result.RolledBack
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return SwitchResult{
+		ProfileName:   result.ProfileName,
+		SwitchedAt:    result.SwitchedAt,
+		RolledBack:    result.RolledBack,
+		BackupID:      result.BackupID,
+		BackupWarning: result.BackupWarning,
+	}, nil
+}
+
+func (r *runner) save(args []string) (interface{}, error) {
+	positional, err := parseCommand("save", args, 0, 1, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	name := ""
+	if len(positional) == 1 {
+		name = positional[0]
+	}
+
+	err = ws.Record("save", name, []string{ws.SaveGamePath, ws.ProfileDir(name)}, func(*audit.Entry) error {
+		return ws.Lifecycle().SaveCurrentProfile(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name, _ = ws.MarkerStore().ReadActiveProfile()
+	}
+
+	return map[string]string{"profile": strings.TrimSpace(name)}, nil
+}
+
+func (r *runner) fresh(args []string) (interface{}, error) {
+	var noSave, force bool
+	positional, err := parseCommand("fresh", args, 1, 1, func(fs *flag.FlagSet) {
+		fs.BoolVar(&noSave, "no-save", false, "do not save the current profile first")
+		fs.BoolVar(&force, "force", false, "skip the game-running check")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(force)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ws.Record("fresh", positional[0], []string{ws.SaveGamePath, ws.ProfileDir(positional[0])}, func(*audit.Entry) error {
+		service := ws.Lifecycle()
+		if noSave {
+			return service.PrepareFreshProfileWithoutSave(positional[0])
+		}
+		return service.PrepareFreshProfile(positional[0])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"profile": strings.TrimSpace(positional[0])}, nil
+}
+
+func (r *runner) rename(args []string) (interface{}, error) {
+	positional, err := parseCommand("rename", args, 2, 2, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ws.Record("rename", positional[0], []string{ws.ProfileDir(positional[0]), ws.ProfileDir(positional[1])}, func(*audit.Entry) error {
+		return ws.Lifecycle().RenameProfile(positional[0], positional[1])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"oldName": strings.TrimSpace(positional[0]), "newName": strings.TrimSpace(positional[1])}, nil
+}
+
+func (r *runner) deleteProfile(args []string) (interface{}, error) {
+	var replacement string
+	var force bool
+	positional, err := parseCommand("delete", args, 1, 1, func(fs *flag.FlagSet) {
+		fs.StringVar(&replacement, "replacement", "", "profile to switch to when deleting the active profile")
+		fs.BoolVar(&force, "force", false, "skip the game-running check")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(force)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(positional[0])
+	active, _ := ws.MarkerStore().ReadActiveProfile()
+	service := ws.Lifecycle()
+	if strings.TrimSpace(replacement) != "" && strings.EqualFold(strings.TrimSpace(active), name) {
+		err = ws.Record("delete-active", "", []string{ws.SaveGamePath, ws.ProfileDir(replacement)}, func(*audit.Entry) error {
+			return service.DeleteActiveProfile(replacement)
+		})
+	} else {
+		err = ws.Record("delete", name, []string{ws.ProfileDir(name)}, func(*audit.Entry) error {
+			return service.DeleteProfile(name)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"profile": name}, nil
+}
+
+func (r *runner) export(args []string) (interface{}, error) {
+	var options bundle.ExportOptions
+	var passphraseEnv string
+	var sign bool
+	positional, err := parseCommand("export", args, 2, 2, func(fs *flag.FlagSet) {
+		fs.StringVar(&options.Description, "description", "", "description stored in the bundle manifest")
+		fs.StringVar(&options.Author, "author", "", "author stored in the bundle manifest")
+		fs.StringVar(&passphraseEnv, "passphrase-env", "", "environment variable holding the encryption passphrase")
+		fs.BoolVar(&sign, "sign", false, "sign the bundle with the local signing key")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Passphrase, err = passphraseFromEnv(passphraseEnv); err != nil {
+		return nil, err
+	}
+
+	if sign {
+		keys, err := r.keyStore()
+		if err != nil {
+			return nil, err
+		}
+
+		identity, err := keys.Load()
+		if err != nil {
+			return nil, err
+		}
+
+		options.Signer = &identity
+		if strings.TrimSpace(options.Author) == "" {
+			options.Author = identity.Author
+		}
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var result bundle.ExportResult
+	err = ws.Record("export", positional[0], []string{ws.ProfileDir(positional[0]), positional[1]}, func(*audit.Entry) error {
+		var err error
+		result, err = ws.Bundles().ExportProfileWithOptions(positional[0], positional[1], options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"profile":     strings.TrimSpace(positional[0]),
+		"bundlePath":  result.BundlePath,
+		"size":        result.Size,
+		"files":       result.Files,
+		"directories": result.Directories,
+	}, nil
+}
+
+// inspectBundle previews a bundle without importing it.
+func (r *runner) inspectBundle(args []string) (interface{}, error) {
+	var passphraseEnv string
+	positional, err := parseCommand("inspect", args, 1, 1, func(fs *flag.FlagSet) {
+		fs.StringVar(&passphraseEnv, "passphrase-env", "", "environment variable holding the bundle passphrase")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var options bundle.ImportOptions
+	if options.Passphrase, err = passphraseFromEnv(passphraseEnv); err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return ws.Bundles().Inspect(positional[0], options)
+}
+
+func (r *runner) importBundle(args []string) (interface{}, error) {
+	var passphraseEnv string
+	var options bundle.ImportOptions
+	positional, err := parseCommand("import", args, 2, 2, func(fs *flag.FlagSet) {
+		fs.StringVar(&passphraseEnv, "passphrase-env", "", "environment variable holding the bundle passphrase")
+		fs.StringVar(&options.Conflict, "on-conflict", bundle.ConflictFail, "what to do when the profile exists: fail, overwrite, rename or merge-wraps")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Passphrase, err = passphraseFromEnv(passphraseEnv); err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var result bundle.ImportResult
+	err = ws.Record("import", positional[0], []string{positional[1], ws.ProfileDir(positional[0])}, func(*audit.Entry) error {
+		var err error
+		result, err = ws.Bundles().ImportProfileWithOptions(positional[0], positional[1], options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *runner) recover(args []string) (interface{}, error) {
+	if _, err := parseCommand("recover", args, 0, 0, nil); err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	actions, err := ws.RecoverInterrupted()
+	ws.RecordRecovery(started, actions, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
+
+type healthResult struct {
+	Ready     bool        `json:"ready"`
+	CheckedAt time.Time   `json:"checkedAt"`
+	Items     interface{} `json:"items"`
+}
+
+func (r *runner) health(args []string) (interface{}, error) {
+	if _, err := parseCommand("health", args, 0, 0, nil); err != nil {
+		return nil, err
+	}
+
+	ws, err := r.workspace(false)
+	if err != nil && !errors.Is(err, errNotConfigured) {
+		return nil, err
+	}
+
+	report := ws.Health().Run()
+	return healthResult{Ready: report.Ready, CheckedAt: report.CheckedAt, Items: report.Items}, nil
+}
+
+func (r *runner) configCommand(args []string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%w: config needs get or set", errUsage)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "get":
+		positional, err := parseCommand("config get", args[1:], 0, 1, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		values, err := configValues(r.loadConfig())
+		if err != nil {
+			return nil, err
+		}
+
+		if len(positional) == 0 {
+			return values, nil
+		}
+
+		value, ok := values[positional[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown setting %q", errUsage, positional[0])
+		}
+
+		return map[string]interface{}{positional[0]: value}, nil
+	case "set":
+		positional, err := parseCommand("config set", args[1:], 2, 2, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result interface{}
+		err = r.auditLog().Record("change-setting", positional[0], nil, func(*audit.Entry) error {
+			var err error
+			result, err = r.setConfig(positional[0], positional[1])
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown config action %q", errUsage, args[0])
+	}
+}
+
+type identityResult struct {
+	Author      string    `json:"author"`
+	PublicKey   string    `json:"publicKey"`
+	Fingerprint string    `json:"fingerprint"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (r *runner) keysCommand(args []string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%w: keys needs generate, show, list, trust or untrust", errUsage)
+	}
+
+	action := strings.ToLower(args[0])
+	switch action {
+	case "generate", "show":
+		minArgs, maxArgs := 1, 1
+		if action == "show" {
+			minArgs, maxArgs = 0, 0
+		}
+
+		positional, err := parseCommand("keys "+action, args[1:], minArgs, maxArgs, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err := r.keyStore()
+		if err != nil {
+			return nil, err
+		}
+
+		var identity signing.Identity
+		if action == "generate" {
+			err = r.auditLog().Record("generate-signing-key", positional[0], nil, func(*audit.Entry) error {
+				var err error
+				identity, err = keys.Generate(positional[0])
+				return err
+			})
+		} else {
+			identity, err = keys.Load()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return identityResult{
+			Author:      identity.Author,
+			PublicKey:   signing.EncodePublicKey(identity.PublicKey),
+			Fingerprint: identity.Fingerprint(),
+			CreatedAt:   identity.CreatedAt,
+		}, nil
+	case "list":
+		if _, err := parseCommand("keys list", args[1:], 0, 0, nil); err != nil {
+			return nil, err
+		}
+
+		return r.loadConfig().TrustedKeys, nil
+	case "trust", "untrust":
+		minArgs := 2
+		if action == "untrust" {
+			minArgs = 1
+		}
+
+		positional, err := parseCommand("keys "+action, args[1:], minArgs, minArgs, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.env.ConfigStore == nil {
+			return nil, errConfigStore
+		}
+
+		cfg := r.loadConfig()
+		err = r.auditLog().Record(action+"-key", positional[0], nil, func(*audit.Entry) error {
+			if action == "trust" {
+				var err error
+				cfg.TrustedKeys, err = signing.AddTrustedKey(cfg.TrustedKeys, positional[0], positional[1], time.Now())
+				if err != nil {
+					return err
+				}
+			} else {
+				var removed bool
+				cfg.TrustedKeys, removed = signing.RemoveTrustedKey(cfg.TrustedKeys, positional[0])
+				if !removed {
+					return fmt.Errorf("%w: trusted key %s", errNotFound, positional[0])
+				}
+			}
+
+			if err := r.env.ConfigStore.Save(cfg); err != nil {
+				return fmt.Errorf("save app settings: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return cfg.TrustedKeys, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown keys action %q", errUsage, args[0])
+	}
+}
+
+func (r *runner) keyStore() (*signing.KeyStore, error) {
+	if r.env.ConfigStore == nil {
+		return nil, errConfigStore
+	}
+
+	return signing.NewKeyStore(r.env.ConfigStore.Dir()), nil
+}
+
+func (r *runner) setConfig(key string, rawValue string) (interface{}, error) {
+	if r.env.ConfigStore == nil {
+		return nil, errConfigStore
+	}
+
+	cfg := r.loadConfig()
+	switch key {
+	case "profilesPath":
+		return nil, fmt.Errorf("%w: profilesPath is derived from saveGamePath", errUsage)
+	case "trustedKeys":
+		return nil, fmt.Errorf("%w: use keys trust and keys untrust to manage trusted keys", errUsage)
+	case "saveGamePath":
+		paths, err := discovery.ValidateSaveGamePath(rawValue)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(paths.ProfilesPath, 0o755); err != nil {
+			return nil, fmt.Errorf("ensure Profiles folder: %w", err)
+		}
+
+		cfg.SaveGamePath = paths.SaveGamePath
+		cfg.ProfilesPath = paths.ProfilesPath
+	case "language":
+		language, err := config.ValidateLanguage(rawValue)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Language = language
+	default:
+		values, err := configValues(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		current, ok := values[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown setting %q", errUsage, key)
+		}
+
+		switch current.(type) {
+		case bool:
+			parsed, err := strconv.ParseBool(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s expects true or false", errUsage, key)
+			}
+			values[key] = parsed
+		case float64:
+			parsed, err := strconv.Atoi(rawValue)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("%w: %s expects a non-negative whole number", errUsage, key)
+			}
+			values[key] = parsed
+		default:
+			values[key] = rawValue
+		}
+
+		content, err := json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(content, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.env.ConfigStore.Save(cfg); err != nil {
+		return nil, fmt.Errorf("save app settings: %w", err)
+	}
+
+	return configValues(cfg)
+}
+
+// workspace resolves the SaveGame folder from --savegame, the saved settings,
+// or the default documents location, in that order.
+func (r *runner) workspace(skipGameCheck bool) (workspace.Workspace, error) {
+	cfg := r.loadConfig()
+	if skipGameCheck {
+		cfg.CheckGameRunning = false
+	}
+
+	ws := r.auditLog()
+	ws.Config = cfg
+	ws.GameDetector = r.env.GameDetector
+	ws.AppVersion = r.env.Version
+
+	candidate := strings.TrimSpace(r.saveGamePath)
+	if candidate == "" {
+		candidate = strings.TrimSpace(cfg.SaveGamePath)
+	}
+
+	if candidate == "" {
+		located, err := discovery.LocateDefault()
+		if err != nil {
+			return ws, fmt.Errorf("%w: %v", errNotConfigured, err)
+		}
+		candidate = located.SaveGamePath
+	}
+
+	paths, err := discovery.ValidateSaveGamePath(candidate)
+	if err != nil {
+		return ws, fmt.Errorf("%w: %v", errNotConfigured, err)
+	}
+
+	if err := os.MkdirAll(paths.ProfilesPath, 0o755); err != nil {
+		return ws, fmt.Errorf("ensure Profiles folder: %w", err)
+	}
+
+	ws.SaveGamePath = paths.SaveGamePath
+	ws.ProfilesPath = paths.ProfilesPath
+	return ws, nil
+}
+
+// auditLog returns a workspace that only records to the audit log the desktop
+// app writes, for commands that change settings rather than the SaveGame
+// folder. Without a config store nothing is recorded.
+func (r *runner) auditLog() workspace.Workspace {
+	if r.env.ConfigStore == nil {
+		return workspace.Workspace{}
+	}
+
+	return workspace.Workspace{AuditLog: audit.New(r.env.ConfigStore.Dir())}
+}
+
+func (r *runner) loadConfig() config.AppConfig {
+	cfg := config.Default()
+	if r.env.ConfigStore != nil {
+		if loaded, err := r.env.ConfigStore.Load(); err == nil {
+			cfg = loaded
+		}
+	}
+
+	cfg.Language = config.NormalizeLanguage(cfg.Language)
+	return cfg
+}
+
+// passphraseFromEnv reads passphrases from the environment so they never show
+// up in process listings or shell history.
+func passphraseFromEnv(name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil
+	}
+
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: environment variable %s is not set", errUsage, name)
+	}
+
+	return value, nil
+}
+
+func configValues(cfg config.AppConfig) (map[string]interface{}, error) {
+	content, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(content, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// parseCommand accepts flags before, between and after positional arguments.
+func parseCommand(name string, args []string, minArgs int, maxArgs int, define func(*flag.FlagSet)) ([]string, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if define != nil {
+		define(fs)
+	}
+
+	positional := make([]string, 0, len(args))
+	remaining := args
+	for {
+		if err := fs.Parse(remaining); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", errUsage, name, err)
+		}
+
+		if fs.NArg() == 0 {
+			break
+		}
+
+		positional = append(positional, fs.Arg(0))
+		remaining = fs.Args()[1:]
+	}
+
+	if len(positional) < minArgs || len(positional) > maxArgs {
+		return nil, fmt.Errorf("%w: %s: wrong number of arguments", errUsage, name)
+	}
+
+	return positional, nil
+}
+
+func writeFailure(env Environment, command string, err error) int {
+	code, errCode := classifyError(err)
+	writeJSON(env.Stdout, response{
+		OK:      false,
+		Command: command,
+		Error:   &errorBody{Code: errCode, Message: err.Error()},
+	})
+	return code
+}
+
+func classifyError(err error) (int, string) {
+	switch {
+	case errors.Is(err, errUsage):
+		return ExitUsage, "usage"
+	case errors.Is(err, errNotConfigured),
+		errors.Is(err, errConfigStore),
+		errors.Is(err, lifecycle.ErrSaveGamePathRequired),
+		errors.Is(err, lifecycle.ErrProfilesPathRequired):
+		return ExitNotConfigured, "not_configured"
+	case errors.Is(err, gameproc.ErrGameRunning):
+		return ExitGameRunning, "game_running"
+	case errors.Is(err, savelock.ErrBusy):
+		return ExitBusy, "busy"
+	case errors.Is(err, lifecycle.ErrProfileNotFound),
+		errors.Is(err, snapshot.ErrSnapshotNotFound),
+		errors.Is(err, backups.ErrBackupNotFound),
+		errors.Is(err, signing.ErrNoSigningKey),
+		errors.Is(err, errNotFound),
+		errors.Is(err, profiles.ErrInvalidProfileLayout),
+		errors.Is(err, os.ErrNotExist):
+		return ExitNotFound, "not_found"
+	case errors.Is(err, bundle.ErrUnsupportedFormatVersion),
+		errors.Is(err, bundle.ErrManifestInvalid),
+		errors.Is(err, bundle.ErrBundleChecksumMismatch),
+		errors.Is(err, bundle.ErrBundleEntryNotInManifest),
+		errors.Is(err, bundle.ErrBundleEntryMissingContent),
+		errors.Is(err, bundle.ErrBundleCorrupted),
+		errors.Is(err, bundle.ErrSignatureInvalid),
+		errors.Is(err, bundle.ErrSignatureWithoutPayload),
+		errors.Is(err, bundle.ErrDuplicateBundleEntry):
+		return ExitFailure, "invalid_bundle"
+	case errors.Is(err, bundle.ErrPassphraseRequired):
+		return ExitUsage, "passphrase_required"
+	case errors.Is(err, bundle.ErrWrongPassphrase):
+		return ExitFailure, "wrong_passphrase"
+	case errors.Is(err, signing.ErrSigningKeyExists),
+		errors.Is(err, lifecycle.ErrProfileAlreadyExists),
+		errors.Is(err, bundle.ErrProfileExists),
+		errors.Is(err, lifecycle.ErrCannotDeleteActiveProfile),
+		errors.Is(err, lifecycle.ErrFreshProfileNameConflict):
+		return ExitConflict, "conflict"
+	case errors.Is(err, lifecycle.ErrProfileNameRequired),
+		errors.Is(err, lifecycle.ErrProfileNameInvalid),
+		errors.Is(err, switcher.ErrProfileNameRequired),
+		errors.Is(err, switcher.ErrProfileNameInvalid),
+		errors.Is(err, marker.ErrProfileNameRequired),
+		errors.Is(err, signing.ErrAuthorRequired),
+		errors.Is(err, signing.ErrPublicKeyInvalid),
+		errors.Is(err, signing.ErrTrustedKeyNameEmpty),
+		errors.Is(err, bundle.ErrUnknownConflictPolicy):
+		return ExitUsage, "invalid_argument"
+	default:
+		return ExitFailure, "failed"
+	}
+}
+
+func writeJSON(w io.Writer, value interface{}) {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"heat-save-manager/internal/audit"
+	"heat-save-manager/internal/config"
+	"heat-save-manager/internal/gameproc"
+)
+
+type cliFixture struct {
+	saveGamePath string
+	store        *config.Store
+	detector     gameproc.Detector
+}
+
+func newCLIFixture(t *testing.T) cliFixture {
+	t.Helper()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "Need for speed heat", "SaveGame")
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "root-save")
+	writeFile(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "root-wrap")
+	writeFile(t, filepath.Join(saveGamePath, "Profiles", "ProfileAlpha", "savegame", "slot.sav"), "alpha-save")
+	writeFile(t, filepath.Join(saveGamePath, "Profiles", "ProfileAlpha", "wraps", "wrap.txt"), "alpha-wrap")
+	writeFile(t, filepath.Join(saveGamePath, "Profiles", "ProfileBeta", "savegame", "slot.sav"), "beta-save")
+	writeFile(t, filepath.Join(saveGamePath, "Profiles", "ProfileBeta", "wraps", "wrap.txt"), "beta-wrap")
+	writeFile(t, filepath.Join(saveGamePath, config.MarkerFileName), "ProfileAlpha")
+
+	store := config.NewStoreWithDir(filepath.Join(root, "config-root"))
+	cfg := config.Default()
+	cfg.SaveGamePath = saveGamePath
+	cfg.ProfilesPath = filepath.Join(saveGamePath, "Profiles")
+	if err := store.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	return cliFixture{
+		saveGamePath: saveGamePath,
+		store:        store,
+		detector:     gameproc.NewProcDetector(filepath.Join(root, "proc")),
+	}
+}
+
+func (f cliFixture) run(t *testing.T, args ...string) (int, response) {
+	t.Helper()
+
+	var stdout bytes.Buffer
+	code := Run(args, Environment{
+		Stdout:       &stdout,
+		Stderr:       &bytes.Buffer{},
+		ConfigStore:  f.store,
+		GameDetector: f.detector,
+		Version:      "test",
+	})
+
+	var decoded response
+	if err := json.Unmarshal(stdout.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode output %q: %v", stdout.String(), err)
+	}
+
+	return code, decoded
+}
+
+func TestListReportsActiveProfile(t *testing.T) {
+	t.Parallel()
+
+	fixture := newCLIFixture(t)
+	code, out := fixture.run(t, "list")
+	if code != ExitOK || !out.OK {
+		t.Fatalf("unexpected list result: code=%d out=%+v", code, out)
+	}
+
+	entries, ok := out.Result.([]interface{})
+	if !ok || len(entries) != 2 {
+		t.Fatalf("expected two profiles, got %+v", out.Result)
+	}
+
+	first := entries[0].(map[string]interface{})
+	if first["name"] != "ProfileAlpha" || first["active"] != true {
+		t.Fatalf("expected ProfileAlpha to be active, got %+v", first)
+	}
+}
+
+func TestSwitchAcceptsFlagsAfterProfileName(t *testing.T) {
+	t.Parallel()
+
+	fixture := newCLIFixture(t)
+	code, out := fixture.run(t, "switch", "ProfileBeta", "--no-save")
+	if code != ExitOK || !out.OK {
+		t.Fatalf("unexpected switch result: code=%d out=%+v", code, out)
+	}
+
+	assertFileContent(t, filepath.Join(fixture.saveGamePath, "savegame", "slot.sav"), "beta-save")
+	assertFileContent(t, filepath.Join(fixture.saveGamePath, "Profiles", "ProfileAlpha", "savegame", "slot.sav"), "alpha-save")
+}
+
+func TestMutatingCommandsWriteTheAuditLog(t *testing.T) {
+	t.Parallel()
+
+	fixture := newCLIFixture(t)
+	if code, out := fixture.run(t, "switch", "ProfileBeta", "--no-save"); code != ExitOK {
+		t.Fatalf("unexpected switch result: code=%d out=%+v", code, out)
+	}
+
+	if code, out := fixture.run(t, "rename", "Missing", "Other"); code == ExitOK {
+		t.Fatalf("expected rename of a missing profile to fail, got %+v", out)
+	}
+
+	log := audit.New(fixture.store.Dir())
+	for _, want := range []audit.Entry{
+		{Operation: "switch", Target: "ProfileBeta", Outcome: audit.OutcomeSucceeded},
+		{Operation: "rename", Target: "Missing", Outcome: audit.OutcomeFailed},
+	} {
+		page, err := log.Query(audit.Filter{Operation: want.Operation})
+		if err != nil {
+			t.Fatalf("read audit log: %v", err)
+		}
+
+		if page.Total != 1 {
+			t.Fatalf("expected one %s entry, got %+v", want.Operation, page)
+		}
+
+		got := page.Entries[0]
+		if got.Target != want.Target || got.Outcome != want.Outcome || len(got.Paths) == 0 {
+			t.Fatalf("unexpected %s entry: %+v", want.Operation, got)
+		}
+	}
+}
+
+func TestSwitchRefusesWhileGameRunningUnlessForced(t *testing.T) {
+	t.Parallel()
+
+	fixture := newCLIFixture(t)
+	procRoot := t.TempDir()
+	writeFile(t, filepath.Join(procRoot, "4242", "cmdline"), `C:\Games\NeedForSpeedHeat.exe`+"\x00")
+	fixture.detector = gameproc.NewProcDetector(procRoot)
+
+	code, out := fixture.run(t, "switch", "ProfileBeta")
+	if code != ExitGameRunning || out.OK || out.Error == nil || out.Error.Code != "game_running" {
+		t.Fatalf("expected game_running failure, got code=%d out=%+v", code, out)
+	}
+
+	code, out = fixture.run(t, "switch", "--force", "ProfileBeta")
+	if code != ExitOK || !out.OK {
+		t.Fatalf("expected forced switch to succeed, got code=%d out=%+v", code, out)
+	}
+}
+
+func TestExitCodesForCommonFailures(t *testing.T) {
+	t.Parallel()
+
+	fixture := newCLIFixture(t)
+
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{args: []string{}, code: ExitUsage},
+		{args: []string{"unknown"}, code: ExitUsage},
+		{args: []string{"switch"}, code: ExitUsage},
+		{args: []string{"switch", "Missing"}, code: ExitNotFound},
+		{args: []string{"rename", "ProfileAlpha", "ProfileBeta"}, code: ExitConflict},
+		{args: []string{"delete", "ProfileAlpha"}, code: ExitConflict},
+		{args: []string{"--savegame", t.TempDir(), "list"}, code: ExitNotConfigured},
+	}
+
+	for _, tc := range cases {
+		code, out := fixture.run(t, tc.args...)
+		if code != tc.code || out.OK {
+			t.Fatalf("args %v: expected exit %d, got %d (%+v)", tc.args, tc.code, code, out)
+		}
+	}
+}
+
+func TestConfigSetAndGet(t *testing.T) {
+	t.Parallel()
+
+	fixture := newCLIFixture(t)
+	if code, out := fixture.run(t, "config", "set", "saveBeforeSwitch", "false"); code != ExitOK {
+		t.Fatalf("set saveBeforeSwitch: code=%d out=%+v", code, out)
+	}
+
+	if code, out := fixture.run(t, "config", "set", "language", "fr"); code == ExitOK {
+		t.Fatalf("expected unsupported language to fail, got %+v", out)
+	}
+
+	if code, out := fixture.run(t, "config", "set", "switchBackupMaxCount", "-1"); code != ExitUsage {
+		t.Fatalf("expected usage error for negative count, got code=%d out=%+v", code, out)
+	}
+
+	code, out := fixture.run(t, "config", "get", "saveBeforeSwitch")
+	if code != ExitOK {
+		t.Fatalf("get saveBeforeSwitch: code=%d out=%+v", code, out)
+	}
+
+	values := out.Result.(map[string]interface{})
+	if values["saveBeforeSwitch"] != false {
+		t.Fatalf("expected saveBeforeSwitch=false, got %+v", values)
+	}
+
+	loaded, err := fixture.store.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	if loaded.SaveBeforeSwitch || loaded.SaveGamePath != fixture.saveGamePath {
+		t.Fatalf("unexpected persisted config: %+v", loaded)
+	}
+}
+
+func TestExportAndImportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	fixture := newCLIFixture(t)
+	bundlePath := filepath.Join(t.TempDir(), "alpha.zip")
+
+	if code, out := fixture.run(t, "export", "ProfileAlpha", bundlePath); code != ExitOK {
+		t.Fatalf("export: code=%d out=%+v", code, out)
+	}
+
+	code, out := fixture.run(t, "inspect", bundlePath)
+	preview, ok := out.Result.(map[string]interface{})
+	if code != ExitOK || !ok || preview["canImport"] != true {
+		t.Fatalf("inspect: code=%d out=%+v", code, out)
+	}
+
+	if code, out := fixture.run(t, "import", "ProfileGamma", bundlePath); code != ExitOK {
+		t.Fatalf("import: code=%d out=%+v", code, out)
+	}
+
+	assertFileContent(t, filepath.Join(fixture.saveGamePath, "Profiles", "ProfileGamma", "savegame", "slot.sav"), "alpha-save")
+
+	if code, out := fixture.run(t, "import", "ProfileGamma", bundlePath); code != ExitConflict {
+		t.Fatalf("expected an existing profile to conflict: code=%d out=%+v", code, out)
+	}
+
+	code, out = fixture.run(t, "import", "--on-conflict", "rename", "ProfileGamma", bundlePath)
+	imported, ok := out.Result.(map[string]interface{})
+	if code != ExitOK || !ok || imported["profileName"] != "ProfileGamma (2)" {
+		t.Fatalf("import with rename: code=%d out=%+v", code, out)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func assertFileContent(t *testing.T, path string, expected string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(data) != expected {
+		t.Fatalf("expected %q, got %q", expected, string(data))
+	}
+}
@@ -1,7 +1,12 @@
 package config
 
+import "time"
+
 const MarkerFileName = "active_profile.txt"
 const DefaultLanguage = "en"
+const DefaultSwitchBackupMaxCount = 10
+const DefaultSwitchBackupMaxAgeDays = 30
+const DefaultCopyWorkers = 4
 
 type AppConfig struct {
 	SaveGamePath       string `json:"saveGamePath"`
@@ -9,6 +14,22 @@ type AppConfig struct {
 	Language           string `json:"language"`
 	BackupBeforeSwitch bool   `json:"backupBeforeSwitch"`
 	CheckGameRunning   bool   `json:"checkGameRunning"`
+	SaveBeforeSwitch   bool   `json:"saveBeforeSwitch"`
+	SyncCompareContent bool   `json:"syncCompareContent"`
+
+	SwitchBackupMaxCount   int `json:"switchBackupMaxCount"`
+	SwitchBackupMaxAgeDays int `json:"switchBackupMaxAgeDays"`
+	CopyWorkers            int `json:"copyWorkers"`
+
+	TrustedKeys []TrustedKey `json:"trustedKeys"`
+}
+
+// TrustedKey is a bundle author's ed25519 public key (base64) that the user
+// has chosen to trust.
+type TrustedKey struct {
+	Name      string    `json:"name"`
+	PublicKey string    `json:"publicKey"`
+	AddedAt   time.Time `json:"addedAt"`
 }
 
 func Default() AppConfig {
@@ -16,5 +37,10 @@ func Default() AppConfig {
 		Language:           DefaultLanguage,
 		BackupBeforeSwitch: true,
 		CheckGameRunning:   true,
+		SaveBeforeSwitch:   true,
+
+		SwitchBackupMaxCount:   DefaultSwitchBackupMaxCount,
+		SwitchBackupMaxAgeDays: DefaultSwitchBackupMaxAgeDays,
+		CopyWorkers:            DefaultCopyWorkers,
 	}
 }
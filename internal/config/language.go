@@ -0,0 +1,34 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	LanguageEnglish = "en"
+	LanguageSpanish = "es"
+)
+
+func NormalizeLanguage(language string) string {
+	switch strings.ToLower(strings.TrimSpace(language)) {
+	case LanguageSpanish, "es-es", "es-419", "es-mx":
+		return LanguageSpanish
+	default:
+		return LanguageEnglish
+	}
+}
+
+func ValidateLanguage(language string) (string, error) {
+	trimmed := strings.ToLower(strings.TrimSpace(language))
+	switch trimmed {
+	case LanguageEnglish, "en-us", "en-gb":
+		return LanguageEnglish, nil
+	case LanguageSpanish, "es-es", "es-419", "es-mx":
+		return LanguageSpanish, nil
+	case "":
+		return "", errors.New("language is required")
+	default:
+		return "", errors.New("unsupported language")
+	}
+}
@@ -31,6 +31,11 @@ func NewStoreWithDir(configDir string) *Store {
 	return &Store{configDir: configDir}
 }
 
+// Dir is the directory holding the config file and other per-user app data.
+func (s *Store) Dir() string {
+	return s.configDir
+}
+
 func (s *Store) Path() string {
 	return filepath.Join(s.configDir, FileName)
 }
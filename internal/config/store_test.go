@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -50,7 +51,7 @@ func TestSaveAndLoadRoundTrip(t *testing.T) {
 		t.Fatalf("load config: %v", err)
 	}
 
-	if loaded != wanted {
+	if !reflect.DeepEqual(loaded, wanted) {
 		t.Fatalf("expected %+v, got %+v", wanted, loaded)
 	}
 }
@@ -100,4 +101,8 @@ func TestLoadAppliesDefaultsForMissingFields(t *testing.T) {
 	if !cfg.CheckGameRunning {
 		t.Fatal("expected CheckGameRunning default true")
 	}
+
+	if !cfg.SaveBeforeSwitch {
+		t.Fatal("expected SaveBeforeSwitch default true")
+	}
 }
@@ -0,0 +1,48 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ValidateSaveGamePath checks that saveGamePath is an existing SaveGame folder
+// inside the Need for Speed Heat documents folder and returns the derived paths.
+func ValidateSaveGamePath(saveGamePath string) (Paths, error) {
+	trimmed := strings.TrimSpace(saveGamePath)
+	if trimmed == "" {
+		return Paths{}, errors.New("savegame path is required")
+	}
+
+	if !filepath.IsAbs(trimmed) {
+		return Paths{}, errors.New("savegame path must be absolute")
+	}
+
+	info, err := os.Stat(trimmed)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Paths{}, errors.New("savegame path does not exist")
+		}
+		return Paths{}, fmt.Errorf("read savegame path: %w", err)
+	}
+
+	if !info.IsDir() {
+		return Paths{}, errors.New("savegame path must be a directory")
+	}
+
+	if !strings.EqualFold(filepath.Base(trimmed), "SaveGame") {
+		return Paths{}, errors.New("path must point to the SaveGame folder")
+	}
+
+	parentDirName := filepath.Base(filepath.Dir(trimmed))
+	if !strings.EqualFold(parentDirName, "Need for speed heat") {
+		return Paths{}, errors.New("savegame path must be inside the Need for Speed Heat folder")
+	}
+
+	return Paths{
+		SaveGamePath: trimmed,
+		ProfilesPath: filepath.Join(trimmed, "Profiles"),
+	}, nil
+}
@@ -1,29 +1,51 @@
 package fsops
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
-var ErrSourceMustBeDirectory = fmt.Errorf("source must be a directory")
+var (
+	ErrSourceMustBeDirectory = fmt.Errorf("source must be a directory")
+	ErrDestinationExists     = fmt.Errorf("destination already exists")
+)
 
+// Operations copy, move and replace directory trees. Copies stop with
+// ctx.Err() once ctx is cancelled and report their work to the Progress
+// attached to ctx, if any. SyncDir is ReplaceDir that only writes the files
+// that changed since reference. RemoveDir is not cancellable so cleanup always
+// finishes. The scratch folders of ReplaceDir, SyncDir and MoveDir are
+// reported to the staging hook on ctx, if any.
 type Operations interface {
-	CopyDir(source string, destination string) error
-	ReplaceDir(source string, destination string) error
+	CopyDir(ctx context.Context, source string, destination string) error
+	ReplaceDir(ctx context.Context, source string, destination string) error
+	MoveDir(ctx context.Context, source string, destination string) error
+	SyncDir(ctx context.Context, source string, destination string, reference string) error
 	RemoveDir(path string) error
 }
 
-type Local struct{}
+type Local struct {
+	compareContent bool
+	workers        int
+}
 
+// NewLocal copies one file at a time.
 func NewLocal() *Local {
 	return &Local{}
 }
 
-func (l *Local) CopyDir(source string, destination string) error {
+// NewParallelLocal copies up to workers files at once, which pays off for
+// trees of many small files on fast disks. Fewer than two workers copy
+// sequentially, like NewLocal.
+func NewParallelLocal(workers int) *Local {
+	return &Local{workers: workers}
+}
+
+func (l *Local) CopyDir(ctx context.Context, source string, destination string) error {
 	info, err := os.Stat(source)
 	if err != nil {
 		return err
@@ -37,11 +59,31 @@ func (l *Local) CopyDir(source string, destination string) error {
 		return err
 	}
 
+	return l.copyTree(ctx, source, destination, func(ctx context.Context, path string, targetPath string, info os.FileInfo) error {
+		if err := copyFile(ctx, path, targetPath, info.Mode().Perm()); err != nil {
+			return err
+		}
+
+		Advance(ctx, 1, 0)
+		return nil
+	})
+}
+
+// copyFunc writes the regular file at path to targetPath.
+type copyFunc func(ctx context.Context, path string, targetPath string, info os.FileInfo) error
+
+// walkTree recreates the directories below source under destination in walk
+// order and hands every regular file to visit. Symlinks are rejected.
+func walkTree(ctx context.Context, source string, destination string, visit func(path string, targetPath string, info os.FileInfo) error) error {
 	return filepath.WalkDir(source, func(path string, d os.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		relPath, err := filepath.Rel(source, path)
 		if err != nil {
 			return err
@@ -65,15 +107,11 @@ func (l *Local) CopyDir(source string, destination string) error {
 			return fmt.Errorf("symlinks are not supported: %s", path)
 		}
 
-		if err := copyFile(path, targetPath, entryInfo.Mode().Perm()); err != nil {
-			return err
-		}
-
-		return nil
+		return visit(path, targetPath, entryInfo)
 	})
 }
 
-func (l *Local) ReplaceDir(source string, destination string) error {
+func (l *Local) ReplaceDir(ctx context.Context, source string, destination string) error {
 	parentDir := filepath.Dir(destination)
 	if err := os.MkdirAll(parentDir, 0o755); err != nil {
 		return err
@@ -82,14 +120,27 @@ func (l *Local) ReplaceDir(source string, destination string) error {
 	timestampToken := strings.ReplaceAll(time.Now().UTC().Format("20060102-150405.000000000"), ".", "")
 	tmpDir := destination + ".replace-" + timestampToken
 	backupDir := destination + ".backup-" + timestampToken
+	if err := announceStaging(ctx, Staging{Destination: destination, Staging: tmpDir, Parked: backupDir}); err != nil {
+		return err
+	}
+
 	if err := os.RemoveAll(tmpDir); err != nil {
 		return err
 	}
-	if err := os.RemoveAll(backupDir); err != nil {
+
+	if err := l.CopyDir(ctx, source, tmpDir); err != nil {
+		_ = os.RemoveAll(tmpDir)
 		return err
 	}
 
-	if err := l.CopyDir(source, tmpDir); err != nil {
+	return swapInto(tmpDir, destination, backupDir)
+}
+
+// swapInto renames the fully built tmpDir over destination, keeping the old
+// destination in backupDir until the rename succeeds so it can be put back.
+func swapInto(tmpDir string, destination string, backupDir string) error {
+	if err := os.RemoveAll(backupDir); err != nil {
+		_ = os.RemoveAll(tmpDir)
 		return err
 	}
 
@@ -126,11 +177,56 @@ func (l *Local) ReplaceDir(source string, destination string) error {
 	return nil
 }
 
+// MoveDir moves source to destination, which must not exist yet. On the same
+// volume this is a single rename; across volumes the tree is copied next to
+// destination, renamed into place, and only then removed from source.
+func (l *Local) MoveDir(ctx context.Context, source string, destination string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(destination); err == nil {
+		return fmt.Errorf("%w: %s", ErrDestinationExists, destination)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+		return err
+	}
+
+	err := os.Rename(source, destination)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+
+	if err := Plan(ctx, source); err != nil {
+		return err
+	}
+
+	tmpDir := destination + ".move-" + strings.ReplaceAll(time.Now().UTC().Format("20060102-150405.000000000"), ".", "")
+	if err := announceStaging(ctx, Staging{Destination: destination, Staging: tmpDir}); err != nil {
+		return err
+	}
+
+	if err := l.CopyDir(ctx, source, tmpDir); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return err
+	}
+
+	if err := os.Rename(tmpDir, destination); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return err
+	}
+
+	return os.RemoveAll(source)
+}
+
 func (l *Local) RemoveDir(path string) error {
 	return os.RemoveAll(path)
 }
 
-func copyFile(source string, destination string, mode os.FileMode) error {
+func copyFile(ctx context.Context, source string, destination string, mode os.FileMode) error {
 	in, err := os.Open(source)
 	if err != nil {
 		return err
@@ -141,15 +237,31 @@ func copyFile(source string, destination string, mode os.FileMode) error {
 		return err
 	}
 
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
 	out, err := os.OpenFile(destination, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
 
-	if _, err := io.Copy(out, in); err != nil {
+	if _, err := Copy(ctx, out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
 		return err
 	}
 
-	return out.Sync()
+	// Keeping the modification time lets SyncDir and the fingerprint index
+	// recognise unchanged copies without reading them.
+	return os.Chtimes(destination, info.ModTime(), info.ModTime())
 }
@@ -1,10 +1,13 @@
 package fsops
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestCopyDirCopiesNestedFiles(t *testing.T) {
@@ -18,7 +21,7 @@ func TestCopyDirCopiesNestedFiles(t *testing.T) {
 	createFile(t, filepath.Join(source, "wraps", "car-wrap.txt"), "wrap-content")
 
 	ops := NewLocal()
-	if err := ops.CopyDir(source, destination); err != nil {
+	if err := ops.CopyDir(context.Background(), source, destination); err != nil {
 		t.Fatalf("copy dir: %v", err)
 	}
 
@@ -34,7 +37,7 @@ func TestCopyDirRequiresDirectorySource(t *testing.T) {
 	createFile(t, sourceFile, "not-a-dir")
 
 	ops := NewLocal()
-	err := ops.CopyDir(sourceFile, filepath.Join(root, "destination"))
+	err := ops.CopyDir(context.Background(), sourceFile, filepath.Join(root, "destination"))
 	if !errors.Is(err, ErrSourceMustBeDirectory) {
 		t.Fatalf("expected ErrSourceMustBeDirectory, got %v", err)
 	}
@@ -47,7 +50,7 @@ func TestCopyDirReturnsNotExistForMissingSource(t *testing.T) {
 	missingSource := filepath.Join(root, "does-not-exist")
 
 	ops := NewLocal()
-	err := ops.CopyDir(missingSource, filepath.Join(root, "destination"))
+	err := ops.CopyDir(context.Background(), missingSource, filepath.Join(root, "destination"))
 	if !os.IsNotExist(err) {
 		t.Fatalf("expected os.IsNotExist error, got %v", err)
 	}
@@ -65,7 +68,7 @@ func TestReplaceDirReplacesDestinationContents(t *testing.T) {
 	createFile(t, filepath.Join(destination, "savegame", "old.sav"), "old")
 
 	ops := NewLocal()
-	if err := ops.ReplaceDir(source, destination); err != nil {
+	if err := ops.ReplaceDir(context.Background(), source, destination); err != nil {
 		t.Fatalf("replace dir: %v", err)
 	}
 
@@ -88,7 +91,7 @@ func TestReplaceDirCreatesDestinationPathWhenMissing(t *testing.T) {
 	createFile(t, filepath.Join(source, "savegame", "new.sav"), "new")
 
 	ops := NewLocal()
-	if err := ops.ReplaceDir(source, destination); err != nil {
+	if err := ops.ReplaceDir(context.Background(), source, destination); err != nil {
 		t.Fatalf("replace dir: %v", err)
 	}
 
@@ -105,7 +108,7 @@ func TestReplaceDirKeepsDestinationWhenSourceIsMissing(t *testing.T) {
 	createFile(t, filepath.Join(destination, "savegame", "old.sav"), "old")
 
 	ops := NewLocal()
-	err := ops.ReplaceDir(source, destination)
+	err := ops.ReplaceDir(context.Background(), source, destination)
 	if !os.IsNotExist(err) {
 		t.Fatalf("expected not-exist error, got %v", err)
 	}
@@ -143,6 +146,303 @@ func TestRemoveDirOnMissingPathDoesNotFail(t *testing.T) {
 	}
 }
 
+func TestMoveDirMovesTreeAndRefusesExistingDestination(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	source := filepath.Join(root, "source")
+	destination := filepath.Join(root, "backup", "savegame")
+	createFile(t, filepath.Join(source, "nested", "slot.sav"), "save")
+
+	ops := NewLocal()
+	if err := ops.MoveDir(context.Background(), source, destination); err != nil {
+		t.Fatalf("move dir: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(destination, "nested", "slot.sav"), "save")
+	if _, err := os.Stat(source); !os.IsNotExist(err) {
+		t.Fatalf("expected source to be gone, got err=%v", err)
+	}
+
+	createFile(t, filepath.Join(source, "slot.sav"), "other")
+	if err := ops.MoveDir(context.Background(), source, destination); !errors.Is(err, ErrDestinationExists) {
+		t.Fatalf("expected ErrDestinationExists, got %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(source, "slot.sav"), "other")
+}
+
+func TestCopyDirReportsProgressAgainstPlannedTotals(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	source := filepath.Join(root, "source")
+	createFile(t, filepath.Join(source, "a.txt"), "alpha")
+	createFile(t, filepath.Join(source, "nested", "b.txt"), "beta")
+
+	ctx, tracker := WithProgress(context.Background(), nil)
+	if err := Plan(ctx, source, filepath.Join(root, "missing")); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+
+	if err := NewLocal().CopyDir(ctx, source, filepath.Join(root, "destination")); err != nil {
+		t.Fatalf("copy dir: %v", err)
+	}
+
+	progress := tracker.Progress()
+	expected := Progress{FilesDone: 2, FilesTotal: 2, BytesDone: 9, BytesTotal: 9}
+	if progress != expected {
+		t.Fatalf("expected %+v, got %+v", expected, progress)
+	}
+}
+
+func TestReplaceDirKeepsDestinationWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	source := filepath.Join(root, "source")
+	destination := filepath.Join(root, "destination")
+	createFile(t, filepath.Join(source, "slot.sav"), "new")
+	createFile(t, filepath.Join(destination, "slot.sav"), "old")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewLocal().ReplaceDir(ctx, source, destination)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(destination, "slot.sav"), "old")
+}
+
+func TestSyncDirWritesOnlyChangedFiles(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	source := filepath.Join(root, "source")
+	destination := filepath.Join(root, "destination")
+	createFile(t, filepath.Join(source, "same.sav"), "same")
+	createFile(t, filepath.Join(source, "nested", "changed.sav"), "old")
+	createFile(t, filepath.Join(source, "removed.sav"), "gone")
+
+	ops := NewLocal()
+	if err := ops.ReplaceDir(context.Background(), source, destination); err != nil {
+		t.Fatalf("replace dir: %v", err)
+	}
+
+	sourceInfo := statFile(t, filepath.Join(source, "same.sav"))
+	if !statFile(t, filepath.Join(destination, "same.sav")).ModTime().Equal(sourceInfo.ModTime()) {
+		t.Fatal("expected copies to keep the modification time")
+	}
+
+	before := statFile(t, filepath.Join(destination, "same.sav"))
+	createFile(t, filepath.Join(source, "nested", "changed.sav"), "new")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(source, "nested", "changed.sav"), later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if err := os.Remove(filepath.Join(source, "removed.sav")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	ctx, tracker := WithProgress(context.Background(), nil)
+	if err := Plan(ctx, source); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+
+	if err := ops.SyncDir(ctx, source, destination, ""); err != nil {
+		t.Fatalf("sync dir: %v", err)
+	}
+
+	if !os.SameFile(before, statFile(t, filepath.Join(destination, "same.sav"))) {
+		t.Fatal("expected the unchanged file to be reused instead of copied")
+	}
+
+	assertFileContent(t, filepath.Join(destination, "nested", "changed.sav"), "new")
+	if _, err := os.Stat(filepath.Join(destination, "removed.sav")); !os.IsNotExist(err) {
+		t.Fatalf("expected removed file to be gone, got err=%v", err)
+	}
+
+	expected := Progress{FilesDone: 2, FilesTotal: 2, BytesDone: 7, BytesTotal: 7}
+	if progress := tracker.Progress(); progress != expected {
+		t.Fatalf("expected %+v, got %+v", expected, progress)
+	}
+
+	// Same size and time but different content is only caught by hashing.
+	createFile(t, filepath.Join(source, "same.sav"), "SAME")
+	if err := os.Chtimes(filepath.Join(source, "same.sav"), sourceInfo.ModTime(), sourceInfo.ModTime()); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	if err := ops.SyncDir(context.Background(), source, destination, ""); err != nil {
+		t.Fatalf("sync dir: %v", err)
+	}
+	assertFileContent(t, filepath.Join(destination, "same.sav"), "same")
+
+	ops.SetCompareContent(true)
+	if err := ops.SyncDir(context.Background(), source, destination, ""); err != nil {
+		t.Fatalf("sync dir comparing content: %v", err)
+	}
+	assertFileContent(t, filepath.Join(destination, "same.sav"), "SAME")
+}
+
+func TestSyncDirAnnouncesStagingBeforeWriting(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	source := filepath.Join(root, "source")
+	destination := filepath.Join(root, "destination")
+	createFile(t, filepath.Join(source, "slot.sav"), "new")
+	createFile(t, filepath.Join(destination, "slot.sav"), "old")
+
+	var announced []Staging
+	ctx := WithStagingHook(context.Background(), func(staging Staging) error {
+		if _, err := os.Stat(staging.Staging); !os.IsNotExist(err) {
+			t.Errorf("expected staging folder not created yet, got %v", err)
+		}
+		announced = append(announced, staging)
+		return nil
+	})
+
+	if err := NewLocal().SyncDir(ctx, source, destination, ""); err != nil {
+		t.Fatalf("sync dir: %v", err)
+	}
+
+	if len(announced) != 1 || announced[0].Destination != destination || announced[0].Staging == "" || announced[0].Parked == "" {
+		t.Fatalf("expected the staging and parked folders to be announced, got %+v", announced)
+	}
+
+	refused := errors.New("journal unavailable")
+	ctx = WithStagingHook(context.Background(), func(Staging) error { return refused })
+	if err := NewLocal().ReplaceDir(ctx, source, destination); !errors.Is(err, refused) {
+		t.Fatalf("expected the hook error, got %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(destination, "slot.sav"), "new")
+}
+
+func TestRecoverStagingPutsParkedDestinationBack(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	staging := Staging{
+		Destination: filepath.Join(root, "savegame"),
+		Staging:     filepath.Join(root, "savegame.sync-1"),
+		Parked:      filepath.Join(root, "savegame.backup-1"),
+	}
+
+	// Interrupted between parking the destination and renaming the staged
+	// tree onto it.
+	createFile(t, filepath.Join(staging.Staging, "slot.sav"), "half-written")
+	createFile(t, filepath.Join(staging.Parked, "slot.sav"), "original")
+
+	if err := RecoverStaging(staging); err != nil {
+		t.Fatalf("recover staging: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(staging.Destination, "slot.sav"), "original")
+	for _, path := range []string{staging.Staging, staging.Parked} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed, got %v", path, err)
+		}
+	}
+
+	// Interrupted after the rename but before the parked copy was removed.
+	createFile(t, filepath.Join(staging.Parked, "slot.sav"), "original")
+	createFile(t, filepath.Join(staging.Destination, "slot.sav"), "replaced")
+	if err := RecoverStaging(staging); err != nil {
+		t.Fatalf("recover finished staging: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(staging.Destination, "slot.sav"), "replaced")
+	if _, err := os.Stat(staging.Parked); !os.IsNotExist(err) {
+		t.Fatalf("expected the parked copy removed, got %v", err)
+	}
+}
+
+func TestParallelCopyDirMatchesSequentialCopy(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	source := filepath.Join(root, "source")
+	for i := range 40 {
+		createFile(t, filepath.Join(source, "wraps", fmt.Sprintf("dir-%d", i%5), fmt.Sprintf("wrap-%02d.txt", i)), fmt.Sprintf("wrap-%d", i))
+	}
+	if err := os.Chmod(filepath.Join(source, "wraps", "dir-0", "wrap-00.txt"), 0o600); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+
+	ctx, tracker := WithProgress(context.Background(), nil)
+	if err := Plan(ctx, source); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+
+	destination := filepath.Join(root, "destination")
+	if err := NewParallelLocal(4).CopyDir(ctx, source, destination); err != nil {
+		t.Fatalf("parallel copy dir: %v", err)
+	}
+
+	for i := range 40 {
+		assertFileContent(t, filepath.Join(destination, "wraps", fmt.Sprintf("dir-%d", i%5), fmt.Sprintf("wrap-%02d.txt", i)), fmt.Sprintf("wrap-%d", i))
+	}
+
+	if mode := statFile(t, filepath.Join(destination, "wraps", "dir-0", "wrap-00.txt")).Mode().Perm(); mode != 0o600 {
+		t.Fatalf("expected permissions to be kept, got %v", mode)
+	}
+
+	if progress := tracker.Progress(); progress.FilesDone != 40 || progress.BytesDone != progress.BytesTotal {
+		t.Fatalf("expected every file to be reported, got %+v", progress)
+	}
+
+	if err := os.Symlink(filepath.Join(source, "wraps"), filepath.Join(source, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	err := NewParallelLocal(4).CopyDir(context.Background(), source, filepath.Join(root, "with-link"))
+	if err == nil {
+		t.Fatal("expected symlinks to be rejected")
+	}
+}
+
+func TestParallelCopyReportsEarliestFailureInWalkOrder(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	source := filepath.Join(root, "source")
+	for _, name := range []string{"a", "b", "c", "d"} {
+		createFile(t, filepath.Join(source, name+".sav"), name)
+	}
+
+	slowFailure := errors.New("b failed")
+	fastFailure := errors.New("d failed")
+	err := NewParallelLocal(4).copyTree(context.Background(), source, filepath.Join(root, "destination"), func(ctx context.Context, path string, targetPath string, info os.FileInfo) error {
+		switch filepath.Base(path) {
+		case "b.sav":
+			time.Sleep(50 * time.Millisecond)
+			return slowFailure
+		case "d.sav":
+			return fastFailure
+		}
+		return nil
+	})
+
+	if !errors.Is(err, slowFailure) {
+		t.Fatalf("expected the failure of the earlier file, got %v", err)
+	}
+}
+
+func statFile(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat %s: %v", path, err)
+	}
+
+	return info
+}
+
 func createFile(t *testing.T, path string, content string) {
 	t.Helper()
 
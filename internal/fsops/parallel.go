@@ -0,0 +1,131 @@
+package fsops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+)
+
+// copyTree walks source into destination and runs copyOne for every file,
+// inline or on the worker pool depending on how l was built.
+func (l *Local) copyTree(ctx context.Context, source string, destination string, copyOne copyFunc) error {
+	if l.workers < 2 {
+		return walkTree(ctx, source, destination, func(path string, targetPath string, info os.FileInfo) error {
+			return copyOne(ctx, path, targetPath, info)
+		})
+	}
+
+	pool := newFilePool(ctx, l.workers)
+	err := walkTree(pool.ctx, source, destination, func(path string, targetPath string, info os.FileInfo) error {
+		return pool.submit(func(ctx context.Context) error {
+			return copyOne(ctx, path, targetPath, info)
+		})
+	})
+	return pool.wait(err)
+}
+
+// filePool runs file copies on a fixed number of goroutines. The first
+// failure stops the remaining work, and the error reported is the one of the
+// earliest file in walk order, so the outcome does not depend on scheduling.
+type filePool struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	jobs   chan fileJob
+	wg     sync.WaitGroup
+
+	next int
+
+	mu       sync.Mutex
+	err      error
+	errIndex int
+}
+
+type fileJob struct {
+	index int
+	run   func(ctx context.Context) error
+}
+
+func newFilePool(ctx context.Context, workers int) *filePool {
+	poolCtx, cancel := context.WithCancel(ctx)
+	pool := &filePool{
+		parent: ctx,
+		ctx:    poolCtx,
+		cancel: cancel,
+		jobs:   make(chan fileJob, workers),
+	}
+
+	for range workers {
+		pool.wg.Add(1)
+		go func() {
+			defer pool.wg.Done()
+			for job := range pool.jobs {
+				if pool.failedBefore(job.index) {
+					continue
+				}
+
+				if err := job.run(pool.ctx); err != nil {
+					pool.fail(job.index, err)
+				}
+			}
+		}()
+	}
+
+	return pool
+}
+
+// submit queues one copy, blocking while every worker is busy. It fails once
+// the pool has stopped so the walk ends early.
+func (p *filePool) submit(run func(ctx context.Context) error) error {
+	job := fileJob{index: p.next, run: run}
+	p.next++
+
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// fail records err for the file at index and stops the pool. Cancellations
+// caused by an earlier failure rather than by the caller are not errors of
+// their own.
+func (p *filePool) fail(index int, err error) {
+	if errors.Is(err, context.Canceled) && p.parent.Err() == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if p.err == nil || index < p.errIndex {
+		p.err = err
+		p.errIndex = index
+	}
+	p.mu.Unlock()
+
+	p.cancel()
+}
+
+// failedBefore reports whether a file earlier than index already failed, in
+// which case copying it cannot change the outcome.
+func (p *filePool) failedBefore(index int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err != nil && p.errIndex < index
+}
+
+// wait lets the queued copies finish and returns the earliest error, counting
+// walkErr as coming after every submitted file.
+func (p *filePool) wait(walkErr error) error {
+	if walkErr != nil {
+		p.fail(p.next, walkErr)
+	}
+
+	close(p.jobs)
+	p.wg.Wait()
+	p.cancel()
+
+	return p.err
+}
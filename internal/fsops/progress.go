@@ -0,0 +1,195 @@
+package fsops
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	progressEmitInterval = 150 * time.Millisecond
+	copyChunkSize        = 1 << 20
+)
+
+// Progress is the running total of one operation. Totals are planned up
+// front by the service running it; rollback copies can push the done counts
+// past them, in which case the totals are raised to match.
+type Progress struct {
+	FilesDone  int   `json:"filesDone"`
+	FilesTotal int   `json:"filesTotal"`
+	BytesDone  int64 `json:"bytesDone"`
+	BytesTotal int64 `json:"bytesTotal"`
+}
+
+// Tracker accumulates Progress for one operation and forwards it, throttled,
+// to a callback.
+type Tracker struct {
+	report func(Progress)
+	now    func() time.Time
+
+	mu       sync.Mutex
+	progress Progress
+	lastEmit time.Time
+}
+
+type trackerKey struct{}
+
+// WithProgress returns a context whose copies report to report. The final
+// state is only guaranteed to be delivered by Tracker.Flush.
+func WithProgress(ctx context.Context, report func(Progress)) (context.Context, *Tracker) {
+	tracker := &Tracker{report: report, now: time.Now}
+	return context.WithValue(ctx, trackerKey{}, tracker), tracker
+}
+
+func trackerFrom(ctx context.Context) *Tracker {
+	if ctx == nil {
+		return nil
+	}
+
+	tracker, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return tracker
+}
+
+// Plan adds the files and bytes under dirs to the totals of the Tracker on
+// ctx. Missing directories count as empty.
+func Plan(ctx context.Context, dirs ...string) error {
+	tracker := trackerFrom(ctx)
+	if tracker == nil {
+		return nil
+	}
+
+	for _, dir := range dirs {
+		files, bytes, err := measure(dir)
+		if err != nil {
+			return err
+		}
+
+		tracker.add(files, bytes, 0, 0)
+	}
+
+	return nil
+}
+
+// PlanTotals adds known totals, for work that is not a directory copy.
+func PlanTotals(ctx context.Context, files int, bytes int64) {
+	if tracker := trackerFrom(ctx); tracker != nil {
+		tracker.add(files, bytes, 0, 0)
+	}
+}
+
+// Advance records finished work on the Tracker on ctx.
+func Advance(ctx context.Context, files int, bytes int64) {
+	if tracker := trackerFrom(ctx); tracker != nil {
+		tracker.add(0, 0, files, bytes)
+	}
+}
+
+// Copy is io.Copy that stops between chunks once ctx is cancelled and
+// advances the byte count as it goes.
+func Copy(ctx context.Context, dst io.Writer, src io.Reader) (int64, error) {
+	buffer := make([]byte, copyChunkSize)
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+
+		n, readErr := src.Read(buffer)
+		if n > 0 {
+			w, writeErr := dst.Write(buffer[:n])
+			written += int64(w)
+			Advance(ctx, 0, int64(w))
+			if writeErr != nil {
+				return written, writeErr
+			}
+			if w != n {
+				return written, io.ErrShortWrite
+			}
+		}
+
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
+
+func (t *Tracker) Progress() Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.progress
+}
+
+// Flush delivers the current state regardless of throttling.
+func (t *Tracker) Flush() {
+	if t == nil || t.report == nil {
+		return
+	}
+
+	t.mu.Lock()
+	progress := t.progress
+	t.lastEmit = t.now()
+	t.mu.Unlock()
+
+	t.report(progress)
+}
+
+func (t *Tracker) add(filesTotal int, bytesTotal int64, filesDone int, bytesDone int64) {
+	t.mu.Lock()
+	t.progress.FilesTotal += filesTotal
+	t.progress.BytesTotal += bytesTotal
+	t.progress.FilesDone += filesDone
+	t.progress.BytesDone += bytesDone
+	if t.progress.FilesDone > t.progress.FilesTotal {
+		t.progress.FilesTotal = t.progress.FilesDone
+	}
+	if t.progress.BytesDone > t.progress.BytesTotal {
+		t.progress.BytesTotal = t.progress.BytesDone
+	}
+
+	now := t.now()
+	if t.report == nil || now.Sub(t.lastEmit) < progressEmitInterval {
+		t.mu.Unlock()
+		return
+	}
+
+	t.lastEmit = now
+	progress := t.progress
+	t.mu.Unlock()
+
+	t.report(progress)
+}
+
+func measure(dir string) (int, int64, error) {
+	files := 0
+	var bytes int64
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if os.IsNotExist(walkErr) && path == dir {
+				return filepath.SkipAll
+			}
+			return walkErr
+		}
+
+		if d.IsDir() || d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		files++
+		bytes += info.Size()
+		return nil
+	})
+
+	return files, bytes, err
+}
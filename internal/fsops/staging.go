@@ -0,0 +1,67 @@
+package fsops
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// Staging names the scratch folders of one ReplaceDir, SyncDir or MoveDir:
+// the tree is built in Staging and renamed onto Destination, and ReplaceDir
+// and SyncDir keep the previous Destination at Parked until the rename is
+// done.
+type Staging struct {
+	Destination string `json:"destination"`
+	Staging     string `json:"staging"`
+	Parked      string `json:"parked,omitempty"`
+}
+
+type stagingHookKey struct{}
+
+// WithStagingHook returns a context whose directory replacements hand their
+// scratch folders to hook before writing anything there, so the caller can
+// journal them for RecoverStaging. An error from hook stops the replacement
+// before it starts.
+func WithStagingHook(ctx context.Context, hook func(Staging) error) context.Context {
+	return context.WithValue(ctx, stagingHookKey{}, hook)
+}
+
+func announceStaging(ctx context.Context, staging Staging) error {
+	if ctx == nil {
+		return nil
+	}
+
+	hook, _ := ctx.Value(stagingHookKey{}).(func(Staging) error)
+	if hook == nil {
+		return nil
+	}
+
+	return hook(staging)
+}
+
+// RecoverStaging cleans up after a replacement that was interrupted: a
+// destination that was parked but never replaced is put back, a parked copy
+// that was already replaced is dropped, and the staging tree is removed.
+func RecoverStaging(staging Staging) error {
+	if strings.TrimSpace(staging.Parked) != "" {
+		if _, err := os.Stat(staging.Parked); err == nil {
+			if _, err := os.Stat(staging.Destination); os.IsNotExist(err) {
+				if err := os.Rename(staging.Parked, staging.Destination); err != nil {
+					return err
+				}
+			} else if err != nil {
+				return err
+			} else if err := os.RemoveAll(staging.Parked); err != nil {
+				return err
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if strings.TrimSpace(staging.Staging) == "" {
+		return nil
+	}
+
+	return os.RemoveAll(staging.Staging)
+}
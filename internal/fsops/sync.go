@@ -0,0 +1,130 @@
+package fsops
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SetCompareContent makes SyncDir hash a file and its previous copy before
+// reusing it, instead of trusting an unchanged size and modification time.
+func (l *Local) SetCompareContent(enabled bool) {
+	l.compareContent = enabled
+}
+
+// SyncDir makes destination an exact copy of source with the same staging
+// and rollback guarantees as ReplaceDir: the new tree is built next to
+// destination and swapped in only when complete. Files whose copy in
+// reference (an earlier copy of source, or destination itself when reference
+// is empty) still has the same size and modification time are hard-linked
+// from there instead of copied, so only changed files are written. Files
+// missing from source are left out, which removes them.
+func (l *Local) SyncDir(ctx context.Context, source string, destination string, reference string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return ErrSourceMustBeDirectory
+	}
+
+	if strings.TrimSpace(reference) == "" {
+		reference = destination
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+		return err
+	}
+
+	timestampToken := strings.ReplaceAll(time.Now().UTC().Format("20060102-150405.000000000"), ".", "")
+	tmpDir := destination + ".sync-" + timestampToken
+	backupDir := destination + ".backup-" + timestampToken
+	if err := announceStaging(ctx, Staging{Destination: destination, Staging: tmpDir, Parked: backupDir}); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+
+	if err := l.stageSync(ctx, source, tmpDir, reference, info.Mode().Perm()); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return err
+	}
+
+	return swapInto(tmpDir, destination, backupDir)
+}
+
+func (l *Local) stageSync(ctx context.Context, source string, tmpDir string, reference string, mode os.FileMode) error {
+	if err := os.MkdirAll(tmpDir, mode); err != nil {
+		return err
+	}
+
+	return l.copyTree(ctx, source, tmpDir, func(ctx context.Context, path string, targetPath string, info os.FileInfo) error {
+		relPath, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+
+		previous := filepath.Join(reference, relPath)
+		if l.unchanged(path, info, previous) && os.Link(previous, targetPath) == nil {
+			Advance(ctx, 1, info.Size())
+			return nil
+		}
+
+		if err := copyFile(ctx, path, targetPath, info.Mode().Perm()); err != nil {
+			return err
+		}
+
+		Advance(ctx, 1, 0)
+		return nil
+	})
+}
+
+func (l *Local) unchanged(path string, info os.FileInfo, previous string) bool {
+	previousInfo, err := os.Stat(previous)
+	if err != nil || !previousInfo.Mode().IsRegular() {
+		return false
+	}
+
+	if previousInfo.Size() != info.Size() || !previousInfo.ModTime().Equal(info.ModTime()) {
+		return false
+	}
+
+	if !l.compareContent {
+		return true
+	}
+
+	left, err := fileSHA256(path)
+	if err != nil {
+		return false
+	}
+
+	right, err := fileSHA256(previous)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(left, right)
+}
+
+func fileSHA256(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+
+	return hash.Sum(nil), nil
+}
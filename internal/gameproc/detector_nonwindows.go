@@ -0,0 +1,7 @@
+//go:build !windows
+
+package gameproc
+
+func NewDefaultDetector() Detector {
+	return NewProcDetector("/proc")
+}
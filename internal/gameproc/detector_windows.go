@@ -0,0 +1,49 @@
+//go:build windows
+
+package gameproc
+
+import (
+	"errors"
+	"sort"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+type windowsDetector struct{}
+
+func NewDefaultDetector() Detector {
+	return windowsDetector{}
+}
+
+func (windowsDetector) FindRunning(processNames []string) ([]Process, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+
+	found := make([]Process, 0)
+	err = windows.Process32First(snapshot, &entry)
+	for err == nil {
+		exeName := windows.UTF16ToString(entry.ExeFile[:])
+		if name, ok := matchesProcessName(exeName, processNames); ok {
+			found = append(found, Process{PID: int(entry.ProcessID), Name: name})
+		}
+
+		err = windows.Process32Next(snapshot, &entry)
+	}
+
+	if !errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+		return nil, err
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].PID < found[j].PID
+	})
+
+	return found, nil
+}
@@ -0,0 +1,92 @@
+package gameproc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrGameRunning = errors.New("need for speed heat is running")
+
+var DefaultProcessNames = []string{"NeedForSpeedHeat.exe"}
+
+type Process struct {
+	PID  int    `json:"pid"`
+	Name string `json:"name"`
+}
+
+type Detector interface {
+	FindRunning(processNames []string) ([]Process, error)
+}
+
+// RunningError is returned by Guard.Check when a game process is detected.
+// It matches ErrGameRunning with errors.Is.
+type RunningError struct {
+	Processes []Process
+}
+
+func (e *RunningError) Error() string {
+	if len(e.Processes) == 0 {
+		return ErrGameRunning.Error()
+	}
+
+	parts := make([]string, 0, len(e.Processes))
+	for _, process := range e.Processes {
+		parts = append(parts, fmt.Sprintf("%s (pid %d)", process.Name, process.PID))
+	}
+
+	return fmt.Sprintf("%s: %s; close the game before changing saves", ErrGameRunning.Error(), strings.Join(parts, ", "))
+}
+
+func (e *RunningError) Unwrap() error {
+	return ErrGameRunning
+}
+
+type Guard struct {
+	detector     Detector
+	processNames []string
+}
+
+func NewGuard(detector Detector) *Guard {
+	return &Guard{
+		detector:     detector,
+		processNames: DefaultProcessNames,
+	}
+}
+
+func (g *Guard) Check() error {
+	if g == nil || g.detector == nil {
+		return nil
+	}
+
+	processes, err := g.detector.FindRunning(g.processNames)
+	if err != nil {
+		return fmt.Errorf("detect running game: %w", err)
+	}
+
+	if len(processes) > 0 {
+		return &RunningError{Processes: processes}
+	}
+
+	return nil
+}
+
+func matchesProcessName(candidate string, processNames []string) (string, bool) {
+	base := candidate
+	if idx := strings.LastIndexAny(base, `/\`); idx >= 0 {
+		base = base[idx+1:]
+	}
+
+	base = strings.TrimSpace(base)
+	if base == "" {
+		return "", false
+	}
+
+	for _, name := range processNames {
+		if strings.EqualFold(base, name) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,108 @@
+package gameproc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcDetectorFindsProtonCommandLine(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeProcEntry(t, root, "101", "bash", "/bin/bash\x00")
+	writeProcEntry(t, root, "202", "NeedForSpeedHea", "Z:\\games\\Need for Speed Heat\\NeedForSpeedHeat.exe\x00-dx12\x00")
+
+	processes, err := NewProcDetector(root).FindRunning(DefaultProcessNames)
+	if err != nil {
+		t.Fatalf("find running: %v", err)
+	}
+
+	if len(processes) != 1 || processes[0].PID != 202 || processes[0].Name != "NeedForSpeedHeat.exe" {
+		t.Fatalf("unexpected processes: %+v", processes)
+	}
+}
+
+func TestProcDetectorFallsBackToTruncatedComm(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeProcEntry(t, root, "303", "NeedForSpeedHea", "")
+
+	processes, err := NewProcDetector(root).FindRunning(DefaultProcessNames)
+	if err != nil {
+		t.Fatalf("find running: %v", err)
+	}
+
+	if len(processes) != 1 || processes[0].PID != 303 {
+		t.Fatalf("unexpected processes: %+v", processes)
+	}
+}
+
+func TestProcDetectorMissingRootFindsNothing(t *testing.T) {
+	t.Parallel()
+
+	processes, err := NewProcDetector(filepath.Join(t.TempDir(), "missing")).FindRunning(DefaultProcessNames)
+	if err != nil {
+		t.Fatalf("find running: %v", err)
+	}
+
+	if len(processes) != 0 {
+		t.Fatalf("expected no processes, got %+v", processes)
+	}
+}
+
+func TestGuardCheckReturnsRunningError(t *testing.T) {
+	t.Parallel()
+
+	guard := NewGuard(stubDetector{processes: []Process{{PID: 42, Name: "NeedForSpeedHeat.exe"}}})
+	err := guard.Check()
+	if !errors.Is(err, ErrGameRunning) {
+		t.Fatalf("expected ErrGameRunning, got %v", err)
+	}
+
+	var runningErr *RunningError
+	if !errors.As(err, &runningErr) || len(runningErr.Processes) != 1 {
+		t.Fatalf("expected RunningError with one process, got %v", err)
+	}
+}
+
+func TestGuardCheckPassesWhenGameIsNotRunning(t *testing.T) {
+	t.Parallel()
+
+	if err := NewGuard(stubDetector{}).Check(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var nilGuard *Guard
+	if err := nilGuard.Check(); err != nil {
+		t.Fatalf("expected nil guard to pass, got %v", err)
+	}
+}
+
+type stubDetector struct {
+	processes []Process
+}
+
+func (s stubDetector) FindRunning(processNames []string) ([]Process, error) {
+	_ = processNames
+	return s.processes, nil
+}
+
+func writeProcEntry(t *testing.T, root string, pid string, comm string, cmdline string) {
+	t.Helper()
+
+	dir := filepath.Join(root, pid)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", dir, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0o644); err != nil {
+		t.Fatalf("write comm: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0o644); err != nil {
+		t.Fatalf("write cmdline: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package gameproc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProcDetector scans a Linux-style /proc tree. Under Proton the game shows up
+// with a Windows executable path in its command line, and comm is truncated
+// to 15 characters, so both are checked.
+type ProcDetector struct {
+	root string
+}
+
+func NewProcDetector(root string) *ProcDetector {
+	return &ProcDetector{root: root}
+}
+
+func (d *ProcDetector) FindRunning(processNames []string) ([]Process, error) {
+	entries, err := os.ReadDir(d.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	found := make([]Process, 0)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		processDir := filepath.Join(d.root, entry.Name())
+		if name, ok := matchCmdline(filepath.Join(processDir, "cmdline"), processNames); ok {
+			found = append(found, Process{PID: pid, Name: name})
+			continue
+		}
+
+		if name, ok := matchComm(filepath.Join(processDir, "comm"), processNames); ok {
+			found = append(found, Process{PID: pid, Name: name})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].PID < found[j].PID
+	})
+
+	return found, nil
+}
+
+func matchCmdline(path string, processNames []string) (string, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil || len(content) == 0 {
+		return "", false
+	}
+
+	args := bytes.Split(content, []byte{0})
+	for _, arg := range args {
+		if name, ok := matchesProcessName(string(arg), processNames); ok {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+func matchComm(path string, processNames []string) (string, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	comm := strings.TrimSpace(string(content))
+	if comm == "" {
+		return "", false
+	}
+
+	if name, ok := matchesProcessName(comm, processNames); ok {
+		return name, true
+	}
+
+	for _, name := range processNames {
+		if len(comm) >= 15 && strings.HasPrefix(strings.ToLower(name), strings.ToLower(comm)) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,289 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/fsops"
+)
+
+// DirName is the journal folder inside the SaveGame root.
+const DirName = ".journal"
+
+// Operations recorded in the journal.
+const (
+	OperationSwitch       = "switch"
+	OperationSaveOutgoing = "save-outgoing"
+	OperationSave         = "save"
+	OperationImport       = "import"
+	OperationDelete       = "delete"
+	// OperationRestoreSnapshot replaces a profile folder with one of its
+	// snapshots, the same way a save replaces it with the root save.
+	OperationRestoreSnapshot = "restore-snapshot"
+	// OperationRestoreBackup puts a kept switch backup back into the root
+	// save. BackupRoot holds the parked root folders and StagingRoot the
+	// copies being restored.
+	OperationRestoreBackup = "restore-backup"
+)
+
+// Phases shared by the operations. Each operation only uses the subset that
+// applies to it; recovery decides what to do from the last recorded phase.
+const (
+	// PhaseStaging: a staging copy or backup is being written. Nothing the
+	// user owns has been touched yet.
+	PhaseStaging = "staging"
+	// PhaseStaged: the staging copy is complete and ready to swap in.
+	PhaseStaged = "staged"
+	// PhaseReplacing: live folders are being swapped or overwritten.
+	PhaseReplacing = "replacing"
+	// PhaseReplaced: the swap finished; the previous copy is parked until the
+	// enclosing operation decides to keep or discard it.
+	PhaseReplaced = "replaced"
+	// PhaseCommitting: a switch has replaced the root save; the marker update
+	// and backup retention are pending.
+	PhaseCommitting = "committing"
+	// PhaseRemoving: the parked copy is being deleted.
+	PhaseRemoving = "removing"
+)
+
+var ErrJournalDirRequired = errors.New("journal directory is required")
+
+// Entry is the write-ahead record of one in-flight operation. Paths are
+// absolute so recovery does not have to re-derive them.
+type Entry struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"`
+	Phase     string    `json:"phase"`
+	StartedAt time.Time `json:"startedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Profile is the profile the operation writes to; SwitchTarget is the
+	// profile a switch is moving to.
+	Profile      string `json:"profile,omitempty"`
+	SwitchTarget string `json:"switchTarget,omitempty"`
+
+	// Profile folder replacement: StagingRoot is swapped into TargetRoot and
+	// the previous TargetRoot is parked at ParkedRoot.
+	StagingRoot string `json:"stagingRoot,omitempty"`
+	TargetRoot  string `json:"targetRoot,omitempty"`
+	ParkedRoot  string `json:"parkedRoot,omitempty"`
+	HadExisting bool   `json:"hadExisting,omitempty"`
+
+	// Root switch: the root save was copied to BackupRoot first.
+	BackupRoot      string `json:"backupRoot,omitempty"`
+	HadSavegame     bool   `json:"hadSavegame,omitempty"`
+	HadWraps        bool   `json:"hadWraps,omitempty"`
+	PreviousProfile string `json:"previousProfile,omitempty"`
+
+	// Scratch lists the staging folders of directory replacements made under
+	// this entry; recovery settles them before anything else.
+	Scratch []fsops.Staging `json:"scratch,omitempty"`
+}
+
+type Journal struct {
+	dir string
+	now func() time.Time
+}
+
+func New(dir string) *Journal {
+	return &Journal{dir: dir, now: time.Now}
+}
+
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+// Record is a handle on a journaled operation. A nil Record is valid and
+// ignores every call, so services work the same without a journal.
+type Record struct {
+	journal *Journal
+	entry   Entry
+}
+
+// Begin writes the first journal entry for an operation. It returns a nil
+// Record when j is nil.
+func (j *Journal) Begin(entry Entry) (*Record, error) {
+	if j == nil {
+		return nil, nil
+	}
+
+	if strings.TrimSpace(j.dir) == "" {
+		return nil, ErrJournalDirRequired
+	}
+
+	if err := os.MkdirAll(j.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	now := j.now().UTC()
+	entry.StartedAt = now
+	entry.UpdatedAt = now
+	entry.ID = j.uniqueID(now, entry.Operation)
+
+	record := &Record{journal: j, entry: entry}
+	if err := record.write(); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Advance records that the operation is moving to phase, applying update to
+// the entry first when given.
+func (r *Record) Advance(phase string, update func(*Entry)) error {
+	if r == nil {
+		return nil
+	}
+
+	if update != nil {
+		update(&r.entry)
+	}
+
+	r.entry.Phase = phase
+	r.entry.UpdatedAt = r.journal.now().UTC()
+	return r.write()
+}
+
+// Track returns a context whose directory replacements are added to the
+// entry's Scratch before they write anything. A nil Record returns ctx.
+func (r *Record) Track(ctx context.Context) context.Context {
+	if r == nil {
+		return ctx
+	}
+
+	return fsops.WithStagingHook(ctx, func(staging fsops.Staging) error {
+		return r.Advance(r.entry.Phase, func(entry *Entry) {
+			entry.Scratch = append(entry.Scratch, staging)
+		})
+	})
+}
+
+// Finish removes the entry once the operation has fully completed or rolled
+// back on its own.
+func (r *Record) Finish() error {
+	if r == nil {
+		return nil
+	}
+
+	err := os.Remove(r.journal.path(r.entry.ID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Record) Entry() Entry {
+	if r == nil {
+		return Entry{}
+	}
+
+	return r.entry
+}
+
+// Pending lists entries left behind by operations that never finished, oldest
+// first.
+func (j *Journal) Pending() ([]Entry, error) {
+	if j == nil || strings.TrimSpace(j.dir) == "" {
+		return []Entry{}, nil
+	}
+
+	files, err := os.ReadDir(j.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(j.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(content, &entry); err != nil {
+			return nil, fmt.Errorf("read journal entry %s: %w", file.Name(), err)
+		}
+
+		entry.ID = strings.TrimSuffix(file.Name(), ".json")
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, k int) bool {
+		if entries[i].StartedAt.Equal(entries[k].StartedAt) {
+			return entries[i].ID < entries[k].ID
+		}
+		return entries[i].StartedAt.Before(entries[k].StartedAt)
+	})
+
+	return entries, nil
+}
+
+// Remove drops an entry after recovery has dealt with it.
+func (j *Journal) Remove(id string) error {
+	err := os.Remove(j.path(id))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Record) write() error {
+	content, err := json.MarshalIndent(r.entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	target := r.journal.path(r.entry.ID)
+	tmpPath := target + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, target)
+}
+
+func (j *Journal) path(id string) string {
+	return filepath.Join(j.dir, id+".json")
+}
+
+func (j *Journal) uniqueID(at time.Time, operation string) string {
+	base := strings.ReplaceAll(at.Format("20060102-150405.000000000"), ".", "") + "-" + operation
+	id := base
+	for attempt := 1; ; attempt++ {
+		if _, err := os.Stat(j.path(id)); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, attempt)
+	}
+}
@@ -0,0 +1,121 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBeginAdvanceFinishLifecycle(t *testing.T) {
+	t.Parallel()
+
+	j := New(filepath.Join(t.TempDir(), DirName))
+
+	record, err := j.Begin(Entry{Operation: OperationSave, Phase: PhaseStaging, Profile: "main"})
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+
+	err = record.Advance(PhaseStaged, func(entry *Entry) {
+		entry.StagingRoot = "/tmp/main.save-1"
+	})
+	if err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+
+	pending, err := j.Pending()
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+
+	if len(pending) != 1 {
+		t.Fatalf("expected one pending entry, got %d", len(pending))
+	}
+
+	got := pending[0]
+	if got.ID != record.Entry().ID || got.Phase != PhaseStaged || got.StagingRoot != "/tmp/main.save-1" || got.Profile != "main" {
+		t.Fatalf("unexpected pending entry: %+v", got)
+	}
+
+	if err := record.Finish(); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	pending, err = j.Pending()
+	if err != nil {
+		t.Fatalf("pending after finish: %v", err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending entries after finish, got %d", len(pending))
+	}
+}
+
+func TestPendingOrdersOldestFirst(t *testing.T) {
+	t.Parallel()
+
+	j := New(filepath.Join(t.TempDir(), DirName))
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	step := 0
+	j.now = func() time.Time {
+		step++
+		return base.Add(time.Duration(step) * time.Second)
+	}
+
+	for _, operation := range []string{OperationSaveOutgoing, OperationSwitch} {
+		if _, err := j.Begin(Entry{Operation: operation, Phase: PhaseStaging}); err != nil {
+			t.Fatalf("begin %s: %v", operation, err)
+		}
+	}
+
+	pending, err := j.Pending()
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+
+	if len(pending) != 2 || pending[0].Operation != OperationSaveOutgoing || pending[1].Operation != OperationSwitch {
+		t.Fatalf("expected save-outgoing then switch, got %+v", pending)
+	}
+}
+
+func TestNilJournalIsNoOp(t *testing.T) {
+	t.Parallel()
+
+	var j *Journal
+	record, err := j.Begin(Entry{Operation: OperationDelete})
+	if err != nil {
+		t.Fatalf("begin on nil journal: %v", err)
+	}
+
+	if err := record.Advance(PhaseRemoving, nil); err != nil {
+		t.Fatalf("advance nil record: %v", err)
+	}
+
+	if err := record.Finish(); err != nil {
+		t.Fatalf("finish nil record: %v", err)
+	}
+
+	pending, err := j.Pending()
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("expected empty pending list, got %v, %v", pending, err)
+	}
+}
+
+func TestPendingMissingDirIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	j := New(filepath.Join(t.TempDir(), "missing"))
+	pending, err := j.Pending()
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatalf("expected no entries, got %d", len(pending))
+	}
+
+	if _, err := os.Stat(j.Dir()); !os.IsNotExist(err) {
+		t.Fatalf("expected Pending not to create the journal dir, got %v", err)
+	}
+}
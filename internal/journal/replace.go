@@ -0,0 +1,121 @@
+package journal
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Replacement is a folder swapped for a staged copy whose previous contents
+// are parked until Commit or Rollback. Each step is recorded on the record's
+// TargetRoot, ParkedRoot and HadExisting fields so recovery can settle a swap
+// that was cut short.
+type Replacement struct {
+	targetRoot  string
+	parkedRoot  string
+	hadExisting bool
+	record      *Record
+}
+
+// Replace swaps stagingRoot in for targetRoot and drops the previous copy,
+// finishing record once the swap is complete.
+func Replace(record *Record, targetRoot string, stagingRoot string) error {
+	replacement, err := BeginReplace(record, targetRoot, stagingRoot)
+	if err != nil {
+		return err
+	}
+
+	return replacement.Commit()
+}
+
+// BeginReplace swaps stagingRoot in for targetRoot and parks the previous
+// copy. When it fails, targetRoot is put back and record is finished; if
+// even that fails, record is left pending for recovery.
+func BeginReplace(record *Record, targetRoot string, stagingRoot string) (*Replacement, error) {
+	replacement := &Replacement{
+		targetRoot: targetRoot,
+		parkedRoot: targetRoot + ".backup-" + time.Now().UTC().Format("20060102-150405.000000000"),
+		record:     record,
+	}
+
+	info, err := os.Stat(targetRoot)
+	if err == nil {
+		if !info.IsDir() {
+			_ = record.Finish()
+			return nil, fmt.Errorf("profile path exists but is not a directory: %s", targetRoot)
+		}
+		replacement.hadExisting = true
+	} else if !os.IsNotExist(err) {
+		_ = record.Finish()
+		return nil, err
+	}
+
+	err = record.Advance(PhaseReplacing, func(entry *Entry) {
+		entry.ParkedRoot = replacement.parkedRoot
+		entry.HadExisting = replacement.hadExisting
+	})
+	if err != nil {
+		_ = record.Finish()
+		return nil, fmt.Errorf("record replace: %w", err)
+	}
+
+	if replacement.hadExisting {
+		if err := os.RemoveAll(replacement.parkedRoot); err != nil {
+			_ = record.Finish()
+			return nil, err
+		}
+		if err := os.Rename(targetRoot, replacement.parkedRoot); err != nil {
+			_ = record.Finish()
+			return nil, err
+		}
+	}
+
+	if err := os.Rename(stagingRoot, targetRoot); err != nil {
+		if replacement.hadExisting {
+			if rollbackErr := os.Rename(replacement.parkedRoot, targetRoot); rollbackErr != nil {
+				return nil, fmt.Errorf("replace profile failed: %w; rollback failed: %v", err, rollbackErr)
+			}
+		}
+
+		_ = record.Finish()
+		return nil, err
+	}
+
+	if err := record.Advance(PhaseReplaced, nil); err != nil {
+		err = fmt.Errorf("record replace: %w", err)
+		if rollbackErr := replacement.Rollback(); rollbackErr != nil {
+			return nil, fmt.Errorf("%w; rollback failed: %v", err, rollbackErr)
+		}
+
+		return nil, err
+	}
+
+	return replacement, nil
+}
+
+// Commit drops the parked copy and finishes the record.
+func (r *Replacement) Commit() error {
+	if r.hadExisting {
+		if err := os.RemoveAll(r.parkedRoot); err != nil {
+			return err
+		}
+	}
+
+	return r.record.Finish()
+}
+
+// Rollback puts the parked copy back and finishes the record. When that
+// fails the record is left pending so recovery can finish putting it back.
+func (r *Replacement) Rollback() error {
+	if err := os.RemoveAll(r.targetRoot); err != nil {
+		return err
+	}
+
+	if r.hadExisting {
+		if err := os.Rename(r.parkedRoot, r.targetRoot); err != nil {
+			return err
+		}
+	}
+
+	return r.record.Finish()
+}
@@ -0,0 +1,95 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceSwapsFolderAndFinishesRecord(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	j := New(filepath.Join(root, DirName))
+	targetRoot := createFolder(t, filepath.Join(root, "main"), "old")
+	stagingRoot := createFolder(t, filepath.Join(root, "main.save-1"), "new")
+
+	record, err := j.Begin(Entry{Operation: OperationSave, Phase: PhaseStaged, TargetRoot: targetRoot, StagingRoot: stagingRoot})
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+
+	if err := Replace(record, targetRoot, stagingRoot); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(targetRoot, "file.txt"))
+	if err != nil || string(content) != "new" {
+		t.Fatalf("expected the staged copy at the target, got %q, %v", content, err)
+	}
+
+	if _, err := os.Stat(stagingRoot); !os.IsNotExist(err) {
+		t.Fatalf("expected the staging folder to be moved, got %v", err)
+	}
+
+	parked, err := filepath.Glob(targetRoot + ".backup-*")
+	if err != nil || len(parked) != 0 {
+		t.Fatalf("expected the parked copy to be removed, got %v, %v", parked, err)
+	}
+
+	pending, err := j.Pending()
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("expected no pending entries, got %v, %v", pending, err)
+	}
+}
+
+func TestFailedRollbackLeavesRecordPending(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	j := New(filepath.Join(root, DirName))
+	targetRoot := createFolder(t, filepath.Join(root, "main"), "old")
+	stagingRoot := createFolder(t, filepath.Join(root, "main.save-1"), "new")
+
+	record, err := j.Begin(Entry{Operation: OperationSaveOutgoing, Phase: PhaseStaged, TargetRoot: targetRoot, StagingRoot: stagingRoot})
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+
+	replacement, err := BeginReplace(record, targetRoot, stagingRoot)
+	if err != nil {
+		t.Fatalf("begin replace: %v", err)
+	}
+
+	parkedRoot := record.Entry().ParkedRoot
+	if err := os.RemoveAll(parkedRoot); err != nil {
+		t.Fatalf("remove parked copy: %v", err)
+	}
+
+	if err := replacement.Rollback(); err == nil {
+		t.Fatal("expected rollback without a parked copy to fail")
+	}
+
+	pending, err := j.Pending()
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+
+	if len(pending) != 1 || pending[0].Phase != PhaseReplaced || pending[0].ParkedRoot != parkedRoot || !pending[0].HadExisting {
+		t.Fatalf("expected the replace to stay pending for recovery, got %+v", pending)
+	}
+}
+
+func createFolder(t *testing.T, path string, content string) string {
+	t.Helper()
+
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatalf("create %s: %v", path, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "file.txt"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+
+	return path
+}
@@ -0,0 +1,332 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/journal"
+)
+
+// Recovery outcomes reported for each interrupted operation.
+const (
+	RecoveryCompleted  = "completed"
+	RecoveryRolledBack = "rolled-back"
+	RecoveryFailed     = "failed"
+)
+
+// RecoveryAction describes what recovery did with one interrupted operation.
+// Failed entries stay in the journal and are retried on the next start.
+type RecoveryAction struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"`
+	Profile   string    `json:"profile,omitempty"`
+	Phase     string    `json:"phase"`
+	StartedAt time.Time `json:"startedAt"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// RecoverInterrupted finishes or undoes every operation the journal shows as
+// still in flight. Entries are handled newest first so a switch nested inside
+// a save-outgoing or delete is settled before the operation wrapping it. It
+// reports savelock.ErrBusy when another process is mid-operation, since its
+// entries are not interrupted.
+func (s *Service) RecoverInterrupted() ([]RecoveryAction, error) {
+	actions := []RecoveryAction{}
+	if s.journal == nil {
+		return actions, nil
+	}
+
+	if err := s.validateDependencies(); err != nil {
+		return actions, err
+	}
+
+	release, err := s.lock.Acquire("recover")
+	if err != nil {
+		return actions, err
+	}
+	defer release()
+
+	entries, err := s.journal.Pending()
+	if err != nil {
+		return actions, err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		action := RecoveryAction{
+			ID:        entry.ID,
+			Operation: entry.Operation,
+			Profile:   entry.Profile,
+			Phase:     entry.Phase,
+			StartedAt: entry.StartedAt,
+		}
+		if action.Profile == "" {
+			action.Profile = entry.SwitchTarget
+		}
+
+		outcome, err := s.recoverEntry(entry)
+		if err == nil {
+			err = s.journal.Remove(entry.ID)
+		}
+
+		if err != nil {
+			action.Outcome = RecoveryFailed
+			action.Error = err.Error()
+		} else {
+			action.Outcome = outcome
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+func (s *Service) recoverEntry(entry journal.Entry) (string, error) {
+	// Interrupted directory replacements are settled first, so the folders
+	// they were replacing are whole again before the operation is judged.
+	for _, staging := range entry.Scratch {
+		if err := fsops.RecoverStaging(staging); err != nil {
+			return "", fmt.Errorf("clean up staging folder: %w", err)
+		}
+	}
+
+	switch entry.Operation {
+	case journal.OperationSwitch:
+		return s.recoverSwitch(entry)
+	case journal.OperationSave, journal.OperationSaveOutgoing, journal.OperationImport, journal.OperationRestoreSnapshot:
+		return s.recoverReplace(entry)
+	case journal.OperationDelete:
+		return s.recoverDelete(entry)
+	case journal.OperationRestoreBackup:
+		return s.recoverRestoreBackup(entry)
+	default:
+		return "", fmt.Errorf("unknown journal operation %q", entry.Operation)
+	}
+}
+
+// recoverSwitch rolls the root save back from the switch backup unless the
+// new profile was already fully copied in, in which case the marker update
+// and backup retention are finished instead.
+func (s *Service) recoverSwitch(entry journal.Entry) (string, error) {
+	switch entry.Phase {
+	case journal.PhaseStaging:
+		return RecoveryRolledBack, s.removeSwitchBackup(entry.BackupRoot)
+	case journal.PhaseReplacing:
+		if err := s.restoreRootDir(savegameDirName, entry.BackupRoot, entry.HadSavegame); err != nil {
+			return "", err
+		}
+
+		if err := s.restoreRootDir(wrapsDirName, entry.BackupRoot, entry.HadWraps); err != nil {
+			return "", err
+		}
+
+		return RecoveryRolledBack, s.removeSwitchBackup(entry.BackupRoot)
+	case journal.PhaseCommitting:
+		if err := s.marker.WriteActiveProfile(entry.SwitchTarget); err != nil {
+			return "", err
+		}
+
+		// A backup that cannot be kept stays in place with the journal entry,
+		// so the next recovery tries again instead of deleting it.
+		if s.backups != nil && dirExists(entry.BackupRoot) {
+			if backupID, err := s.backups.Keep(entry.BackupRoot, entry.PreviousProfile, entry.SwitchTarget); err != nil && backupID == "" {
+				return "", fmt.Errorf("keep switch backup: %w", err)
+			}
+		}
+
+		return RecoveryCompleted, s.removeSwitchBackup(entry.BackupRoot)
+	default:
+		return "", fmt.Errorf("unexpected switch phase %q", entry.Phase)
+	}
+}
+
+// recoverRestoreBackup puts the parked root folders back unless both restored
+// folders were already swapped in, in which case the marker update is
+// finished instead. The parked and staged folders share BackupRoot.
+func (s *Service) recoverRestoreBackup(entry journal.Entry) (string, error) {
+	switch entry.Phase {
+	case journal.PhaseStaging:
+		return RecoveryRolledBack, s.removeSwitchBackup(entry.BackupRoot)
+	case journal.PhaseReplacing:
+		if err := s.restoreRootDir(savegameDirName, entry.BackupRoot, entry.HadSavegame); err != nil {
+			return "", err
+		}
+
+		if err := s.restoreRootDir(wrapsDirName, entry.BackupRoot, entry.HadWraps); err != nil {
+			return "", err
+		}
+
+		return RecoveryRolledBack, s.removeSwitchBackup(entry.BackupRoot)
+	case journal.PhaseReplaced:
+		if entry.SwitchTarget != "" {
+			if err := s.marker.WriteActiveProfile(entry.SwitchTarget); err != nil {
+				return "", err
+			}
+		}
+
+		return RecoveryCompleted, s.removeSwitchBackup(entry.BackupRoot)
+	default:
+		return "", fmt.Errorf("unexpected restore phase %q", entry.Phase)
+	}
+}
+
+// restoreRootDir puts back a root folder a switch moved into its backup. An
+// original that never reached the backup was not moved and is left alone.
+func (s *Service) restoreRootDir(dirName string, backupRoot string, hadOriginal bool) error {
+	target := filepath.Join(s.saveGamePath, dirName)
+	backup := filepath.Join(backupRoot, dirName)
+	if hadOriginal && !dirExists(backup) {
+		return nil
+	}
+
+	if err := s.ops.RemoveDir(target); err != nil {
+		return err
+	}
+
+	if !hadOriginal {
+		return nil
+	}
+
+	return s.ops.MoveDir(context.Background(), backup, target)
+}
+
+// removeSwitchBackup drops a switch backup tree and its parent once empty.
+func (s *Service) removeSwitchBackup(backupRoot string) error {
+	if strings.TrimSpace(backupRoot) == "" {
+		return nil
+	}
+
+	if err := s.ops.RemoveDir(backupRoot); err != nil {
+		return err
+	}
+
+	backupParent := filepath.Dir(backupRoot)
+	entries, err := os.ReadDir(backupParent)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if len(entries) == 0 {
+		return s.ops.RemoveDir(backupParent)
+	}
+
+	return nil
+}
+
+// recoverReplace settles a profile folder replacement. A swap that already
+// happened is kept, except for a save-outgoing whose switch did not go
+// through, which is undone the same way SwitchProfile would have.
+func (s *Service) recoverReplace(entry journal.Entry) (string, error) {
+	switch entry.Phase {
+	case journal.PhaseStaging, journal.PhaseStaged:
+		return RecoveryRolledBack, removeIfSet(entry.StagingRoot)
+	case journal.PhaseReplacing:
+		if !dirExists(entry.StagingRoot) && dirExists(entry.TargetRoot) {
+			return RecoveryCompleted, completeReplace(entry)
+		}
+
+		return RecoveryRolledBack, rollbackReplace(entry)
+	case journal.PhaseReplaced:
+		if entry.Operation == journal.OperationSaveOutgoing && !s.isActiveProfile(entry.SwitchTarget) {
+			return RecoveryRolledBack, rollbackReplace(entry)
+		}
+
+		return RecoveryCompleted, completeReplace(entry)
+	case journal.PhaseRemoving:
+		return RecoveryCompleted, completeReplace(entry)
+	default:
+		return "", fmt.Errorf("unexpected %s phase %q", entry.Operation, entry.Phase)
+	}
+}
+
+func completeReplace(entry journal.Entry) error {
+	if err := removeIfSet(entry.ParkedRoot); err != nil {
+		return err
+	}
+
+	return removeIfSet(entry.StagingRoot)
+}
+
+func rollbackReplace(entry journal.Entry) error {
+	staged := dirExists(entry.StagingRoot)
+	switch {
+	case entry.ParkedRoot != "" && dirExists(entry.ParkedRoot):
+		// The previous copy is parked, so whatever sits at the target is the
+		// new one.
+		if err := os.RemoveAll(entry.TargetRoot); err != nil {
+			return err
+		}
+		if err := os.Rename(entry.ParkedRoot, entry.TargetRoot); err != nil {
+			return err
+		}
+	case !entry.HadExisting && !staged:
+		// The staged copy was moved into a target that did not exist before.
+		if err := os.RemoveAll(entry.TargetRoot); err != nil {
+			return err
+		}
+	}
+
+	return removeIfSet(entry.StagingRoot)
+}
+
+// recoverDelete puts a parked profile back unless the delete had reached the
+// point of no return, in which case the parked copy is removed.
+func (s *Service) recoverDelete(entry journal.Entry) (string, error) {
+	switch entry.Phase {
+	case journal.PhaseReplacing:
+		return RecoveryRolledBack, restoreParked(entry)
+	case journal.PhaseReplaced:
+		if entry.SwitchTarget != "" && !s.isActiveProfile(entry.SwitchTarget) {
+			return RecoveryRolledBack, restoreParked(entry)
+		}
+
+		return RecoveryCompleted, s.finishDelete(entry)
+	case journal.PhaseRemoving:
+		return RecoveryCompleted, s.finishDelete(entry)
+	default:
+		return "", fmt.Errorf("unexpected delete phase %q", entry.Phase)
+	}
+}
+
+func (s *Service) finishDelete(entry journal.Entry) error {
+	if err := s.ops.RemoveDir(entry.ParkedRoot); err != nil {
+		return err
+	}
+
+	return s.deleteSnapshots(entry.Profile)
+}
+
+func restoreParked(entry journal.Entry) error {
+	if dirExists(entry.TargetRoot) || !dirExists(entry.ParkedRoot) {
+		return nil
+	}
+
+	return os.Rename(entry.ParkedRoot, entry.TargetRoot)
+}
+
+func (s *Service) isActiveProfile(profileName string) bool {
+	active, err := s.marker.ReadActiveProfile()
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(strings.TrimSpace(active), strings.TrimSpace(profileName))
+}
+
+func removeIfSet(path string) error {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+
+	return os.RemoveAll(path)
+}
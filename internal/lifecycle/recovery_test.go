@@ -0,0 +1,517 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"heat-save-manager/internal/backups"
+	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/journal"
+	"heat-save-manager/internal/marker"
+	"heat-save-manager/internal/profiles"
+)
+
+func TestRecoverInterruptedRollsBackHalfWrittenSwitch(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	backupRoot := filepath.Join(saveGamePath, ".backup", "switch-1")
+
+	createDirWithFile(t, filepath.Join(backupRoot, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(backupRoot, "wraps"), "wrap.txt", "alpha-wrap")
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "beta-partial")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	beginEntry(t, j, journal.Entry{
+		Operation:       journal.OperationSwitch,
+		Phase:           journal.PhaseReplacing,
+		SwitchTarget:    "ProfileBeta",
+		PreviousProfile: "ProfileAlpha",
+		BackupRoot:      backupRoot,
+		HadSavegame:     true,
+		HadWraps:        true,
+	})
+
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetJournal(j)
+
+	actions, err := svc.RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	if len(actions) != 1 || actions[0].Outcome != RecoveryRolledBack || actions[0].Profile != "ProfileBeta" {
+		t.Fatalf("unexpected recovery actions: %+v", actions)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "alpha-progress")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "alpha-wrap")
+	assertPathMissing(t, filepath.Join(saveGamePath, ".backup"))
+	assertNoPendingEntries(t, j)
+}
+
+func TestRecoverInterruptedRestoresRootMovedAsidePartway(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	backupRoot := filepath.Join(saveGamePath, ".backup", "switch-1")
+
+	// The savegame folder reached the backup; wraps was never moved.
+	createDirWithFile(t, filepath.Join(backupRoot, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap")
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	beginEntry(t, j, journal.Entry{
+		Operation:       journal.OperationSwitch,
+		Phase:           journal.PhaseReplacing,
+		SwitchTarget:    "ProfileBeta",
+		PreviousProfile: "ProfileAlpha",
+		BackupRoot:      backupRoot,
+		HadSavegame:     true,
+		HadWraps:        true,
+	})
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	svc.SetJournal(j)
+
+	actions, err := svc.RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	if len(actions) != 1 || actions[0].Outcome != RecoveryRolledBack {
+		t.Fatalf("unexpected recovery actions: %+v", actions)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "alpha-progress")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "alpha-wrap")
+	assertPathMissing(t, filepath.Join(saveGamePath, ".backup"))
+	assertNoPendingEntries(t, j)
+}
+
+func TestRecoverInterruptedUndoesHalfSwappedBackupRestore(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	workRoot := filepath.Join(saveGamePath, ".backup", "restore-1")
+	stagingRoot := filepath.Join(workRoot, "staged")
+
+	// The restored savegame was swapped in; wraps was not parked yet.
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "restored-save")
+	createDirWithFile(t, filepath.Join(workRoot, "savegame"), "slot.sav", "beta-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "beta-wrap")
+	createDirWithFile(t, filepath.Join(stagingRoot, "wraps"), "wrap.txt", "restored-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileBeta"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	beginEntry(t, j, journal.Entry{
+		Operation:       journal.OperationRestoreBackup,
+		Phase:           journal.PhaseReplacing,
+		SwitchTarget:    "ProfileAlpha",
+		PreviousProfile: "ProfileBeta",
+		StagingRoot:     stagingRoot,
+		BackupRoot:      workRoot,
+		HadSavegame:     true,
+		HadWraps:        true,
+	})
+
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetJournal(j)
+
+	actions, err := svc.RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	if len(actions) != 1 || actions[0].Outcome != RecoveryRolledBack {
+		t.Fatalf("unexpected recovery actions: %+v", actions)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "beta-progress")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "beta-wrap")
+	assertPathMissing(t, filepath.Join(saveGamePath, ".backup"))
+	assertNoPendingEntries(t, j)
+
+	active, err := store.ReadActiveProfile()
+	if err != nil {
+		t.Fatalf("read marker: %v", err)
+	}
+
+	if active != "ProfileBeta" {
+		t.Fatalf("expected marker to stay on ProfileBeta, got %q", active)
+	}
+}
+
+func TestRecoverInterruptedRemovesSwitchStagingLeftBeforeTheSwap(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	backupRoot := filepath.Join(saveGamePath, ".backup", "switch-1")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	// Run a real switch and stop it as a crash would once the profile's
+	// savegame is fully staged next to the root but not yet renamed into place.
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	svc := NewService(saveGamePath, profilesPath, store, &crashBeforeSwapOps{Local: fsops.NewLocal(), journal: j})
+	svc.SetJournal(j)
+	func() {
+		defer func() {
+			if r := recover(); r != errSimulatedCrash {
+				t.Fatalf("expected the simulated crash, got %v", r)
+			}
+		}()
+		_, _ = svc.SwitchProfile("ProfileBeta", false)
+	}()
+
+	pending, err := j.Pending()
+	if err != nil || len(pending) != 1 || len(pending[0].Scratch) == 0 {
+		t.Fatalf("expected the switch and its staging folder journaled, got %+v, %v", pending, err)
+	}
+
+	staging := pending[0].Scratch[0].Staging
+	if _, err := os.Stat(staging); err != nil {
+		t.Fatalf("expected the staging folder left by the crash, got %v", err)
+	}
+
+	recovering := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	recovering.SetJournal(j)
+	actions, err := recovering.RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	if len(actions) != 1 || actions[0].Outcome != RecoveryRolledBack {
+		t.Fatalf("unexpected recovery actions: %+v", actions)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "alpha-progress")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "alpha-wrap")
+	assertPathMissing(t, staging)
+	assertPathMissing(t, backupRoot)
+	assertNoPendingEntries(t, j)
+
+	entries, err := os.ReadDir(saveGamePath)
+	if err != nil {
+		t.Fatalf("read SaveGame: %v", err)
+	}
+
+	for _, entry := range entries {
+		if profiles.IsArtifactName(entry.Name()) {
+			t.Fatalf("expected no staging leftovers, found %s", entry.Name())
+		}
+	}
+}
+
+var errSimulatedCrash = errors.New("simulated crash")
+
+// crashBeforeSwapOps runs the first SyncDir, then takes its result back out
+// of the destination into the journaled staging folder and panics, leaving
+// the state a crash right before the final rename would.
+type crashBeforeSwapOps struct {
+	*fsops.Local
+	journal *journal.Journal
+}
+
+func (c *crashBeforeSwapOps) SyncDir(ctx context.Context, source string, destination string, reference string) error {
+	if err := c.Local.SyncDir(ctx, source, destination, reference); err != nil {
+		return err
+	}
+
+	pending, err := c.journal.Pending()
+	if err != nil || len(pending) == 0 || len(pending[0].Scratch) == 0 {
+		return fmt.Errorf("expected a journaled staging folder: %v", err)
+	}
+
+	if err := os.Rename(destination, pending[0].Scratch[0].Staging); err != nil {
+		return err
+	}
+
+	panic(errSimulatedCrash)
+}
+
+func TestRecoverInterruptedCompletesCommittingSwitch(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	backupRoot := filepath.Join(saveGamePath, ".backup", "switch-1")
+
+	createDirWithFile(t, filepath.Join(backupRoot, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	beginEntry(t, j, journal.Entry{
+		Operation:       journal.OperationSwitch,
+		Phase:           journal.PhaseCommitting,
+		SwitchTarget:    "ProfileBeta",
+		PreviousProfile: "ProfileAlpha",
+		BackupRoot:      backupRoot,
+		HadSavegame:     true,
+	})
+
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetJournal(j)
+
+	actions, err := svc.RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	if len(actions) != 1 || actions[0].Outcome != RecoveryCompleted {
+		t.Fatalf("unexpected recovery actions: %+v", actions)
+	}
+
+	active, err := store.ReadActiveProfile()
+	if err != nil {
+		t.Fatalf("read marker: %v", err)
+	}
+
+	if active != "ProfileBeta" {
+		t.Fatalf("expected marker to point at ProfileBeta, got %q", active)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "beta-save")
+	assertPathMissing(t, backupRoot)
+	assertNoPendingEntries(t, j)
+}
+
+func TestRecoverInterruptedKeepsSwitchBackupWhenItCannotBeKept(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	backupRoot := filepath.Join(saveGamePath, ".backup", "switch-1")
+
+	createDirWithFile(t, filepath.Join(backupRoot, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "beta-wrap")
+
+	// A file where the backup store should be makes keeping fail.
+	storePath := filepath.Join(root, "not-a-folder")
+	if err := os.WriteFile(storePath, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write blocker: %v", err)
+	}
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	beginEntry(t, j, journal.Entry{
+		Operation:       journal.OperationSwitch,
+		Phase:           journal.PhaseCommitting,
+		SwitchTarget:    "ProfileBeta",
+		PreviousProfile: "ProfileAlpha",
+		BackupRoot:      backupRoot,
+		HadSavegame:     true,
+	})
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	svc.SetJournal(j)
+	svc.SetSwitchBackups(backups.NewService(filepath.Join(storePath, "backups"), fsops.NewLocal(), backups.Retention{}))
+
+	actions, err := svc.RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	if len(actions) != 1 || actions[0].Outcome != RecoveryFailed {
+		t.Fatalf("expected recovery to report the failed keep, got %+v", actions)
+	}
+
+	assertFileContent(t, filepath.Join(backupRoot, "savegame", "slot.sav"), "alpha-progress")
+
+	pending, err := j.Pending()
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected the entry to stay for the next recovery, got %+v err=%v", pending, err)
+	}
+}
+
+func TestRecoverInterruptedRollsBackOutgoingSaveWithItsSwitch(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	backupRoot := filepath.Join(saveGamePath, ".backup", "switch-1")
+	alphaRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	parkedRoot := alphaRoot + ".backup-1"
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap")
+	createDirWithFile(t, filepath.Join(alphaRoot, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(parkedRoot, "savegame"), "slot.sav", "alpha-stale")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	beginEntry(t, j, journal.Entry{
+		Operation:    journal.OperationSaveOutgoing,
+		Phase:        journal.PhaseReplaced,
+		Profile:      "ProfileAlpha",
+		SwitchTarget: "ProfileBeta",
+		StagingRoot:  filepath.Join(profilesPath, "ProfileAlpha.save-1"),
+		TargetRoot:   alphaRoot,
+		ParkedRoot:   parkedRoot,
+		HadExisting:  true,
+	})
+	beginEntry(t, j, journal.Entry{
+		Operation:    journal.OperationSwitch,
+		Phase:        journal.PhaseStaging,
+		SwitchTarget: "ProfileBeta",
+		BackupRoot:   backupRoot,
+	})
+
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetJournal(j)
+
+	actions, err := svc.RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	if len(actions) != 2 || actions[0].Operation != journal.OperationSwitch || actions[1].Outcome != RecoveryRolledBack {
+		t.Fatalf("unexpected recovery actions: %+v", actions)
+	}
+
+	assertFileContent(t, filepath.Join(alphaRoot, "savegame", "slot.sav"), "alpha-stale")
+	assertPathMissing(t, parkedRoot)
+	assertNoPendingEntries(t, j)
+}
+
+func TestRecoverInterruptedFinishesDeleteBeingRemoved(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	parkedRoot := filepath.Join(profilesPath, "ProfileBeta.delete-1")
+
+	createDirWithFile(t, filepath.Join(parkedRoot, "savegame"), "slot.sav", "beta-save")
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	beginEntry(t, j, journal.Entry{
+		Operation:  journal.OperationDelete,
+		Phase:      journal.PhaseRemoving,
+		Profile:    "ProfileBeta",
+		TargetRoot: filepath.Join(profilesPath, "ProfileBeta"),
+		ParkedRoot: parkedRoot,
+	})
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	svc.SetJournal(j)
+
+	actions, err := svc.RecoverInterrupted()
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	if len(actions) != 1 || actions[0].Outcome != RecoveryCompleted {
+		t.Fatalf("unexpected recovery actions: %+v", actions)
+	}
+
+	assertPathMissing(t, parkedRoot)
+	assertPathMissing(t, filepath.Join(profilesPath, "ProfileBeta"))
+	assertNoPendingEntries(t, j)
+}
+
+func TestSwitchProfileLeavesNoJournalEntries(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetJournal(j)
+
+	if _, err := svc.SwitchProfile("ProfileBeta", true); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	if err := svc.DeleteProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("delete profile: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "beta-save")
+	assertNoPendingEntries(t, j)
+}
+
+func beginEntry(t *testing.T, j *journal.Journal, entry journal.Entry) {
+	t.Helper()
+
+	if _, err := j.Begin(entry); err != nil {
+		t.Fatalf("begin journal entry: %v", err)
+	}
+}
+
+func assertNoPendingEntries(t *testing.T, j *journal.Journal) {
+	t.Helper()
+
+	pending, err := j.Pending()
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatalf("expected empty journal, got %+v", pending)
+	}
+}
+
+func assertPathMissing(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be gone, got %v", path, err)
+	}
+}
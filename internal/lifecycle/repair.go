@@ -0,0 +1,143 @@
+package lifecycle
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"heat-save-manager/internal/journal"
+	"heat-save-manager/internal/profiles"
+)
+
+// QuarantineDirName is where entries that are not usable profiles are moved,
+// inside the SaveGame root and outside the profiles folder.
+const QuarantineDirName = ".quarantine"
+
+var (
+	ErrProfileNotRepairable = errors.New("profile cannot be repaired automatically")
+	ErrProfileIsValid       = errors.New("profile is valid and does not need repair")
+	ErrEntryInUse           = errors.New("entry belongs to an interrupted operation; run recovery first")
+)
+
+// RepairProfile fixes a profile folder that is only missing its wraps
+// folder by creating an empty one. Other problems cannot be fixed without
+// guessing at the user's saves and are reported as ErrProfileNotRepairable.
+func (s *Service) RepairProfile(profileName string) error {
+	if err := s.validateDependencies(); err != nil {
+		return err
+	}
+
+	release, err := s.lock.Acquire("repair")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	candidate, err := s.inspectEntry(profileName)
+	if err != nil {
+		return err
+	}
+
+	switch candidate.Status {
+	case profiles.StatusValid:
+		return ErrProfileIsValid
+	case profiles.StatusMissingWraps:
+		return os.Mkdir(filepath.Join(candidate.Path, wrapsDirName), 0o755)
+	default:
+		return ErrProfileNotRepairable
+	}
+}
+
+// QuarantineProfileEntry moves an entry of the profiles folder that is not a
+// valid profile into SaveGame/.quarantine, so it stops cluttering the folder
+// without being deleted. It returns the new location.
+func (s *Service) QuarantineProfileEntry(entryName string) (string, error) {
+	if err := s.validateDependencies(); err != nil {
+		return "", err
+	}
+
+	release, err := s.lock.Acquire("quarantine")
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	candidate, err := s.inspectEntry(entryName)
+	if err != nil {
+		return "", err
+	}
+
+	if candidate.Status == profiles.StatusValid {
+		return "", ErrProfileIsValid
+	}
+
+	inUse, err := s.referencedByJournal(candidate.Path)
+	if err != nil {
+		return "", err
+	}
+	if inUse {
+		return "", ErrEntryInUse
+	}
+
+	quarantineRoot := filepath.Join(s.saveGamePath, QuarantineDirName)
+	if err := os.MkdirAll(quarantineRoot, 0o755); err != nil {
+		return "", err
+	}
+
+	destination := filepath.Join(quarantineRoot, s.now().UTC().Format("20060102-150405")+"-"+candidate.Name)
+	if _, err := os.Lstat(destination); err == nil {
+		return "", os.ErrExist
+	}
+
+	if err := os.Rename(candidate.Path, destination); err != nil {
+		return "", err
+	}
+
+	return destination, nil
+}
+
+// inspectEntry resolves a name from profiles.Scan to its entry. Names of
+// artifacts are not valid profile names, so only path separators are
+// rejected here.
+func (s *Service) inspectEntry(entryName string) (profiles.Candidate, error) {
+	name := strings.TrimSpace(entryName)
+	if name == "" {
+		return profiles.Candidate{}, ErrProfileNameRequired
+	}
+
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return profiles.Candidate{}, ErrProfileNameInvalid
+	}
+
+	path := filepath.Join(s.profilesPath, name)
+	if _, err := os.Lstat(path); err != nil {
+		if os.IsNotExist(err) {
+			return profiles.Candidate{}, ErrProfileNotFound
+		}
+		return profiles.Candidate{}, err
+	}
+
+	return profiles.Inspect(path), nil
+}
+
+func (s *Service) referencedByJournal(path string) (bool, error) {
+	entries, err := s.journal.Pending()
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range entries {
+		for _, referenced := range journalPaths(entry) {
+			if referenced != "" && strings.EqualFold(filepath.Clean(referenced), filepath.Clean(path)) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func journalPaths(entry journal.Entry) []string {
+	return []string{entry.StagingRoot, entry.TargetRoot, entry.ParkedRoot, entry.BackupRoot}
+}
@@ -0,0 +1,123 @@
+package lifecycle
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/journal"
+	"heat-save-manager/internal/marker"
+)
+
+func TestRepairProfileCreatesMissingWrapsOnly(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	createDirWithFile(t, filepath.Join(profilesPath, "NoWraps", "savegame"), "slot.sav", "alpha")
+	createDirWithFile(t, filepath.Join(profilesPath, "NoSavegame", "wraps"), "wrap.txt", "beta")
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+
+	if err := svc.RepairProfile("NoWraps"); err != nil {
+		t.Fatalf("repair profile: %v", err)
+	}
+
+	assertDirEmpty(t, filepath.Join(profilesPath, "NoWraps", "wraps"))
+	assertFileContent(t, filepath.Join(profilesPath, "NoWraps", "savegame", "slot.sav"), "alpha")
+
+	if err := svc.RepairProfile("NoWraps"); !errors.Is(err, ErrProfileIsValid) {
+		t.Fatalf("expected ErrProfileIsValid, got %v", err)
+	}
+
+	if err := svc.RepairProfile("NoSavegame"); !errors.Is(err, ErrProfileNotRepairable) {
+		t.Fatalf("expected ErrProfileNotRepairable, got %v", err)
+	}
+
+	if err := svc.RepairProfile("../NoWraps"); !errors.Is(err, ErrProfileNameInvalid) {
+		t.Fatalf("expected ErrProfileNameInvalid, got %v", err)
+	}
+}
+
+func TestQuarantineProfileEntryMovesJunkAside(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha.import-1", "savegame"), "slot.sav", "leftover")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha.save-2", "savegame"), "slot.sav", "pending")
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	beginEntry(t, j, journal.Entry{
+		Operation:   journal.OperationSave,
+		Phase:       journal.PhaseStaging,
+		StagingRoot: filepath.Join(profilesPath, "ProfileAlpha.save-2"),
+		TargetRoot:  filepath.Join(profilesPath, "ProfileAlpha"),
+	})
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	svc.SetJournal(j)
+
+	destination, err := svc.QuarantineProfileEntry("ProfileAlpha.import-1")
+	if err != nil {
+		t.Fatalf("quarantine entry: %v", err)
+	}
+
+	if filepath.Dir(destination) != filepath.Join(saveGamePath, QuarantineDirName) || !strings.HasSuffix(destination, "-ProfileAlpha.import-1") {
+		t.Fatalf("unexpected quarantine destination %q", destination)
+	}
+
+	assertFileContent(t, filepath.Join(destination, "savegame", "slot.sav"), "leftover")
+	if _, err := os.Stat(filepath.Join(profilesPath, "ProfileAlpha.import-1")); !os.IsNotExist(err) {
+		t.Fatalf("expected entry to leave the profiles folder, got %v", err)
+	}
+
+	if _, err := svc.QuarantineProfileEntry("ProfileAlpha"); !errors.Is(err, ErrProfileIsValid) {
+		t.Fatalf("expected ErrProfileIsValid, got %v", err)
+	}
+
+	if _, err := svc.QuarantineProfileEntry("ProfileAlpha.save-2"); !errors.Is(err, ErrEntryInUse) {
+		t.Fatalf("expected ErrEntryInUse, got %v", err)
+	}
+
+	if _, err := svc.QuarantineProfileEntry("Missing"); !errors.Is(err, ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+func TestSetActiveProfileRewritesMarkerForValidProfilesOnly(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta")
+	createDirWithFile(t, filepath.Join(profilesPath, "Broken", "savegame"), "slot.sav", "broken")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	if err := svc.SetActiveProfile("Broken"); !errors.Is(err, ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound, got %v", err)
+	}
+
+	if err := svc.SetActiveProfile("ProfileBeta"); err != nil {
+		t.Fatalf("set active profile: %v", err)
+	}
+
+	active, err := store.ReadActiveProfile()
+	if err != nil || active != "ProfileBeta" {
+		t.Fatalf("expected marker ProfileBeta, got %q err=%v", active, err)
+	}
+}
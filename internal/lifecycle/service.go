@@ -1,6 +1,7 @@
 package lifecycle
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -8,7 +9,12 @@ import (
 	"strings"
 	"time"
 
+	"heat-save-manager/internal/backups"
 	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/journal"
+	"heat-save-manager/internal/profiles"
+	"heat-save-manager/internal/savelock"
+	"heat-save-manager/internal/snapshot"
 	"heat-save-manager/internal/switcher"
 )
 
@@ -26,6 +32,8 @@ var (
 	ErrCannotDeleteActiveProfile = errors.New("cannot delete active profile")
 	ErrProfileAlreadyExists      = errors.New("profile already exists")
 	ErrProfileNotFound           = errors.New("profile not found")
+	ErrSnapshotsUnavailable      = errors.New("snapshot history is not configured")
+	ErrSwitchBackupsUnavailable  = errors.New("pre-switch backups are not configured")
 )
 
 const (
@@ -43,6 +51,12 @@ type Service struct {
 	profilesPath string
 	marker       MarkerStore
 	ops          fsops.Operations
+	snapshots    *snapshot.Service
+	guard        switcher.GameGuard
+	backups      *backups.Service
+	journal      *journal.Journal
+	lock         *savelock.Locker
+	now          func() time.Time
 }
 
 func NewService(saveGamePath string, profilesPath string, marker MarkerStore, ops fsops.Operations) *Service {
@@ -51,9 +65,40 @@ func NewService(saveGamePath string, profilesPath string, marker MarkerStore, op
 		profilesPath: profilesPath,
 		marker:       marker,
 		ops:          ops,
+		now:          time.Now,
 	}
 }
 
+// SetSnapshotService enables snapshot history: the stored copy of a profile is
+// kept as a snapshot before it is overwritten by a save or restore.
+func (s *Service) SetSnapshotService(snapshots *snapshot.Service) {
+	s.snapshots = snapshots
+}
+
+// SetGameGuard makes destructive operations on the root save fail while the
+// guard reports the game as running.
+func (s *Service) SetGameGuard(guard switcher.GameGuard) {
+	s.guard = guard
+}
+
+// SetSwitchBackups retains the pre-switch copy of the root save for switches
+// started by this service and enables RestoreSwitchBackup.
+func (s *Service) SetSwitchBackups(store *backups.Service) {
+	s.backups = store
+}
+
+// SetJournal records switches, saves and deletes so an interrupted one can be
+// finished or undone by recovery on the next start.
+func (s *Service) SetJournal(j *journal.Journal) {
+	s.journal = j
+}
+
+// SetLocker makes every mutating operation hold the SaveGame folder lock; the
+// switcher built for a switch shares it.
+func (s *Service) SetLocker(lock *savelock.Locker) {
+	s.lock = lock
+}
+
 func (s *Service) PrepareFreshProfile(profileName string) error {
 	return s.prepareFreshProfile(profileName, true)
 }
@@ -72,6 +117,12 @@ func (s *Service) prepareFreshProfile(profileName string, preserveCurrent bool)
 		return err
 	}
 
+	release, err := s.lock.Acquire("fresh")
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	activeName := ""
 	if preserveCurrent {
 		activeProfile, err := s.marker.ReadActiveProfile()
@@ -97,6 +148,10 @@ func (s *Service) prepareFreshProfile(profileName string, preserveCurrent bool)
 		return err
 	}
 
+	if err := s.CheckGameNotRunning(); err != nil {
+		return err
+	}
+
 	if preserveCurrent {
 		if err := s.SaveCurrentProfile(activeName); err != nil {
 			return err
@@ -115,19 +170,313 @@ func (s *Service) prepareFreshProfile(profileName string, preserveCurrent bool)
 		return err
 	}
 
-	return s.marker.WriteActiveProfile(name)
+	if err := s.marker.WriteActiveProfile(name); err != nil {
+		return err
+	}
+
+	s.touchMetadata(name, func(metadata *profiles.Metadata) {
+		metadata.LastSwitchedAt = s.now().UTC()
+	})
+	return nil
 }
 
 func (s *Service) SaveCurrentProfile(profileName string) error {
+	return s.SaveCurrentProfileContext(context.Background(), profileName)
+}
+
+// SaveCurrentProfileContext is SaveCurrentProfile with cancellation and
+// progress reporting through ctx. Cancelling leaves the stored profile as it
+// was.
+func (s *Service) SaveCurrentProfileContext(ctx context.Context, profileName string) error {
 	if err := s.validateDependencies(); err != nil {
 		return err
 	}
 
+	release, err := s.lock.Acquire("save")
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	name, err := s.resolveProfileName(profileName)
 	if err != nil {
 		return err
 	}
 
+	if err := s.ensureRootFolders(); err != nil {
+		return err
+	}
+
+	if err := fsops.Plan(ctx, filepath.Join(s.saveGamePath, savegameDirName), filepath.Join(s.saveGamePath, wrapsDirName)); err != nil {
+		return err
+	}
+
+	targetRoot := filepath.Join(s.profilesPath, name)
+	record, err := s.journal.Begin(journal.Entry{
+		Operation:  journal.OperationSave,
+		Phase:      journal.PhaseStaging,
+		Profile:    name,
+		TargetRoot: targetRoot,
+	})
+	if err != nil {
+		return fmt.Errorf("record save: %w", err)
+	}
+
+	stagingRoot, err := s.stageRoot(ctx, name, record)
+	if err != nil {
+		_ = record.Finish()
+		return err
+	}
+	defer os.RemoveAll(stagingRoot)
+
+	if err := ctx.Err(); err != nil {
+		_ = record.Finish()
+		return err
+	}
+
+	if err := s.snapshotExisting(name, "save"); err != nil {
+		_ = record.Finish()
+		return err
+	}
+
+	if err := record.Advance(journal.PhaseStaged, nil); err != nil {
+		_ = record.Finish()
+		return fmt.Errorf("record save: %w", err)
+	}
+
+	if err := journal.Replace(record, targetRoot, stagingRoot); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SwitchProfile swaps the target profile into the root save. When
+// saveOutgoing is set, the root is first written back into the active profile
+// with the same staging as SaveCurrentProfile; if the switch then fails, the
+// active profile's previous copy is put back alongside the root rollback.
+func (s *Service) SwitchProfile(profileName string, saveOutgoing bool) (switcher.Result, error) {
+	return s.SwitchProfileContext(context.Background(), profileName, saveOutgoing)
+}
+
+// SwitchProfileContext is SwitchProfile with cancellation and progress
+// reporting through ctx. A cancelled switch is rolled back like a failed one.
+func (s *Service) SwitchProfileContext(ctx context.Context, profileName string, saveOutgoing bool) (switcher.Result, error) {
+	if err := s.validateDependencies(); err != nil {
+		return switcher.Result{}, err
+	}
+
+	release, err := s.lock.Acquire("switch")
+	if err != nil {
+		return switcher.Result{}, err
+	}
+	defer release()
+
+	name, err := validateProfileName(profileName)
+	if err != nil {
+		return switcher.Result{}, err
+	}
+
+	if err := profiles.ValidateLayout(filepath.Join(s.profilesPath, name)); err != nil {
+		return switcher.Result{}, err
+	}
+
+	if err := s.CheckGameNotRunning(); err != nil {
+		return switcher.Result{}, err
+	}
+
+	outgoingName := ""
+	if saveOutgoing {
+		if outgoingName, err = s.outgoingProfile(); err != nil {
+			return switcher.Result{}, fmt.Errorf("save outgoing profile: %w", err)
+		}
+	}
+
+	// The switch moves the root folders aside instead of copying them, so
+	// only the profile and a saved outgoing copy count towards progress.
+	planned := []string{filepath.Join(s.profilesPath, name, savegameDirName), filepath.Join(s.profilesPath, name, wrapsDirName)}
+	if outgoingName != "" {
+		planned = append(planned, filepath.Join(s.saveGamePath, savegameDirName), filepath.Join(s.saveGamePath, wrapsDirName))
+	}
+
+	if err := fsops.Plan(ctx, planned...); err != nil {
+		return switcher.Result{}, err
+	}
+
+	var outgoing *journal.Replacement
+	if outgoingName != "" {
+		outgoing, err = s.saveOutgoingProfile(ctx, outgoingName, name)
+		if err != nil {
+			return switcher.Result{}, fmt.Errorf("save outgoing profile: %w", err)
+		}
+	}
+
+	result, err := s.newSwitcher().SwitchContext(ctx, switcher.Params{ProfileName: name})
+	if err != nil {
+		if outgoing != nil {
+			if rollbackErr := outgoing.Rollback(); rollbackErr != nil {
+				return result, fmt.Errorf("%w; outgoing profile rollback failed: %v", err, rollbackErr)
+			}
+		}
+
+		return result, err
+	}
+
+	if outgoing != nil {
+		if err := outgoing.Commit(); err != nil {
+			return result, err
+		}
+	}
+
+	s.touchMetadata(name, func(metadata *profiles.Metadata) {
+		metadata.LastSwitchedAt = s.now().UTC()
+	})
+	return result, nil
+}
+
+// ReloadProfileContext loads the stored copy of profileName into the root
+// save without saving the root first, for when the stored copy has just been
+// replaced from outside, such as by an import.
+func (s *Service) ReloadProfileContext(ctx context.Context, profileName string) error {
+	_, err := s.SwitchProfileContext(ctx, profileName, false)
+	return err
+}
+
+// UpdateProfileDetails replaces the description, tags and colour of a
+// profile, leaving its timestamps alone.
+func (s *Service) UpdateProfileDetails(profileName string, details profiles.Details) error {
+	if err := s.validateDependencies(); err != nil {
+		return err
+	}
+
+	release, err := s.lock.Acquire("edit-details")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	name, err := validateProfileName(profileName)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := profiles.NormalizeDetails(details)
+	if err != nil {
+		return err
+	}
+
+	profileRoot := filepath.Join(s.profilesPath, name)
+	if err := ensureDirExists(profileRoot); err != nil {
+		if os.IsNotExist(err) {
+			return ErrProfileNotFound
+		}
+		return err
+	}
+
+	return profiles.UpdateMetadata(profileRoot, func(metadata *profiles.Metadata) {
+		metadata.Description = normalized.Description
+		metadata.Tags = normalized.Tags
+		metadata.Color = normalized.Color
+	})
+}
+
+// SetActiveProfile rewrites the active marker without touching any saves, for
+// fixing a marker that is missing or names the wrong profile.
+func (s *Service) SetActiveProfile(profileName string) error {
+	if err := s.validateDependencies(); err != nil {
+		return err
+	}
+
+	release, err := s.lock.Acquire("set-marker")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	name, err := validateProfileName(profileName)
+	if err != nil {
+		return err
+	}
+
+	if profiles.Inspect(filepath.Join(s.profilesPath, name)).Status != profiles.StatusValid {
+		return ErrProfileNotFound
+	}
+
+	return s.marker.WriteActiveProfile(name)
+}
+
+// touchMetadata updates bookkeeping timestamps after an operation has
+// finished. A failure here must not report the finished operation as failed,
+// so it is dropped.
+func (s *Service) touchMetadata(profileName string, update func(*profiles.Metadata)) {
+	profileRoot := filepath.Join(s.profilesPath, profileName)
+	if !dirExists(profileRoot) {
+		return
+	}
+
+	_ = profiles.UpdateMetadata(profileRoot, update)
+}
+
+// outgoingProfile names the profile a switch should save the root into first,
+// or returns "" when there is nothing to save: no marker, a marker naming no
+// valid profile, or a root save without both folders. Switching to the
+// active profile itself still saves it, so its progress is not overwritten by
+// the stored copy.
+func (s *Service) outgoingProfile() (string, error) {
+	active, err := s.marker.ReadActiveProfile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	activeName, err := validateProfileName(active)
+	if err != nil {
+		return "", nil
+	}
+
+	if !dirExists(filepath.Join(s.saveGamePath, savegameDirName)) || !dirExists(filepath.Join(s.saveGamePath, wrapsDirName)) {
+		return "", nil
+	}
+
+	return activeName, nil
+}
+
+func (s *Service) saveOutgoingProfile(ctx context.Context, activeName string, targetName string) (*journal.Replacement, error) {
+	targetRoot := filepath.Join(s.profilesPath, activeName)
+	record, err := s.journal.Begin(journal.Entry{
+		Operation:    journal.OperationSaveOutgoing,
+		Phase:        journal.PhaseStaging,
+		Profile:      activeName,
+		SwitchTarget: targetName,
+		TargetRoot:   targetRoot,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("record save: %w", err)
+	}
+
+	stagingRoot, err := s.stageRoot(ctx, activeName, record)
+	if err != nil {
+		_ = record.Finish()
+		return nil, err
+	}
+	defer os.RemoveAll(stagingRoot)
+
+	if err := s.snapshotExisting(activeName, "switch"); err != nil {
+		_ = record.Finish()
+		return nil, err
+	}
+
+	if err := record.Advance(journal.PhaseStaged, nil); err != nil {
+		_ = record.Finish()
+		return nil, fmt.Errorf("record save: %w", err)
+	}
+
+	return journal.BeginReplace(record, targetRoot, stagingRoot)
+}
+
+func (s *Service) ensureRootFolders() error {
 	if err := ensureDirExists(filepath.Join(s.saveGamePath, savegameDirName)); err != nil {
 		if os.IsNotExist(err) {
 			return ErrRootSavegameMissing
@@ -142,28 +491,228 @@ func (s *Service) SaveCurrentProfile(profileName string) error {
 		return err
 	}
 
+	return nil
+}
+
+// stageRoot copies the root savegame and wraps folders into a new staging
+// folder next to the profiles. Files unchanged since the profile's last save
+// are taken over from its stored copy instead of being written again. The
+// caller owns and removes the result; record learns the staging path before
+// anything is copied into it.
+func (s *Service) stageRoot(ctx context.Context, profileName string, record *journal.Record) (string, error) {
+	if err := os.MkdirAll(s.profilesPath, 0o755); err != nil {
+		return "", err
+	}
+
+	stagingRoot, err := os.MkdirTemp(s.profilesPath, profileName+".save-*")
+	if err != nil {
+		return "", err
+	}
+
+	err = record.Advance(journal.PhaseStaging, func(entry *journal.Entry) {
+		entry.StagingRoot = stagingRoot
+	})
+	if err != nil {
+		_ = os.RemoveAll(stagingRoot)
+		return "", fmt.Errorf("record staging: %w", err)
+	}
+
+	profileRoot := filepath.Join(s.profilesPath, profileName)
+	if err := s.ops.SyncDir(ctx, filepath.Join(s.saveGamePath, savegameDirName), filepath.Join(stagingRoot, savegameDirName), filepath.Join(profileRoot, savegameDirName)); err != nil {
+		_ = os.RemoveAll(stagingRoot)
+		return "", err
+	}
+
+	if err := s.ops.SyncDir(ctx, filepath.Join(s.saveGamePath, wrapsDirName), filepath.Join(stagingRoot, wrapsDirName), filepath.Join(profileRoot, wrapsDirName)); err != nil {
+		_ = os.RemoveAll(stagingRoot)
+		return "", err
+	}
+
+	// The staged copy replaces the whole profile folder, so it carries the
+	// metadata along, stamped with this save.
+	if err := profiles.CopyMetadata(profileRoot, stagingRoot); err != nil {
+		_ = os.RemoveAll(stagingRoot)
+		return "", err
+	}
+
+	now := s.now().UTC()
+	err = profiles.UpdateMetadata(stagingRoot, func(metadata *profiles.Metadata) {
+		if metadata.CreatedAt.IsZero() {
+			metadata.CreatedAt = now
+		}
+		metadata.LastSavedAt = now
+	})
+	if err != nil {
+		_ = os.RemoveAll(stagingRoot)
+		return "", err
+	}
+
+	return stagingRoot, nil
+}
+
+// RestoreSnapshot replaces the stored copy of profileName with one of its
+// snapshots, taking a snapshot of the copy it replaces first. The replacement
+// is journaled like a save.
+func (s *Service) RestoreSnapshot(profileName string, snapshotID string) error {
+	return s.RestoreSnapshotContext(context.Background(), profileName, snapshotID)
+}
+
+// RestoreSnapshotContext is RestoreSnapshot with cancellation through ctx.
+// Restoring the active profile saves the root save into it before the
+// pre-restore snapshot is taken, so that progress is kept in the snapshot, and
+// reloads the root save from the restored copy afterwards.
+func (s *Service) RestoreSnapshotContext(ctx context.Context, profileName string, snapshotID string) error {
+	if err := s.validateDependencies(); err != nil {
+		return err
+	}
+
+	release, err := s.lock.Acquire("restore-snapshot")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if s.snapshots == nil {
+		return ErrSnapshotsUnavailable
+	}
+
+	name, err := validateProfileName(profileName)
+	if err != nil {
+		return err
+	}
+
+	active := s.isActiveProfile(name)
+	if active {
+		if err := s.CheckGameNotRunning(); err != nil {
+			return err
+		}
+	}
+
 	if err := os.MkdirAll(s.profilesPath, 0o755); err != nil {
 		return err
 	}
 
-	stagingRoot, err := os.MkdirTemp(s.profilesPath, name+".save-*")
+	targetRoot := filepath.Join(s.profilesPath, name)
+	record, err := s.journal.Begin(journal.Entry{
+		Operation:  journal.OperationRestoreSnapshot,
+		Phase:      journal.PhaseStaging,
+		Profile:    name,
+		TargetRoot: targetRoot,
+	})
+	if err != nil {
+		return fmt.Errorf("record restore: %w", err)
+	}
+
+	stagingRoot, err := os.MkdirTemp(s.profilesPath, name+".restore-*")
 	if err != nil {
+		_ = record.Finish()
 		return err
 	}
 	defer os.RemoveAll(stagingRoot)
 
-	if err := s.ops.ReplaceDir(filepath.Join(s.saveGamePath, savegameDirName), filepath.Join(stagingRoot, savegameDirName)); err != nil {
+	err = record.Advance(journal.PhaseStaging, func(entry *journal.Entry) {
+		entry.StagingRoot = stagingRoot
+	})
+	if err != nil {
+		_ = record.Finish()
+		return fmt.Errorf("record staging: %w", err)
+	}
+
+	if err := s.snapshots.CopyTo(name, snapshotID, stagingRoot); err != nil {
+		_ = record.Finish()
+		return err
+	}
+
+	if active && dirExists(filepath.Join(s.saveGamePath, savegameDirName)) && dirExists(filepath.Join(s.saveGamePath, wrapsDirName)) {
+		if err := s.SaveCurrentProfileContext(ctx, name); err != nil {
+			_ = record.Finish()
+			return fmt.Errorf("save active profile before restore: %w", err)
+		}
+	}
+
+	if err := profiles.CopyMetadata(targetRoot, stagingRoot); err != nil {
+		_ = record.Finish()
 		return err
 	}
 
-	if err := s.ops.ReplaceDir(filepath.Join(s.saveGamePath, wrapsDirName), filepath.Join(stagingRoot, wrapsDirName)); err != nil {
+	if err := s.snapshotExisting(name, "restore"); err != nil {
+		_ = record.Finish()
 		return err
 	}
 
-	if err := replaceProfileRootAtomic(filepath.Join(s.profilesPath, name), stagingRoot); err != nil {
+	if err := record.Advance(journal.PhaseStaged, nil); err != nil {
+		_ = record.Finish()
+		return fmt.Errorf("record restore: %w", err)
+	}
+
+	if err := journal.Replace(record, targetRoot, stagingRoot); err != nil {
 		return err
 	}
 
+	if !active {
+		return nil
+	}
+
+	if err := s.ReloadProfileContext(ctx, name); err != nil {
+		return fmt.Errorf("reload root save from restored profile: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreSwitchBackup puts a retained pre-switch backup back into the root
+// save and points the marker at the profile that owned that progress.
+func (s *Service) RestoreSwitchBackup(backupID string) error {
+	return s.RestoreSwitchBackupContext(context.Background(), backupID)
+}
+
+// RestoreSwitchBackupContext is RestoreSwitchBackup with cancellation through
+// ctx. The backup store swaps both root folders in one journaled step and
+// updates the marker as part of it.
+func (s *Service) RestoreSwitchBackupContext(ctx context.Context, backupID string) error {
+	if err := s.validateDependencies(); err != nil {
+		return err
+	}
+
+	release, err := s.lock.Acquire("restore-backup")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if s.backups == nil {
+		return ErrSwitchBackupsUnavailable
+	}
+
+	if err := s.CheckGameNotRunning(); err != nil {
+		return err
+	}
+
+	currentProfile := ""
+	if active, err := s.marker.ReadActiveProfile(); err == nil {
+		currentProfile = strings.TrimSpace(active)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	_, err = s.backups.Restore(ctx, backupID, s.saveGamePath, currentProfile)
+	return err
+}
+
+func (s *Service) snapshotExisting(profileName string, reason string) error {
+	if s.snapshots == nil {
+		return nil
+	}
+
+	profileRoot := filepath.Join(s.profilesPath, profileName)
+	if err := profiles.ValidateLayout(profileRoot); err != nil {
+		return nil
+	}
+
+	if _, err := s.snapshots.Create(profileName, profileRoot, reason); err != nil {
+		return fmt.Errorf("snapshot profile before %s: %w", reason, err)
+	}
+
 	return nil
 }
 
@@ -172,6 +721,12 @@ func (s *Service) RenameProfile(oldName string, newName string) error {
 		return err
 	}
 
+	release, err := s.lock.Acquire("rename")
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	oldTrimmed, err := validateProfileName(oldName)
 	if err != nil {
 		return err
@@ -198,21 +753,27 @@ func (s *Service) RenameProfile(oldName string, newName string) error {
 		return err
 	}
 
-	if err := os.Rename(oldPath, newPath); err != nil {
+	active, err := s.marker.ReadActiveProfile()
+	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
+	wasActive := err == nil && strings.EqualFold(strings.TrimSpace(active), oldTrimmed)
 
-	active, err := s.marker.ReadActiveProfile()
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
+	// The history moves first: it is the step most likely to be refused, and
+	// nothing else has changed yet if it is.
+	if err := s.renameSnapshots(oldTrimmed, newTrimmed); err != nil {
+		return err
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		_ = s.renameSnapshots(newTrimmed, oldTrimmed)
 		return err
 	}
 
-	if strings.EqualFold(strings.TrimSpace(active), oldTrimmed) {
+	if wasActive {
 		if err := s.marker.WriteActiveProfile(newTrimmed); err != nil {
 			_ = os.Rename(newPath, oldPath)
+			_ = s.renameSnapshots(newTrimmed, oldTrimmed)
 			return err
 		}
 	}
@@ -220,11 +781,29 @@ func (s *Service) RenameProfile(oldName string, newName string) error {
 	return nil
 }
 
+func (s *Service) renameSnapshots(oldName string, newName string) error {
+	if s.snapshots == nil {
+		return nil
+	}
+
+	if err := s.snapshots.Rename(oldName, newName); err != nil {
+		return fmt.Errorf("rename snapshot history: %w", err)
+	}
+
+	return nil
+}
+
 func (s *Service) DeleteProfile(profileName string) error {
 	if err := s.validateDependencies(); err != nil {
 		return err
 	}
 
+	release, err := s.lock.Acquire("delete")
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	name, err := validateProfileName(profileName)
 	if err != nil {
 		return err
@@ -247,7 +826,58 @@ func (s *Service) DeleteProfile(profileName string) error {
 		return err
 	}
 
-	return s.ops.RemoveDir(profilePath)
+	// The folder is parked under a temporary name first so an interrupted
+	// removal never leaves a half-deleted profile that still looks valid.
+	stagingPath, err := s.reserveDeletePath(name)
+	if err != nil {
+		return err
+	}
+
+	record, err := s.journal.Begin(journal.Entry{
+		Operation:  journal.OperationDelete,
+		Phase:      journal.PhaseReplacing,
+		Profile:    name,
+		TargetRoot: profilePath,
+		ParkedRoot: stagingPath,
+	})
+	if err != nil {
+		return fmt.Errorf("record delete: %w", err)
+	}
+
+	if err := os.Rename(profilePath, stagingPath); err != nil {
+		_ = record.Finish()
+		return err
+	}
+
+	if err := record.Advance(journal.PhaseRemoving, nil); err != nil {
+		if rollbackErr := os.Rename(stagingPath, profilePath); rollbackErr != nil {
+			return fmt.Errorf("record delete: %w; restore failed: %v", err, rollbackErr)
+		}
+
+		_ = record.Finish()
+		return fmt.Errorf("record delete: %w", err)
+	}
+
+	if err := s.ops.RemoveDir(stagingPath); err != nil {
+		return err
+	}
+
+	_ = record.Finish()
+	return s.deleteSnapshots(name)
+}
+
+// reserveDeletePath picks an unused <name>.delete-* path next to the profile.
+func (s *Service) reserveDeletePath(profileName string) (string, error) {
+	stagingPath, err := os.MkdirTemp(s.profilesPath, profileName+".delete-*")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(stagingPath); err != nil {
+		return "", err
+	}
+
+	return stagingPath, nil
 }
 
 func (s *Service) DeleteActiveProfile(replacementProfileName string) error {
@@ -255,6 +885,12 @@ func (s *Service) DeleteActiveProfile(replacementProfileName string) error {
 		return err
 	}
 
+	release, err := s.lock.Acquire("delete")
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	active, err := s.marker.ReadActiveProfile()
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -294,39 +930,98 @@ func (s *Service) DeleteActiveProfile(replacementProfileName string) error {
 		return err
 	}
 
-	stagingPath, err := os.MkdirTemp(s.profilesPath, activeName+".delete-*")
-	if err != nil {
+	if err := s.CheckGameNotRunning(); err != nil {
 		return err
 	}
 
-	if err := os.Remove(stagingPath); err != nil {
+	stagingPath, err := s.reserveDeletePath(activeName)
+	if err != nil {
 		return err
 	}
 
+	record, err := s.journal.Begin(journal.Entry{
+		Operation:    journal.OperationDelete,
+		Phase:        journal.PhaseReplacing,
+		Profile:      activeName,
+		SwitchTarget: replacementName,
+		TargetRoot:   activePath,
+		ParkedRoot:   stagingPath,
+	})
+	if err != nil {
+		return fmt.Errorf("record delete: %w", err)
+	}
+
 	if err := os.Rename(activePath, stagingPath); err != nil {
+		_ = record.Finish()
 		return err
 	}
 
-	switchService := switcher.NewService(s.saveGamePath, s.profilesPath, s.marker, s.ops)
-	if _, err := switchService.Switch(switcher.Params{ProfileName: replacementName}); err != nil {
+	if err := record.Advance(journal.PhaseReplaced, nil); err != nil {
+		if rollbackErr := os.Rename(stagingPath, activePath); rollbackErr != nil {
+			return fmt.Errorf("record delete: %w; restore failed: %v", err, rollbackErr)
+		}
+
+		_ = record.Finish()
+		return fmt.Errorf("record delete: %w", err)
+	}
+
+	if _, err := s.newSwitcher().Switch(switcher.Params{ProfileName: replacementName}); err != nil {
 		if rollbackErr := os.Rename(stagingPath, activePath); rollbackErr != nil {
 			return fmt.Errorf("delete active profile switch failed: %w; rollback failed: %v", err, rollbackErr)
 		}
 
+		_ = record.Finish()
 		return err
 	}
 
+	if err := record.Advance(journal.PhaseRemoving, nil); err != nil {
+		if rollbackErr := os.Rename(stagingPath, activePath); rollbackErr != nil {
+			return fmt.Errorf("record delete: %w; restore failed: %v", err, rollbackErr)
+		}
+
+		_ = record.Finish()
+		return fmt.Errorf("record delete: %w", err)
+	}
+
 	if err := s.ops.RemoveDir(stagingPath); err != nil {
 		if rollbackErr := os.Rename(stagingPath, activePath); rollbackErr != nil {
 			return fmt.Errorf("delete active profile cleanup failed: %w; restore failed: %v", err, rollbackErr)
 		}
 
+		_ = record.Finish()
 		return err
 	}
 
+	_ = record.Finish()
+	return s.deleteSnapshots(activeName)
+}
+
+// deleteSnapshots drops the history of a deleted profile so a new profile of
+// the same name does not inherit it and a rename to that name is not refused.
+func (s *Service) deleteSnapshots(profileName string) error {
+	if s.snapshots == nil {
+		return nil
+	}
+
+	if err := s.snapshots.DeleteHistory(profileName); err != nil {
+		return fmt.Errorf("remove snapshot history: %w", err)
+	}
+
 	return nil
 }
 
+func (s *Service) newSwitcher() *switcher.Service {
+	switchService := switcher.NewService(s.saveGamePath, s.profilesPath, s.marker, s.ops)
+	switchService.SetGameGuard(s.guard)
+	switchService.SetJournal(s.journal)
+	switchService.SetLocker(s.lock)
+	if s.backups != nil {
+		switchService.SetBackupKeeper(s.backups)
+	}
+
+	return switchService
+}
+
 func (s *Service) resolveProfileName(profileName string) (string, error) {
 	trimmed := strings.TrimSpace(profileName)
 	if trimmed != "" {
@@ -352,6 +1047,16 @@ func (s *Service) ensureFreshProfileDoesNotExist(profileName string) error {
 	return nil
 }
 
+// CheckGameNotRunning refuses with gameproc.ErrGameRunning while the game is
+// open, when a game guard is set.
+func (s *Service) CheckGameNotRunning() error {
+	if s.guard == nil {
+		return nil
+	}
+
+	return s.guard.Check()
+}
+
 func (s *Service) validateDependencies() error {
 	if strings.TrimSpace(s.saveGamePath) == "" {
 		return ErrSaveGamePathRequired
@@ -386,6 +1091,10 @@ func validateProfileName(profileName string) (string, error) {
 		return "", ErrProfileNameInvalid
 	}
 
+	if profiles.IsArtifactName(trimmed) {
+		return "", profiles.ErrReservedProfileName
+	}
+
 	return trimmed, nil
 }
 
@@ -402,6 +1111,11 @@ func ensureDirExists(path string) error {
 	return nil
 }
 
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
 func clearDirContents(path string) error {
 	if err := ensureDirExists(path); err != nil {
 		return err
@@ -421,41 +1135,3 @@ func clearDirContents(path string) error {
 
 	return nil
 }
-
-func replaceProfileRootAtomic(targetRoot string, stagingRoot string) error {
-	backupRoot := targetRoot + ".backup-" + time.Now().UTC().Format("20060102-150405.000000000")
-	hadExisting := false
-
-	if info, err := os.Stat(targetRoot); err == nil {
-		if !info.IsDir() {
-			return fmt.Errorf("profile path exists but is not a directory: %s", targetRoot)
-		}
-		hadExisting = true
-		if err := os.RemoveAll(backupRoot); err != nil {
-			return err
-		}
-		if err := os.Rename(targetRoot, backupRoot); err != nil {
-			return err
-		}
-	} else if !os.IsNotExist(err) {
-		return err
-	}
-
-	if err := os.Rename(stagingRoot, targetRoot); err != nil {
-		if hadExisting {
-			if rollbackErr := os.Rename(backupRoot, targetRoot); rollbackErr != nil {
-				return fmt.Errorf("replace profile failed: %w; rollback failed: %v", err, rollbackErr)
-			}
-		}
-
-		return err
-	}
-
-	if hadExisting {
-		if err := os.RemoveAll(backupRoot); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
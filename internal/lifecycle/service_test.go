@@ -1,14 +1,23 @@
 package lifecycle
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"heat-save-manager/internal/backups"
 	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/gameproc"
+	"heat-save-manager/internal/journal"
 	"heat-save-manager/internal/marker"
+	"heat-save-manager/internal/profiles"
+	"heat-save-manager/internal/savelock"
+	"heat-save-manager/internal/snapshot"
+	"heat-save-manager/internal/switcher"
 )
 
 func TestPrepareFreshProfilePreserveUpdatesActiveAndCreatesFreshProfile(t *testing.T) {
@@ -205,6 +214,200 @@ func TestSaveCurrentProfileKeepsExistingProfileWhenSecondReplaceFails(t *testing
 	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps", "wrap.txt"), "old-wrap")
 }
 
+func TestSaveCurrentProfileSnapshotsPreviousCopyAndRestoreBringsItBack(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "corrupted-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "new-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "good-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "good-wrap")
+
+	snapshots := snapshot.NewService(filepath.Join(saveGamePath, snapshot.DirName), fsops.NewLocal())
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	svc.SetSnapshotService(snapshots)
+
+	if err := svc.SaveCurrentProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("save current profile: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "corrupted-save")
+
+	items, err := snapshots.List("ProfileAlpha")
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+
+	if len(items) != 1 || items[0].Reason != "save" {
+		t.Fatalf("expected one save snapshot, got %+v", items)
+	}
+
+	if err := svc.RestoreSnapshot("ProfileAlpha", items[0].ID); err != nil {
+		t.Fatalf("restore snapshot: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "good-save")
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps", "wrap.txt"), "good-wrap")
+
+	items, err = snapshots.List("ProfileAlpha")
+	if err != nil {
+		t.Fatalf("list snapshots after restore: %v", err)
+	}
+
+	if len(items) != 2 || items[0].Reason != "restore" {
+		t.Fatalf("expected restore to snapshot the overwritten copy, got %+v", items)
+	}
+}
+
+func TestRepeatedUnchangedSaveCopiesNoFileData(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "root-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "root-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "old-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "old-wrap")
+
+	snapshots := snapshot.NewService(filepath.Join(saveGamePath, snapshot.DirName), fsops.NewLocal())
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	svc.SetSnapshotService(snapshots)
+
+	// The first save brings the stored copy up to date; the second one
+	// snapshots it for the first time.
+	for i := 0; i < 2; i++ {
+		if err := svc.SaveCurrentProfile("ProfileAlpha"); err != nil {
+			t.Fatalf("save %d: %v", i+1, err)
+		}
+	}
+
+	files := []string{filepath.Join("savegame", "slot.sav"), filepath.Join("wraps", "wrap.txt")}
+	latestSnapshot := func() string {
+		t.Helper()
+		items, err := snapshots.List("ProfileAlpha")
+		if err != nil || len(items) == 0 {
+			t.Fatalf("list snapshots: %v %+v", err, items)
+		}
+		return filepath.Join(saveGamePath, snapshot.DirName, "ProfileAlpha", items[0].ID)
+	}
+
+	profileRoot := filepath.Join(profilesPath, "ProfileAlpha")
+	previousSnapshot := latestSnapshot()
+	before := map[string]os.FileInfo{}
+	for _, file := range files {
+		before[file] = statPath(t, filepath.Join(profileRoot, file))
+	}
+
+	if err := svc.SaveCurrentProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("unchanged save: %v", err)
+	}
+
+	currentSnapshot := latestSnapshot()
+	if currentSnapshot == previousSnapshot {
+		t.Fatal("expected the unchanged save to take a new snapshot")
+	}
+
+	for _, file := range files {
+		if !os.SameFile(before[file], statPath(t, filepath.Join(profileRoot, file))) {
+			t.Fatalf("expected stored %s to be reused, not copied", file)
+		}
+
+		if !os.SameFile(statPath(t, filepath.Join(previousSnapshot, file)), statPath(t, filepath.Join(currentSnapshot, file))) {
+			t.Fatalf("expected snapshot %s to be linked from the previous snapshot, not copied", file)
+		}
+	}
+}
+
+func statPath(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat %s: %v", path, err)
+	}
+
+	return info
+}
+
+func TestRestoreSnapshotOfActiveProfileReloadsRootSave(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "good-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "good-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "older-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "older-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	j := journal.New(filepath.Join(saveGamePath, journal.DirName))
+	snapshots := snapshot.NewService(filepath.Join(saveGamePath, snapshot.DirName), fsops.NewLocal())
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetSnapshotService(snapshots)
+	svc.SetJournal(j)
+
+	// The save snapshots the older copy, which is then restored over the
+	// newer progress still sitting in the root save.
+	if err := svc.SaveCurrentProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("save current profile: %v", err)
+	}
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "latest-save")
+
+	items, err := snapshots.List("ProfileAlpha")
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one snapshot, got %+v (%v)", items, err)
+	}
+
+	if err := svc.RestoreSnapshot("ProfileAlpha", items[0].ID); err != nil {
+		t.Fatalf("restore snapshot: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "older-save")
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "older-save")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "older-wrap")
+	assertNoPendingEntries(t, j)
+
+	// The root progress the restore replaced was saved into a snapshot.
+	items, err = snapshots.List("ProfileAlpha")
+	if err != nil {
+		t.Fatalf("list snapshots after restore: %v", err)
+	}
+
+	if len(items) == 0 || items[0].Reason != "restore" {
+		t.Fatalf("expected a restore snapshot, got %+v", items)
+	}
+
+	latest := t.TempDir()
+	if err := snapshots.CopyTo("ProfileAlpha", items[0].ID, latest); err != nil {
+		t.Fatalf("copy restore snapshot: %v", err)
+	}
+	assertFileContent(t, filepath.Join(latest, "savegame", "slot.sav"), "latest-save")
+}
+
+func TestRestoreSnapshotRequiresSnapshotService(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	if err := svc.RestoreSnapshot("ProfileAlpha", "20260301-100000000"); !errors.Is(err, ErrSnapshotsUnavailable) {
+		t.Fatalf("expected ErrSnapshotsUnavailable, got %v", err)
+	}
+}
+
 func TestSaveCurrentProfileUsesActiveMarkerNameWhenEmpty(t *testing.T) {
 	t.Parallel()
 
@@ -481,7 +684,13 @@ func TestRenameProfileRollsBackFolderWhenMarkerWriteFails(t *testing.T) {
 	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "save")
 	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "wrap")
 
+	snapshots := snapshot.NewService(filepath.Join(saveGamePath, snapshot.DirName), fsops.NewLocal())
+	if _, err := snapshots.Create("ProfileAlpha", filepath.Join(profilesPath, "ProfileAlpha"), "manual"); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
 	svc := NewService(saveGamePath, profilesPath, failingRenameMarker{}, fsops.NewLocal())
+	svc.SetSnapshotService(snapshots)
 	err := svc.RenameProfile("ProfileAlpha", "ProfileDelta")
 	if err == nil {
 		t.Fatal("expected rename to fail when marker update fails")
@@ -494,125 +703,797 @@ func TestRenameProfileRollsBackFolderWhenMarkerWriteFails(t *testing.T) {
 	if _, statErr := os.Stat(filepath.Join(profilesPath, "ProfileDelta")); !os.IsNotExist(statErr) {
 		t.Fatalf("expected new folder rolled back, got %v", statErr)
 	}
-}
-
-func TestPrepareFreshProfileRejectsInvalidName(t *testing.T) {
-	t.Parallel()
 
-	svc := NewService("save", "profiles", marker.NewStore(t.TempDir()), fsops.NewLocal())
-	err := svc.PrepareFreshProfile("BAD/NAME")
-	if !errors.Is(err, ErrProfileNameInvalid) {
-		t.Fatalf("expected ErrProfileNameInvalid, got %v", err)
+	items, err := snapshots.List("ProfileAlpha")
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected snapshot history moved back, got %+v, %v", items, err)
 	}
 }
 
-func TestPrepareFreshProfileWithoutPreserveClearsRootAndCreatesEmptyProfile(t *testing.T) {
+func TestRenameProfileChangesNothingWhenSnapshotHistoryCannotMove(t *testing.T) {
 	t.Parallel()
 
 	root := t.TempDir()
 	saveGamePath := filepath.Join(root, "SaveGame")
 	profilesPath := filepath.Join(saveGamePath, "Profiles")
-
-	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "old-save")
-	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "old-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "wrap")
 
 	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	snapshots := snapshot.NewService(filepath.Join(saveGamePath, snapshot.DirName), fsops.NewLocal())
+	for _, name := range []string{"ProfileAlpha", "ProfileDelta"} {
+		if _, err := snapshots.Create(name, filepath.Join(profilesPath, "ProfileAlpha"), "manual"); err != nil {
+			t.Fatalf("create snapshot for %s: %v", name, err)
+		}
+	}
+
 	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetSnapshotService(snapshots)
+	if err := svc.RenameProfile("ProfileAlpha", "ProfileDelta"); err == nil {
+		t.Fatal("expected rename to fail when history exists for the new name")
+	}
 
-	if err := svc.PrepareFreshProfileWithoutSave("fresh-empty"); err != nil {
-		t.Fatalf("prepare fresh profile without preserve: %v", err)
+	if _, err := os.Stat(filepath.Join(profilesPath, "ProfileAlpha")); err != nil {
+		t.Fatalf("expected profile folder left in place, got %v", err)
 	}
 
-	assertDirEmpty(t, filepath.Join(saveGamePath, "savegame"))
-	assertDirEmpty(t, filepath.Join(saveGamePath, "wraps"))
-	assertDirEmpty(t, filepath.Join(profilesPath, "fresh-empty", "savegame"))
-	assertDirEmpty(t, filepath.Join(profilesPath, "fresh-empty", "wraps"))
+	if _, err := os.Stat(filepath.Join(profilesPath, "ProfileDelta")); !os.IsNotExist(err) {
+		t.Fatalf("expected no new profile folder, got %v", err)
+	}
 
 	active, err := store.ReadActiveProfile()
-	if err != nil {
-		t.Fatalf("read marker: %v", err)
+	if err != nil || active != "ProfileAlpha" {
+		t.Fatalf("expected active marker ProfileAlpha, got %q, %v", active, err)
 	}
 
-	if active != "fresh-empty" {
-		t.Fatalf("expected active profile fresh-empty, got %q", active)
+	items, err := snapshots.List("ProfileAlpha")
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected snapshot history left in place, got %+v, %v", items, err)
 	}
 }
 
-func createDirWithFile(t *testing.T, dir string, fileName string, content string) {
-	t.Helper()
+func TestDeleteProfileRemovesSnapshotHistory(t *testing.T) {
+	t.Parallel()
 
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		t.Fatalf("mkdir %s: %v", dir, err)
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	snapshots := snapshot.NewService(filepath.Join(saveGamePath, snapshot.DirName), fsops.NewLocal())
+	for _, name := range []string{"ProfileAlpha", "ProfileBeta"} {
+		if _, err := snapshots.Create(name, filepath.Join(profilesPath, name), "manual"); err != nil {
+			t.Fatalf("create snapshot for %s: %v", name, err)
+		}
 	}
 
-	path := filepath.Join(dir, fileName)
-	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
-		t.Fatalf("write file %s: %v", path, err)
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	svc.SetSnapshotService(snapshots)
+	if err := svc.DeleteProfile("ProfileBeta"); err != nil {
+		t.Fatalf("delete profile: %v", err)
 	}
-}
 
-func assertFileContent(t *testing.T, path string, expected string) {
-	t.Helper()
+	if _, err := os.Stat(filepath.Join(saveGamePath, snapshot.DirName, "ProfileBeta")); !os.IsNotExist(err) {
+		t.Fatalf("expected snapshot history removed, got %v", err)
+	}
 
-	data, err := os.ReadFile(path)
+	if err := svc.RenameProfile("ProfileAlpha", "ProfileBeta"); err != nil {
+		t.Fatalf("rename to the deleted name: %v", err)
+	}
+
+	items, err := snapshots.List("ProfileBeta")
 	if err != nil {
-		t.Fatalf("read file %s: %v", path, err)
+		t.Fatalf("list snapshots: %v", err)
 	}
 
-	if string(data) != expected {
-		t.Fatalf("expected %q, got %q", expected, string(data))
+	if len(items) != 1 {
+		t.Fatalf("expected only the renamed profile's snapshot, got %+v", items)
 	}
-}
 
-type failingRenameMarker struct{}
+	restored := filepath.Join(root, "restored")
+	if err := snapshots.CopyTo("ProfileBeta", items[0].ID, restored); err != nil {
+		t.Fatalf("restore snapshot: %v", err)
+	}
 
-func (f failingRenameMarker) ReadActiveProfile() (string, error) {
-	return "ProfileAlpha", nil
+	assertFileContent(t, filepath.Join(restored, "savegame", "slot.sav"), "alpha-save")
 }
 
-func (f failingRenameMarker) WriteActiveProfile(profileName string) error {
-	_ = profileName
-	return errors.New("marker write failure")
-}
+func TestDestructiveOperationsRefuseWhileGameIsRunning(t *testing.T) {
+	t.Parallel()
 
-type failOnSecondReplaceLifecycleOps struct {
-	base         *fsops.Local
-	replaceCalls int
-}
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
 
-func (f *failOnSecondReplaceLifecycleOps) CopyDir(source string, destination string) error {
-	return f.base.CopyDir(source, destination)
-}
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "root-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "root-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
 
-func (f *failOnSecondReplaceLifecycleOps) ReplaceDir(source string, destination string) error {
-	f.replaceCalls++
-	if f.replaceCalls == 2 {
-		return errors.New("forced second replace failure")
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
 	}
 
-	return f.base.ReplaceDir(source, destination)
-}
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetGameGuard(gameproc.NewGuard(runningDetector{}))
 
-func (f *failOnSecondReplaceLifecycleOps) RemoveDir(path string) error {
-	return f.base.RemoveDir(path)
-}
+	if err := svc.PrepareFreshProfileWithoutSave("fresh-01"); !errors.Is(err, gameproc.ErrGameRunning) {
+		t.Fatalf("expected ErrGameRunning from prepare fresh, got %v", err)
+	}
 
-type failDeleteActiveCleanupOps struct {
-	base *fsops.Local
-}
+	if err := svc.DeleteActiveProfile("ProfileBeta"); !errors.Is(err, gameproc.ErrGameRunning) {
+		t.Fatalf("expected ErrGameRunning from delete active, got %v", err)
+	}
 
-func (f *failDeleteActiveCleanupOps) CopyDir(source string, destination string) error {
-	return f.base.CopyDir(source, destination)
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "root-save")
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "alpha-save")
+	if _, err := os.Stat(filepath.Join(profilesPath, "fresh-01")); !os.IsNotExist(err) {
+		t.Fatalf("expected fresh profile to not be created, got %v", err)
+	}
 }
 
-func (f *failDeleteActiveCleanupOps) ReplaceDir(source string, destination string) error {
-	return f.base.ReplaceDir(source, destination)
+func TestRestoreSwitchBackupRestoresRootAndMarker(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "unsaved-alpha")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	switchBackups := backups.NewService(filepath.Join(saveGamePath, backups.DirName), fsops.NewLocal(), backups.Retention{})
+	switchBackups.SetMarker(store)
+	switchService := switcher.NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	switchService.SetBackupKeeper(switchBackups)
+
+	result, err := switchService.Switch(switcher.Params{ProfileName: "ProfileBeta"})
+	if err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetSwitchBackups(switchBackups)
+
+	if err := svc.RestoreSwitchBackup(result.BackupID); err != nil {
+		t.Fatalf("restore switch backup: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "unsaved-alpha")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "alpha-wrap")
+
+	active, err := store.ReadActiveProfile()
+	if err != nil {
+		t.Fatalf("read marker: %v", err)
+	}
+
+	if active != "ProfileAlpha" {
+		t.Fatalf("expected marker to point at ProfileAlpha, got %q", active)
+	}
 }
 
-func (f *failDeleteActiveCleanupOps) RemoveDir(path string) error {
-	if strings.HasPrefix(filepath.Base(path), "ProfileAlpha.delete-") {
-		return errors.New("forced delete cleanup failure")
+func TestSwitchProfileSavesOutgoingProfileFirst(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap-progress")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
 	}
 
-	return f.base.RemoveDir(path)
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	if _, err := svc.SwitchProfile("ProfileBeta", true); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "alpha-progress")
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps", "wrap.txt"), "alpha-wrap-progress")
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "beta-save")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "beta-wrap")
+}
+
+func TestSwitchProfileWithoutSaveKeepsStoredOutgoingCopy(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap-progress")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	if _, err := svc.SwitchProfile("ProfileBeta", false); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "alpha-stale")
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "beta-save")
+}
+
+func TestSwitchProfileRollsBackOutgoingSaveWhenSwitchFails(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap-progress")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	failingOps := &failOnReplaceCallLifecycleOps{base: fsops.NewLocal(), failOn: 4}
+	svc := NewService(saveGamePath, profilesPath, store, failingOps)
+	result, err := svc.SwitchProfile("ProfileBeta", true)
+	if err == nil {
+		t.Fatal("expected switch to fail")
+	}
+
+	if !result.RolledBack {
+		t.Fatal("expected RolledBack=true")
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "alpha-stale")
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps", "wrap.txt"), "alpha-wrap-stale")
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "alpha-progress")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "alpha-wrap-progress")
+
+	active, err := store.ReadActiveProfile()
+	if err != nil {
+		t.Fatalf("read marker: %v", err)
+	}
+
+	if active != "ProfileAlpha" {
+		t.Fatalf("expected active profile ProfileAlpha, got %q", active)
+	}
+}
+
+func TestSwitchProfileContextRollsBackWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap-progress")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cancellingOps := &cancelOnReplaceCallLifecycleOps{base: fsops.NewLocal(), cancel: cancel, cancelOn: 4}
+	svc := NewService(saveGamePath, profilesPath, store, cancellingOps)
+	result, err := svc.SwitchProfileContext(ctx, "ProfileBeta", true)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if !result.RolledBack {
+		t.Fatal("expected RolledBack=true")
+	}
+
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "alpha-stale")
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "alpha-progress")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "alpha-wrap-progress")
+
+	active, err := store.ReadActiveProfile()
+	if err != nil {
+		t.Fatalf("read marker: %v", err)
+	}
+
+	if active != "ProfileAlpha" {
+		t.Fatalf("expected active profile ProfileAlpha, got %q", active)
+	}
+}
+
+func TestSwitchProfileContextReportsProgress(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap-progress")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	ctx, tracker := fsops.WithProgress(context.Background(), nil)
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	if _, err := svc.SwitchProfileContext(ctx, "ProfileBeta", true); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	progress := tracker.Progress()
+	// The outgoing save and the incoming profile are copied; the root is only
+	// moved aside.
+	if progress.FilesTotal != 4 || progress.FilesDone != progress.FilesTotal || progress.BytesDone != progress.BytesTotal {
+		t.Fatalf("expected all four copied files to be reported done, got %+v", progress)
+	}
+}
+
+func TestSwitchProgressSkipsRootWhenThereIsNothingToSave(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "unknown-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "unknown-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	ctx, tracker := fsops.WithProgress(context.Background(), nil)
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	if _, err := svc.SwitchProfileContext(ctx, "ProfileBeta", true); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	// Without a marker there is no outgoing profile to save into.
+	progress := tracker.Progress()
+	if progress.FilesTotal != 2 || progress.FilesDone != progress.FilesTotal {
+		t.Fatalf("expected only the incoming profile to be planned, got %+v", progress)
+	}
+}
+
+func TestSwitchToActiveProfileSavesItFirst(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap-progress")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-stale")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap-stale")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	if _, err := svc.SwitchProfile("ProfileAlpha", true); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "alpha-progress")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "alpha-wrap-progress")
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame", "slot.sav"), "alpha-progress")
+	assertFileContent(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps", "wrap.txt"), "alpha-wrap-progress")
+}
+
+func TestSaveAndSwitchMaintainProfileMetadata(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap-progress")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	savedAt := time.Date(2026, 5, 6, 7, 8, 9, 0, time.UTC)
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.now = func() time.Time { return savedAt }
+
+	if err := svc.SaveCurrentProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("save current profile: %v", err)
+	}
+
+	if err := svc.UpdateProfileDetails("ProfileAlpha", profiles.Details{Description: "career", Tags: []string{"story"}, Color: "#112233"}); err != nil {
+		t.Fatalf("update details: %v", err)
+	}
+
+	switchedAt := savedAt.Add(time.Hour)
+	svc.now = func() time.Time { return switchedAt }
+	if _, err := svc.SwitchProfile("ProfileBeta", true); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	alpha, err := profiles.LoadMetadata(filepath.Join(profilesPath, "ProfileAlpha"))
+	if err != nil {
+		t.Fatalf("load alpha metadata: %v", err)
+	}
+
+	if alpha.Description != "career" || len(alpha.Tags) != 1 || alpha.Color != "#112233" {
+		t.Fatalf("expected details to survive the outgoing save, got %+v", alpha)
+	}
+
+	if !alpha.CreatedAt.Equal(savedAt) || !alpha.LastSavedAt.Equal(switchedAt) {
+		t.Fatalf("expected created at first save and saved at switch, got %+v", alpha)
+	}
+
+	beta, err := profiles.LoadMetadata(filepath.Join(profilesPath, "ProfileBeta"))
+	if err != nil {
+		t.Fatalf("load beta metadata: %v", err)
+	}
+
+	if !beta.LastSwitchedAt.Equal(switchedAt) {
+		t.Fatalf("expected beta switched at %v, got %+v", switchedAt, beta)
+	}
+
+	if _, err := os.Stat(filepath.Join(saveGamePath, "savegame", profiles.MetadataFileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected metadata to stay out of the root save, got %v", err)
+	}
+}
+
+func TestUpdateProfileDetailsRejectsUnknownProfileAndInvalidColour(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha")
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	if err := svc.UpdateProfileDetails("Missing", profiles.Details{}); !errors.Is(err, ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound, got %v", err)
+	}
+
+	if err := svc.UpdateProfileDetails("ProfileAlpha", profiles.Details{Color: "red"}); !errors.Is(err, profiles.ErrInvalidColor) {
+		t.Fatalf("expected ErrInvalidColor, got %v", err)
+	}
+}
+
+func TestPrepareFreshProfileRejectsInvalidName(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService("save", "profiles", marker.NewStore(t.TempDir()), fsops.NewLocal())
+	err := svc.PrepareFreshProfile("BAD/NAME")
+	if !errors.Is(err, ErrProfileNameInvalid) {
+		t.Fatalf("expected ErrProfileNameInvalid, got %v", err)
+	}
+}
+
+func TestProfileNamesShapedLikeTemporaryFoldersAreRefused(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "wrap")
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	for _, name := range []string{"Career.save-2", "Run.backup-2024-01-01", "Test.sync-1"} {
+		if err := svc.PrepareFreshProfile(name); !errors.Is(err, profiles.ErrReservedProfileName) {
+			t.Fatalf("expected %s to be refused for a new profile, got %v", name, err)
+		}
+
+		if err := svc.RenameProfile("ProfileAlpha", name); !errors.Is(err, profiles.ErrReservedProfileName) {
+			t.Fatalf("expected %s to be refused as a rename target, got %v", name, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(profilesPath, "ProfileAlpha")); err != nil {
+		t.Fatalf("expected the profile left in place, got %v", err)
+	}
+}
+
+func TestPrepareFreshProfileWithoutPreserveClearsRootAndCreatesEmptyProfile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "old-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "old-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+
+	if err := svc.PrepareFreshProfileWithoutSave("fresh-empty"); err != nil {
+		t.Fatalf("prepare fresh profile without preserve: %v", err)
+	}
+
+	assertDirEmpty(t, filepath.Join(saveGamePath, "savegame"))
+	assertDirEmpty(t, filepath.Join(saveGamePath, "wraps"))
+	assertDirEmpty(t, filepath.Join(profilesPath, "fresh-empty", "savegame"))
+	assertDirEmpty(t, filepath.Join(profilesPath, "fresh-empty", "wraps"))
+
+	active, err := store.ReadActiveProfile()
+	if err != nil {
+		t.Fatalf("read marker: %v", err)
+	}
+
+	if active != "fresh-empty" {
+		t.Fatalf("expected active profile fresh-empty, got %q", active)
+	}
+}
+
+func createDirWithFile(t *testing.T, dir string, fileName string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", dir, err)
+	}
+
+	path := filepath.Join(dir, fileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file %s: %v", path, err)
+	}
+}
+
+func assertFileContent(t *testing.T, path string, expected string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file %s: %v", path, err)
+	}
+
+	if string(data) != expected {
+		t.Fatalf("expected %q, got %q", expected, string(data))
+	}
+}
+
+type failingRenameMarker struct{}
+
+func (f failingRenameMarker) ReadActiveProfile() (string, error) {
+	return "ProfileAlpha", nil
+}
+
+func (f failingRenameMarker) WriteActiveProfile(profileName string) error {
+	_ = profileName
+	return errors.New("marker write failure")
+}
+
+type failOnSecondReplaceLifecycleOps struct {
+	base         *fsops.Local
+	replaceCalls int
+}
+
+func (f *failOnSecondReplaceLifecycleOps) CopyDir(ctx context.Context, source string, destination string) error {
+	return f.base.CopyDir(ctx, source, destination)
+}
+
+func (f *failOnSecondReplaceLifecycleOps) ReplaceDir(ctx context.Context, source string, destination string) error {
+	f.replaceCalls++
+	if f.replaceCalls == 2 {
+		return errors.New("forced second replace failure")
+	}
+
+	return f.base.ReplaceDir(ctx, source, destination)
+}
+
+func (f *failOnSecondReplaceLifecycleOps) MoveDir(ctx context.Context, source string, destination string) error {
+	return f.base.MoveDir(ctx, source, destination)
+}
+
+func (f *failOnSecondReplaceLifecycleOps) SyncDir(ctx context.Context, source string, destination string, reference string) error {
+	return f.ReplaceDir(ctx, source, destination)
+}
+
+func (f *failOnSecondReplaceLifecycleOps) RemoveDir(path string) error {
+	return f.base.RemoveDir(path)
+}
+
+type failDeleteActiveCleanupOps struct {
+	base *fsops.Local
+}
+
+func (f *failDeleteActiveCleanupOps) CopyDir(ctx context.Context, source string, destination string) error {
+	return f.base.CopyDir(ctx, source, destination)
+}
+
+func (f *failDeleteActiveCleanupOps) ReplaceDir(ctx context.Context, source string, destination string) error {
+	return f.base.ReplaceDir(ctx, source, destination)
+}
+
+func (f *failDeleteActiveCleanupOps) MoveDir(ctx context.Context, source string, destination string) error {
+	return f.base.MoveDir(ctx, source, destination)
+}
+
+func (f *failDeleteActiveCleanupOps) SyncDir(ctx context.Context, source string, destination string, reference string) error {
+	return f.ReplaceDir(ctx, source, destination)
+}
+
+func (f *failDeleteActiveCleanupOps) RemoveDir(path string) error {
+	if strings.HasPrefix(filepath.Base(path), "ProfileAlpha.delete-") {
+		return errors.New("forced delete cleanup failure")
+	}
+
+	return f.base.RemoveDir(path)
+}
+
+type runningDetector struct{}
+
+func (runningDetector) FindRunning(processNames []string) ([]gameproc.Process, error) {
+	_ = processNames
+	return []gameproc.Process{{PID: 4242, Name: "NeedForSpeedHeat.exe"}}, nil
+}
+
+type failOnReplaceCallLifecycleOps struct {
+	base         *fsops.Local
+	failOn       int
+	replaceCalls int
+}
+
+func (f *failOnReplaceCallLifecycleOps) CopyDir(ctx context.Context, source string, destination string) error {
+	return f.base.CopyDir(ctx, source, destination)
+}
+
+func (f *failOnReplaceCallLifecycleOps) ReplaceDir(ctx context.Context, source string, destination string) error {
+	f.replaceCalls++
+	if f.replaceCalls == f.failOn {
+		return errors.New("forced replace failure")
+	}
+
+	return f.base.ReplaceDir(ctx, source, destination)
+}
+
+func (f *failOnReplaceCallLifecycleOps) MoveDir(ctx context.Context, source string, destination string) error {
+	return f.base.MoveDir(ctx, source, destination)
+}
+
+func (f *failOnReplaceCallLifecycleOps) SyncDir(ctx context.Context, source string, destination string, reference string) error {
+	return f.ReplaceDir(ctx, source, destination)
+}
+
+func (f *failOnReplaceCallLifecycleOps) RemoveDir(path string) error {
+	return f.base.RemoveDir(path)
+}
+
+type cancelOnReplaceCallLifecycleOps struct {
+	base         *fsops.Local
+	cancel       context.CancelFunc
+	cancelOn     int
+	replaceCalls int
+}
+
+func (f *cancelOnReplaceCallLifecycleOps) CopyDir(ctx context.Context, source string, destination string) error {
+	return f.base.CopyDir(ctx, source, destination)
+}
+
+func (f *cancelOnReplaceCallLifecycleOps) ReplaceDir(ctx context.Context, source string, destination string) error {
+	f.replaceCalls++
+	if f.replaceCalls == f.cancelOn {
+		f.cancel()
+	}
+
+	return f.base.ReplaceDir(ctx, source, destination)
+}
+
+func (f *cancelOnReplaceCallLifecycleOps) MoveDir(ctx context.Context, source string, destination string) error {
+	return f.base.MoveDir(ctx, source, destination)
+}
+
+func (f *cancelOnReplaceCallLifecycleOps) SyncDir(ctx context.Context, source string, destination string, reference string) error {
+	return f.ReplaceDir(ctx, source, destination)
+}
+
+func (f *cancelOnReplaceCallLifecycleOps) RemoveDir(path string) error {
+	return f.base.RemoveDir(path)
+}
+
+func TestSwitchProfileRefusesWhileFolderIsLocked(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	release, err := savelock.New(saveGamePath).Acquire("import")
+	if err != nil {
+		t.Fatalf("acquire lock: %v", err)
+	}
+	defer release()
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	svc.SetLocker(savelock.New(saveGamePath))
+
+	if _, err := svc.SwitchProfile("ProfileBeta", false); !errors.Is(err, savelock.ErrBusy) {
+		t.Fatalf("expected savelock.ErrBusy, got %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "alpha-progress")
+}
+
+func TestDeleteActiveProfileSharesLockWithItsSwitch(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-progress")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "savegame"), "slot.sav", "alpha-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileAlpha", "wraps"), "wrap.txt", "alpha-wrap")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "savegame"), "slot.sav", "beta-save")
+	createDirWithFile(t, filepath.Join(profilesPath, "ProfileBeta", "wraps"), "wrap.txt", "beta-wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	lock := savelock.New(saveGamePath)
+	svc := NewService(saveGamePath, profilesPath, store, fsops.NewLocal())
+	svc.SetLocker(lock)
+
+	if err := svc.DeleteActiveProfile("ProfileBeta"); err != nil {
+		t.Fatalf("delete active profile: %v", err)
+	}
+
+	if _, err := os.Stat(lock.Path()); !os.IsNotExist(err) {
+		t.Fatalf("expected lock released, got %v", err)
+	}
 }
@@ -0,0 +1,257 @@
+package oprunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxQueued bounds how many operations may wait behind the running
+// one; anything beyond that is a runaway UI, not a user.
+const DefaultMaxQueued = 8
+
+var (
+	ErrAlreadyQueued = errors.New("operation is already in progress")
+	ErrQueueFull     = errors.New("too many operations are waiting")
+)
+
+// Operation is one mutating request as shown to the UI.
+type Operation struct {
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	Target    string    `json:"target,omitempty"`
+	QueuedAt  time.Time `json:"queuedAt"`
+	StartedAt time.Time `json:"startedAt,omitempty"`
+	// Cancelling is set once a running operation has been asked to stop
+	// and is rolling back.
+	Cancelling bool `json:"cancelling,omitempty"`
+}
+
+// Status is the running operation, if any, and those waiting behind it in
+// the order they will run.
+type Status struct {
+	Current *Operation  `json:"current"`
+	Queued  []Operation `json:"queued"`
+}
+
+// DuplicateError rejects a request identical to one already running or
+// queued, such as a double-clicked switch. It matches ErrAlreadyQueued with
+// errors.Is.
+type DuplicateError struct {
+	Existing Operation
+}
+
+func (e *DuplicateError) Error() string {
+	if e.Existing.Target == "" {
+		return fmt.Sprintf("%s: %s", ErrAlreadyQueued.Error(), e.Existing.Kind)
+	}
+
+	return fmt.Sprintf("%s: %s %s", ErrAlreadyQueued.Error(), e.Existing.Kind, e.Existing.Target)
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrAlreadyQueued
+}
+
+// Runner runs mutating operations one at a time in arrival order. A nil
+// Runner runs every operation immediately.
+type Runner struct {
+	maxQueued int
+	now       func() time.Time
+	onChange  func(Status)
+
+	mu      sync.Mutex
+	nextID  int64
+	current *ticket
+	queue   []*ticket
+}
+
+type ticket struct {
+	operation Operation
+	ready     chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+func New() *Runner {
+	return &Runner{maxQueued: DefaultMaxQueued, now: time.Now}
+}
+
+// OnChange registers a callback invoked with the new status whenever an
+// operation is queued, starts or finishes. It runs outside the runner lock.
+func (r *Runner) OnChange(callback func(Status)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.onChange = callback
+}
+
+// Run waits for every earlier operation to finish, then runs fn. A request
+// with the same kind and target as one already running or queued is rejected
+// instead of queued. The context passed to fn is cancelled by Cancel; a
+// request cancelled while still queued returns context.Canceled without
+// running.
+func (r *Runner) Run(kind string, target string, fn func(ctx context.Context) error) error {
+	if r == nil {
+		return fn(context.Background())
+	}
+
+	t, err := r.enqueue(kind, target)
+	if err != nil {
+		return err
+	}
+	defer t.cancel()
+
+	select {
+	case <-t.ready:
+	case <-t.ctx.Done():
+		if !r.started(t) {
+			return t.ctx.Err()
+		}
+	}
+	defer r.finish(t)
+
+	return fn(t.ctx)
+}
+
+// Cancel stops the operation with the given ID. A queued operation is dropped
+// from the queue; a running one has its context cancelled and is left to roll
+// back. It reports whether the operation was found.
+func (r *Runner) Cancel(id int64) bool {
+	if r == nil {
+		return false
+	}
+
+	r.mu.Lock()
+
+	if r.current != nil && r.current.operation.ID == id {
+		r.current.operation.Cancelling = true
+		r.current.cancel()
+		r.notifyAndUnlock()
+		return true
+	}
+
+	for i, t := range r.queue {
+		if t.operation.ID == id {
+			r.queue = append(r.queue[:i:i], r.queue[i+1:]...)
+			t.cancel()
+			r.notifyAndUnlock()
+			return true
+		}
+	}
+
+	r.mu.Unlock()
+	return false
+}
+
+func (r *Runner) Status() Status {
+	if r == nil {
+		return Status{Queued: []Operation{}}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.statusLocked()
+}
+
+func (r *Runner) enqueue(kind string, target string) (*ticket, error) {
+	r.mu.Lock()
+
+	target = strings.TrimSpace(target)
+	for _, existing := range r.pendingLocked() {
+		if existing.operation.Kind == kind && strings.EqualFold(existing.operation.Target, target) {
+			r.mu.Unlock()
+			return nil, &DuplicateError{Existing: existing.operation}
+		}
+	}
+
+	if r.current != nil && len(r.queue) >= r.maxQueued {
+		r.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+
+	r.nextID++
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &ticket{
+		operation: Operation{ID: r.nextID, Kind: kind, Target: target, QueuedAt: r.now().UTC()},
+		ready:     make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	if r.current == nil {
+		r.startLocked(t)
+	} else {
+		r.queue = append(r.queue, t)
+	}
+
+	r.notifyAndUnlock()
+	return t, nil
+}
+
+func (r *Runner) finish(t *ticket) {
+	r.mu.Lock()
+
+	if r.current == t {
+		r.current = nil
+		if len(r.queue) > 0 {
+			next := r.queue[0]
+			r.queue = r.queue[1:]
+			r.startLocked(next)
+		}
+	}
+
+	r.notifyAndUnlock()
+}
+
+func (r *Runner) started(t *ticket) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current == t
+}
+
+func (r *Runner) startLocked(t *ticket) {
+	t.operation.StartedAt = r.now().UTC()
+	r.current = t
+	close(t.ready)
+}
+
+func (r *Runner) pendingLocked() []*ticket {
+	pending := make([]*ticket, 0, len(r.queue)+1)
+	if r.current != nil {
+		pending = append(pending, r.current)
+	}
+
+	return append(pending, r.queue...)
+}
+
+func (r *Runner) statusLocked() Status {
+	status := Status{Queued: make([]Operation, 0, len(r.queue))}
+	if r.current != nil {
+		current := r.current.operation
+		status.Current = &current
+	}
+
+	for _, t := range r.queue {
+		status.Queued = append(status.Queued, t.operation)
+	}
+
+	return status
+}
+
+// notifyAndUnlock releases the lock before calling back so a callback may
+// read Status without deadlocking.
+func (r *Runner) notifyAndUnlock() {
+	callback := r.onChange
+	status := r.statusLocked()
+	r.mu.Unlock()
+
+	if callback != nil {
+		callback(status)
+	}
+}
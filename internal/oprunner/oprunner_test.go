@@ -0,0 +1,227 @@
+package oprunner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunSerializesOperationsInArrivalOrder(t *testing.T) {
+	t.Parallel()
+
+	runner := New()
+	firstStarted := make(chan struct{})
+	releaseFirst := make(chan struct{})
+
+	var mu sync.Mutex
+	order := []string{}
+	record := func(name string) {
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = runner.Run("switch", "Alpha", func(context.Context) error {
+			close(firstStarted)
+			<-releaseFirst
+			record("switch")
+			return nil
+		})
+	}()
+	<-firstStarted
+
+	for _, name := range []string{"save", "import"} {
+		name := name
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = runner.Run(name, "Beta", func(context.Context) error {
+				record(name)
+				return nil
+			})
+		}()
+		waitFor(t, func() bool {
+			queued := runner.Status().Queued
+			return len(queued) > 0 && queued[len(queued)-1].Kind == name
+		})
+	}
+
+	status := runner.Status()
+	if status.Current == nil || status.Current.Kind != "switch" || len(status.Queued) != 2 {
+		t.Fatalf("unexpected status while first operation runs: %+v", status)
+	}
+
+	close(releaseFirst)
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != "switch" || order[1] != "save" || order[2] != "import" {
+		t.Fatalf("expected switch, save, import; got %v", order)
+	}
+
+	status = runner.Status()
+	if status.Current != nil || len(status.Queued) != 0 {
+		t.Fatalf("expected idle runner, got %+v", status)
+	}
+}
+
+func TestRunRejectsDuplicateRequest(t *testing.T) {
+	t.Parallel()
+
+	runner := New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		done <- runner.Run("switch", "Alpha", func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	err := runner.Run("switch", "alpha", func(context.Context) error {
+		t.Fatal("duplicate operation must not run")
+		return nil
+	})
+	if !errors.Is(err, ErrAlreadyQueued) {
+		t.Fatalf("expected ErrAlreadyQueued, got %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+}
+
+func TestRunRejectsWhenQueueIsFull(t *testing.T) {
+	t.Parallel()
+
+	runner := New()
+	runner.maxQueued = 0
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		done <- runner.Run("switch", "Alpha", func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	if err := runner.Run("save", "Beta", func(context.Context) error { return nil }); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestRunReturnsOperationErrorAndReportsChanges(t *testing.T) {
+	t.Parallel()
+
+	runner := New()
+	var mu sync.Mutex
+	changes := []Status{}
+	runner.OnChange(func(status Status) {
+		mu.Lock()
+		changes = append(changes, status)
+		mu.Unlock()
+	})
+
+	failure := errors.New("boom")
+	if err := runner.Run("delete", "Alpha", func(context.Context) error { return failure }); !errors.Is(err, failure) {
+		t.Fatalf("expected operation error, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 2 || changes[0].Current == nil || changes[0].Current.Kind != "delete" || changes[1].Current != nil {
+		t.Fatalf("expected start and finish notifications, got %+v", changes)
+	}
+}
+
+func TestCancelDropsQueuedOperationAndStopsRunningOne(t *testing.T) {
+	t.Parallel()
+
+	runner := New()
+	started := make(chan struct{})
+	firstDone := make(chan error)
+	go func() {
+		firstDone <- runner.Run("switch", "Alpha", func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	<-started
+
+	queuedDone := make(chan error)
+	go func() {
+		queuedDone <- runner.Run("save", "Beta", func(context.Context) error {
+			t.Error("cancelled operation must not run")
+			return nil
+		})
+	}()
+	waitFor(t, func() bool { return len(runner.Status().Queued) == 1 })
+
+	queued := runner.Status().Queued[0]
+	if !runner.Cancel(queued.ID) {
+		t.Fatal("expected queued operation to be found")
+	}
+	if err := <-queuedDone; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected queued run to be cancelled, got %v", err)
+	}
+
+	current := runner.Status().Current
+	if !runner.Cancel(current.ID) {
+		t.Fatal("expected running operation to be found")
+	}
+	if err := <-firstDone; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected running operation to see cancellation, got %v", err)
+	}
+
+	if runner.Cancel(current.ID) {
+		t.Fatal("expected finished operation to be unknown")
+	}
+
+	status := runner.Status()
+	if status.Current != nil || len(status.Queued) != 0 {
+		t.Fatalf("expected idle runner, got %+v", status)
+	}
+}
+
+func TestNilRunnerRunsImmediately(t *testing.T) {
+	t.Parallel()
+
+	var runner *Runner
+	ran := false
+	if err := runner.Run("switch", "Alpha", func(context.Context) error {
+		ran = true
+		return nil
+	}); err != nil || !ran {
+		t.Fatalf("expected nil runner to run the operation, ran=%v err=%v", ran, err)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
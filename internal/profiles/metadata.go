@@ -0,0 +1,160 @@
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MetadataFileName sits in the profile folder next to savegame and wraps, so
+// switches never copy it into the game's save.
+const MetadataFileName = "profile.json"
+
+const (
+	maxDescriptionLength = 500
+	maxTags              = 20
+	maxTagLength         = 40
+)
+
+var (
+	ErrInvalidColor       = errors.New("profile colour must be a #rrggbb hex value")
+	ErrDescriptionTooLong = errors.New("profile description is too long")
+	ErrTooManyTags        = errors.New("profile has too many tags")
+	ErrTagTooLong         = errors.New("profile tag is too long")
+
+	colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// Metadata is what the app knows about a profile beyond its saves. The
+// timestamps are maintained by lifecycle operations; zero means never.
+type Metadata struct {
+	Description    string    `json:"description,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
+	Color          string    `json:"color,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	LastSwitchedAt time.Time `json:"lastSwitchedAt"`
+	LastSavedAt    time.Time `json:"lastSavedAt"`
+}
+
+// Details are the user-editable parts of Metadata.
+type Details struct {
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Color       string   `json:"color"`
+}
+
+// NormalizeDetails trims the details, drops empty and repeated tags (ignoring
+// case) and checks the limits.
+func NormalizeDetails(details Details) (Details, error) {
+	normalized := Details{
+		Description: strings.TrimSpace(details.Description),
+		Tags:        []string{},
+		Color:       strings.ToLower(strings.TrimSpace(details.Color)),
+	}
+
+	if len([]rune(normalized.Description)) > maxDescriptionLength {
+		return Details{}, ErrDescriptionTooLong
+	}
+
+	if normalized.Color != "" && !colorPattern.MatchString(normalized.Color) {
+		return Details{}, ErrInvalidColor
+	}
+
+	seen := map[string]bool{}
+	for _, tag := range details.Tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+
+		if len([]rune(tag)) > maxTagLength {
+			return Details{}, ErrTagTooLong
+		}
+
+		seen[key] = true
+		normalized.Tags = append(normalized.Tags, tag)
+	}
+
+	if len(normalized.Tags) > maxTags {
+		return Details{}, ErrTooManyTags
+	}
+
+	return normalized, nil
+}
+
+// LoadMetadata reads the metadata of the profile at profilePath. A profile
+// without a metadata file has zero metadata.
+func LoadMetadata(profilePath string) (Metadata, error) {
+	content, err := os.ReadFile(filepath.Join(profilePath, MetadataFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Metadata{}, nil
+		}
+		return Metadata{}, err
+	}
+
+	var metadata Metadata
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return Metadata{}, err
+	}
+
+	return metadata, nil
+}
+
+// SaveMetadata replaces the metadata file through a rename so a crash never
+// leaves it half-written.
+func SaveMetadata(profilePath string, metadata Metadata) error {
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(profilePath, MetadataFileName+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(profilePath, MetadataFileName))
+}
+
+// UpdateMetadata loads, changes and saves the metadata of the profile at
+// profilePath. Unreadable metadata is started over rather than blocking the
+// operation that wants to update it.
+func UpdateMetadata(profilePath string, update func(*Metadata)) error {
+	metadata, err := LoadMetadata(profilePath)
+	if err != nil {
+		metadata = Metadata{}
+	}
+
+	update(&metadata)
+	return SaveMetadata(profilePath, metadata)
+}
+
+// CopyMetadata carries the metadata file from one profile folder to another,
+// for operations that rebuild a profile folder from its saves alone.
+func CopyMetadata(fromPath string, toPath string) error {
+	content, err := os.ReadFile(filepath.Join(fromPath, MetadataFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(toPath, MetadataFileName), content, 0o644)
+}
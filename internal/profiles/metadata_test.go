@@ -0,0 +1,117 @@
+package profiles
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeDetailsTrimsAndDeduplicatesTags(t *testing.T) {
+	t.Parallel()
+
+	details, err := NormalizeDetails(Details{
+		Description: "  main career  ",
+		Tags:        []string{" Story ", "story", "", "Drift"},
+		Color:       " #FFAA00 ",
+	})
+	if err != nil {
+		t.Fatalf("normalize details: %v", err)
+	}
+
+	if details.Description != "main career" || details.Color != "#ffaa00" {
+		t.Fatalf("unexpected details: %+v", details)
+	}
+
+	if len(details.Tags) != 2 || details.Tags[0] != "Story" || details.Tags[1] != "Drift" {
+		t.Fatalf("expected tags [Story Drift], got %v", details.Tags)
+	}
+}
+
+func TestNormalizeDetailsRejectsInvalidValues(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		details  Details
+		expected error
+	}{
+		{name: "colour", details: Details{Color: "orange"}, expected: ErrInvalidColor},
+		{name: "description", details: Details{Description: strings.Repeat("a", maxDescriptionLength+1)}, expected: ErrDescriptionTooLong},
+		{name: "tag length", details: Details{Tags: []string{strings.Repeat("t", maxTagLength+1)}}, expected: ErrTagTooLong},
+	}
+
+	for _, tc := range cases {
+		if _, err := NormalizeDetails(tc.details); !errors.Is(err, tc.expected) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, err)
+		}
+	}
+}
+
+func TestMetadataRoundTripAndCopy(t *testing.T) {
+	t.Parallel()
+
+	base := t.TempDir()
+	source := filepath.Join(base, "source")
+	target := filepath.Join(base, "target")
+	createDir(t, source)
+	createDir(t, target)
+
+	missing, err := LoadMetadata(source)
+	if err != nil || !missing.CreatedAt.IsZero() || missing.Description != "" {
+		t.Fatalf("expected zero metadata for missing file, got %+v err=%v", missing, err)
+	}
+
+	created := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	err = UpdateMetadata(source, func(metadata *Metadata) {
+		metadata.Description = "career"
+		metadata.CreatedAt = created
+	})
+	if err != nil {
+		t.Fatalf("update metadata: %v", err)
+	}
+
+	if err := CopyMetadata(source, target); err != nil {
+		t.Fatalf("copy metadata: %v", err)
+	}
+
+	copied, err := LoadMetadata(target)
+	if err != nil {
+		t.Fatalf("load copied metadata: %v", err)
+	}
+
+	if copied.Description != "career" || !copied.CreatedAt.Equal(created) {
+		t.Fatalf("unexpected copied metadata: %+v", copied)
+	}
+
+	if err := CopyMetadata(filepath.Join(base, "none"), target); err != nil {
+		t.Fatalf("copy from profile without metadata: %v", err)
+	}
+}
+
+func TestListIncludesMetadataAndKeepsProfilesWithDamagedMetadata(t *testing.T) {
+	t.Parallel()
+
+	profilesPath := t.TempDir()
+	createProfileLayout(t, profilesPath, "Alpha", true)
+	createProfileLayout(t, profilesPath, "Beta", true)
+
+	if err := SaveMetadata(filepath.Join(profilesPath, "Alpha"), Metadata{Tags: []string{"story"}}); err != nil {
+		t.Fatalf("save metadata: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(profilesPath, "Beta", MetadataFileName), []byte("{"), 0o644); err != nil {
+		t.Fatalf("write damaged metadata: %v", err)
+	}
+
+	items, err := NewService(profilesPath).List()
+	if err != nil {
+		t.Fatalf("list profiles: %v", err)
+	}
+
+	if len(items) != 2 || len(items[0].Metadata.Tags) != 1 || items[0].Metadata.Tags[0] != "story" {
+		t.Fatalf("unexpected profiles: %+v", items)
+	}
+}
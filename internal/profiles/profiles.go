@@ -4,17 +4,52 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strings"
 )
 
 var (
 	ErrProfilesPathRequired = errors.New("profiles path is required")
 	ErrInvalidProfileLayout = errors.New("profile must contain savegame and wraps folders")
+	ErrReservedProfileName  = errors.New("profile name ends like a temporary folder the app creates")
 )
 
+// Statuses reported by Scan for each entry of the profiles folder.
+const (
+	StatusValid           = "valid"
+	StatusMissingSavegame = "missing-savegame"
+	StatusMissingWraps    = "missing-wraps"
+	StatusNotDirectory    = "not-directory"
+	// StatusArtifact: a staging, parking or temporary entry left behind by
+	// an interrupted operation.
+	StatusArtifact = "artifact"
+)
+
+// artifactPattern matches the temporary names the app creates next to
+// profiles and inside them: os.MkdirTemp/CreateTemp suffixes and the
+// timestamped staging and parking folders of fsops.
+var artifactPattern = regexp.MustCompile(`^.+\.(save|import|restore|delete|replace|sync|move|backup|export|tmp)-[0-9][0-9.\-]*(\.zip)?$`)
+
+// ignoredEntries are created by the operating system, not by users.
+var ignoredEntries = map[string]bool{
+	"desktop.ini": true,
+	"thumbs.db":   true,
+}
+
+// Candidate is one entry of the profiles folder and whether it is usable as
+// a profile.
+type Candidate struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
 type Profile struct {
-	Name string
-	Path string
+	Name     string
+	Path     string
+	Metadata Metadata
 }
 
 type Service struct {
@@ -46,13 +81,17 @@ func (s *Service) List() ([]Profile, error) {
 		}
 
 		profilePath := filepath.Join(s.profilesPath, entry.Name())
-		if err := ValidateLayout(profilePath); err != nil {
+		if Inspect(profilePath).Status != StatusValid {
 			continue
 		}
 
+		// Damaged metadata should not hide the profile; it is rewritten by
+		// the next operation that updates it.
+		metadata, _ := LoadMetadata(profilePath)
 		items = append(items, Profile{
-			Name: entry.Name(),
-			Path: profilePath,
+			Name:     entry.Name(),
+			Path:     profilePath,
+			Metadata: metadata,
 		})
 	}
 
@@ -63,6 +102,89 @@ func (s *Service) List() ([]Profile, error) {
 	return items, nil
 }
 
+// Scan reports every entry of the profiles folder, sorted by name, so that
+// folders List leaves out can be explained and repaired. Hidden entries and
+// operating-system files are skipped.
+func (s *Service) Scan() ([]Candidate, error) {
+	if s.profilesPath == "" {
+		return nil, ErrProfilesPathRequired
+	}
+
+	entries, err := os.ReadDir(s.profilesPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Candidate{}, nil
+		}
+		return nil, err
+	}
+
+	candidates := make([]Candidate, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || ignoredEntries[strings.ToLower(name)] {
+			continue
+		}
+
+		candidates = append(candidates, Inspect(filepath.Join(s.profilesPath, name)))
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	return candidates, nil
+}
+
+// IsArtifactName reports whether name has the shape of a temporary entry the
+// app creates. Such names are refused for profiles, so a real profile is
+// never mistaken for one and hidden.
+func IsArtifactName(name string) bool {
+	return artifactPattern.MatchString(strings.TrimSpace(name))
+}
+
+// Inspect classifies a single entry of the profiles folder.
+func Inspect(path string) Candidate {
+	name := filepath.Base(path)
+	candidate := Candidate{Name: name, Path: path}
+
+	if IsArtifactName(name) {
+		candidate.Status = StatusArtifact
+		candidate.Reason = "left behind by an interrupted operation"
+		return candidate
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		candidate.Status = StatusNotDirectory
+		candidate.Reason = err.Error()
+		return candidate
+	}
+
+	if !info.IsDir() {
+		candidate.Status = StatusNotDirectory
+		candidate.Reason = "is a file, not a profile folder"
+		return candidate
+	}
+
+	hasSavegame := hasRequiredDir(path, "savegame")
+	hasWraps := hasRequiredDir(path, "wraps")
+	switch {
+	case hasSavegame && hasWraps:
+		candidate.Status = StatusValid
+	case !hasSavegame && !hasWraps:
+		candidate.Status = StatusMissingSavegame
+		candidate.Reason = "savegame and wraps folders are missing"
+	case !hasSavegame:
+		candidate.Status = StatusMissingSavegame
+		candidate.Reason = "savegame folder is missing"
+	default:
+		candidate.Status = StatusMissingWraps
+		candidate.Reason = "wraps folder is missing"
+	}
+
+	return candidate
+}
+
 func ValidateLayout(profilePath string) error {
 	if hasRequiredDir(profilePath, "savegame") && hasRequiredDir(profilePath, "wraps") {
 		return nil
@@ -93,3 +93,81 @@ func createDir(t *testing.T, path string) {
 		t.Fatalf("mkdir %s: %v", path, err)
 	}
 }
+
+func TestScanReportsStatusOfEveryEntry(t *testing.T) {
+	t.Parallel()
+
+	profilesPath := t.TempDir()
+	createProfileLayout(t, profilesPath, "Alpha", true)
+	createProfileLayout(t, profilesPath, "NoWraps", false)
+	createDir(t, filepath.Join(profilesPath, "NoSavegame", "wraps"))
+	createProfileLayout(t, profilesPath, "Alpha.import-20260102-030405", true)
+	createDir(t, filepath.Join(profilesPath, ".hidden", "savegame"))
+
+	if err := os.WriteFile(filepath.Join(profilesPath, "notes.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesPath, "desktop.ini"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write desktop.ini: %v", err)
+	}
+
+	candidates, err := NewService(profilesPath).Scan()
+	if err != nil {
+		t.Fatalf("scan profiles: %v", err)
+	}
+
+	expected := map[string]string{
+		"Alpha":                        StatusValid,
+		"Alpha.import-20260102-030405": StatusArtifact,
+		"NoSavegame":                   StatusMissingSavegame,
+		"NoWraps":                      StatusMissingWraps,
+		"notes.txt":                    StatusNotDirectory,
+	}
+
+	if len(candidates) != len(expected) {
+		t.Fatalf("expected %d candidates, got %+v", len(expected), candidates)
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Status != expected[candidate.Name] {
+			t.Fatalf("expected %s to be %q, got %+v", candidate.Name, expected[candidate.Name], candidate)
+		}
+		if candidate.Status != StatusValid && candidate.Reason == "" {
+			t.Fatalf("expected a reason for %+v", candidate)
+		}
+	}
+
+	items, err := NewService(profilesPath).List()
+	if err != nil {
+		t.Fatalf("list profiles: %v", err)
+	}
+
+	if len(items) != 1 || items[0].Name != "Alpha" {
+		t.Fatalf("expected only Alpha to be listed, got %+v", items)
+	}
+}
+
+func TestIsArtifactNameMatchesGeneratedNamesOnly(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"Alpha.import-123456":                    true,
+		"Alpha.delete-98765":                     true,
+		"Alpha.replace-20260102-030405123456789": true,
+		"Alpha.sync-20260102-030405123456789":    true,
+		"Alpha.move-20260102-030405123456789":    true,
+		"Alpha.backup-20260102-030405123456789":  true,
+		"Alpha.export-4242.zip":                  true,
+		"profile.json.tmp-31337":                 true,
+		"Alpha":                                  false,
+		"Career 2":                               false,
+		"Alpha.import":                           false,
+		"Alpha.sync-backup":                      false,
+	}
+
+	for name, want := range cases {
+		if got := IsArtifactName(name); got != want {
+			t.Fatalf("IsArtifactName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package rootsync
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/profiles"
+)
+
+const (
+	maxReportedMatches = 5
+	// minSuggestScore is how much of the root save a profile must share before
+	// it is offered as the active profile when the marker is unusable.
+	minSuggestScore = 0.5
+)
+
+// Match scores how closely a stored profile resembles the root save: the
+// share of files, across both, that are present in both with equal content.
+type Match struct {
+	Profile string  `json:"profile"`
+	Score   float64 `json:"score"`
+	Exact   bool    `json:"exact"`
+}
+
+// Identification tells which profile the root save belongs to. Suggested is
+// set when the marker should be rewritten: it is missing, names a profile
+// that does not exist, or names a profile that differs from the root while
+// another one matches it exactly.
+type Identification struct {
+	ActiveProfile string    `json:"activeProfile,omitempty"`
+	MarkerMissing bool      `json:"markerMissing"`
+	ActiveExists  bool      `json:"activeExists"`
+	Matches       []Match   `json:"matches"`
+	Suggested     string    `json:"suggested,omitempty"`
+	CheckedAt     time.Time `json:"checkedAt"`
+}
+
+// Identify fingerprints the root save and every valid profile and ranks the
+// profiles by how much of their content they share with the root.
+func (s *Service) Identify() (Identification, error) {
+	if strings.TrimSpace(s.saveGamePath) == "" {
+		return Identification{}, ErrSaveGamePathRequired
+	}
+
+	result := Identification{Matches: []Match{}, CheckedAt: s.now().UTC()}
+
+	active, err := s.readActive()
+	if err != nil {
+		return Identification{}, err
+	}
+	result.ActiveProfile = active
+	result.MarkerMissing = active == ""
+
+	idx := s.loadIndex()
+	defer s.saveIndex(idx)
+
+	root, err := idx.scan(s.saveGamePath, s.saveGamePath)
+	if err != nil {
+		return Identification{}, err
+	}
+
+	candidates, err := profiles.NewService(s.profilesPath).Scan()
+	if err != nil {
+		return Identification{}, err
+	}
+
+	matches := []Match{}
+	activeExact := false
+	for _, candidate := range candidates {
+		if candidate.Status != profiles.StatusValid {
+			continue
+		}
+
+		stored, err := idx.scan(s.saveGamePath, candidate.Path)
+		if err != nil {
+			return Identification{}, err
+		}
+
+		match, err := score(candidate.Name, root, stored)
+		if err != nil {
+			return Identification{}, err
+		}
+
+		if active != "" && strings.EqualFold(candidate.Name, active) {
+			result.ActiveExists = true
+			activeExact = match.Exact
+		}
+
+		if match.Score > 0 {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Profile < matches[j].Profile
+	})
+
+	if len(matches) > 0 {
+		best := matches[0]
+		markerUnusable := !result.ActiveExists
+		switch {
+		case strings.EqualFold(best.Profile, active):
+		case markerUnusable && best.Score >= minSuggestScore:
+			result.Suggested = best.Profile
+		case !markerUnusable && best.Exact && !activeExact:
+			result.Suggested = best.Profile
+		}
+	}
+
+	result.Matches = matches[:min(len(matches), maxReportedMatches)]
+	return result, nil
+}
+
+func score(profileName string, root *tree, stored *tree) (Match, error) {
+	union := len(root.sizes)
+	shared := 0
+	for rel, size := range stored.sizes {
+		rootSize, ok := root.sizes[rel]
+		if !ok {
+			union++
+			continue
+		}
+
+		if rootSize != size {
+			continue
+		}
+
+		left, err := root.sum(rel)
+		if err != nil {
+			return Match{}, err
+		}
+
+		right, err := stored.sum(rel)
+		if err != nil {
+			return Match{}, err
+		}
+
+		if left == right {
+			shared++
+		}
+	}
+
+	if union == 0 {
+		return Match{Profile: profileName, Score: 1, Exact: true}, nil
+	}
+
+	return Match{
+		Profile: profileName,
+		Score:   float64(shared) / float64(union),
+		Exact:   shared == union,
+	}, nil
+}
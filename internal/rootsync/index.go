@@ -0,0 +1,112 @@
+package rootsync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// IndexFileName caches the fingerprint of every file compared so far, inside
+// the SaveGame folder. A file whose size and modification time are unchanged
+// is not hashed again.
+const IndexFileName = ".fingerprints.json"
+
+const indexVersion = 1
+
+type fileRecord struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	SHA256  string    `json:"sha256"`
+}
+
+// index maps a tree, by its path relative to the SaveGame folder, to the
+// records of its files.
+type index struct {
+	Version int                              `json:"version"`
+	Trees   map[string]map[string]fileRecord `json:"trees"`
+
+	dirty bool
+}
+
+func (s *Service) indexPath() string {
+	return filepath.Join(s.saveGamePath, IndexFileName)
+}
+
+// loadIndex starts over with an empty index when the file is missing,
+// unreadable or from another version; it is only a cache.
+func (s *Service) loadIndex() *index {
+	idx := &index{Version: indexVersion, Trees: map[string]map[string]fileRecord{}}
+
+	content, err := os.ReadFile(s.indexPath())
+	if err != nil {
+		return idx
+	}
+
+	var loaded index
+	if err := json.Unmarshal(content, &loaded); err != nil || loaded.Version != indexVersion || loaded.Trees == nil {
+		return idx
+	}
+
+	return &loaded
+}
+
+// saveIndex writes the index back when a scan changed it, dropping trees that
+// no longer exist. Failures are ignored: the next check simply hashes again.
+func (s *Service) saveIndex(idx *index) {
+	for key := range idx.Trees {
+		if _, err := os.Stat(filepath.Join(s.saveGamePath, filepath.FromSlash(key))); err != nil {
+			delete(idx.Trees, key)
+			idx.dirty = true
+		}
+	}
+
+	if !idx.dirty {
+		return
+	}
+
+	content, err := json.Marshal(idx)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(s.saveGamePath, IndexFileName+".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return
+	}
+
+	if err := tmp.Close(); err != nil {
+		return
+	}
+
+	_ = os.Rename(tmp.Name(), s.indexPath())
+}
+
+// scan reads the tree at root with the cached records for it. The tree shares
+// its records with the index, so hashes computed later land in the index.
+func (idx *index) scan(saveGamePath string, root string) (*tree, error) {
+	key, err := filepath.Rel(saveGamePath, root)
+	if err != nil {
+		key = root
+	}
+	key = filepath.ToSlash(key)
+
+	t, err := scanTree(root, idx.Trees[key])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(t.records) != len(idx.Trees[key]) {
+		idx.dirty = true
+	}
+
+	idx.Trees[key] = t.records
+	t.index = idx
+	return t, nil
+}
@@ -0,0 +1,327 @@
+package rootsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/profiles"
+)
+
+// States reported by Check.
+const (
+	// StateInSync: the root save is identical to the active profile's stored
+	// copy, so nothing is lost by replacing it.
+	StateInSync = "in-sync"
+	// StateUnsavedChanges: the root save differs from the active profile's
+	// stored copy, usually because the game was played since the last save.
+	StateUnsavedChanges = "unsaved-changes"
+	// StateMatchesOtherProfile: the root save is identical to a profile other
+	// than the one named by the marker, so the marker is probably wrong.
+	StateMatchesOtherProfile = "matches-other-profile"
+	// StateUntracked: there is no active profile with a stored copy to compare
+	// against and no profile matches the root save.
+	StateUntracked = "untracked"
+)
+
+const maxReportedChanges = 20
+
+var ErrSaveGamePathRequired = errors.New("savegame path is required")
+
+var compared = []string{"savegame", "wraps"}
+
+type MarkerReader interface {
+	ReadActiveProfile() (string, error)
+}
+
+// Status compares the root save with the stored profiles. Changes lists up to
+// a handful of differing paths relative to the SaveGame folder; ChangeCount is
+// the full number.
+type Status struct {
+	State          string    `json:"state"`
+	ActiveProfile  string    `json:"activeProfile,omitempty"`
+	MatchedProfile string    `json:"matchedProfile,omitempty"`
+	Changes        []string  `json:"changes,omitempty"`
+	ChangeCount    int       `json:"changeCount"`
+	CheckedAt      time.Time `json:"checkedAt"`
+}
+
+type Service struct {
+	saveGamePath string
+	profilesPath string
+	marker       MarkerReader
+	now          func() time.Time
+}
+
+func NewService(saveGamePath string, profilesPath string, marker MarkerReader) *Service {
+	return &Service{
+		saveGamePath: saveGamePath,
+		profilesPath: profilesPath,
+		marker:       marker,
+		now:          time.Now,
+	}
+}
+
+// Check compares the root savegame and wraps folders with the active
+// profile's stored copy and, when they differ, with every other profile.
+// Files are compared by size first and by SHA-256 only when sizes agree;
+// the game rewrites files without always changing their size.
+func (s *Service) Check() (Status, error) {
+	if strings.TrimSpace(s.saveGamePath) == "" {
+		return Status{}, ErrSaveGamePathRequired
+	}
+
+	status := Status{CheckedAt: s.now().UTC()}
+
+	active, err := s.readActive()
+	if err != nil {
+		return Status{}, err
+	}
+	status.ActiveProfile = active
+
+	idx := s.loadIndex()
+	defer s.saveIndex(idx)
+
+	root, err := idx.scan(s.saveGamePath, s.saveGamePath)
+	if err != nil {
+		return Status{}, err
+	}
+
+	var changes []string
+	hasStoredCopy := false
+	if active != "" {
+		activePath := filepath.Join(s.profilesPath, active)
+		if profiles.Inspect(activePath).Status == profiles.StatusValid {
+			hasStoredCopy = true
+			stored, err := idx.scan(s.saveGamePath, activePath)
+			if err != nil {
+				return Status{}, err
+			}
+
+			changes, err = diff(root, stored)
+			if err != nil {
+				return Status{}, err
+			}
+
+			if len(changes) == 0 {
+				status.State = StateInSync
+				return status, nil
+			}
+		}
+	}
+
+	matched, err := s.findMatch(idx, root, active)
+	if err != nil {
+		return Status{}, err
+	}
+
+	switch {
+	case matched != "":
+		status.State = StateMatchesOtherProfile
+		status.MatchedProfile = matched
+	case hasStoredCopy:
+		status.State = StateUnsavedChanges
+		status.ChangeCount = len(changes)
+		status.Changes = changes[:min(len(changes), maxReportedChanges)]
+	default:
+		status.State = StateUntracked
+	}
+
+	return status, nil
+}
+
+func (s *Service) readActive() (string, error) {
+	if s.marker == nil {
+		return "", nil
+	}
+
+	active, err := s.marker.ReadActiveProfile()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	active = strings.TrimSpace(active)
+	if active == "." || active == ".." || strings.ContainsAny(active, `/\`) {
+		return "", nil
+	}
+
+	return active, nil
+}
+
+// findMatch returns the first valid profile, other than skip, whose stored
+// copy is identical to root.
+func (s *Service) findMatch(idx *index, root *tree, skip string) (string, error) {
+	if strings.TrimSpace(s.profilesPath) == "" {
+		return "", nil
+	}
+
+	candidates, err := profiles.NewService(s.profilesPath).Scan()
+	if err != nil {
+		return "", err
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Status != profiles.StatusValid || strings.EqualFold(candidate.Name, skip) {
+			continue
+		}
+
+		stored, err := idx.scan(s.saveGamePath, candidate.Path)
+		if err != nil {
+			return "", err
+		}
+
+		if !root.sameSizes(stored) {
+			continue
+		}
+
+		changes, err := diff(root, stored)
+		if err != nil {
+			return "", err
+		}
+
+		if len(changes) == 0 {
+			return candidate.Name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// tree is the savegame and wraps content below root, keyed by slash-separated
+// relative path. Hashes are computed on demand and remembered in records,
+// which may start out filled from the fingerprint index.
+type tree struct {
+	root     string
+	sizes    map[string]int64
+	modTimes map[string]time.Time
+	records  map[string]fileRecord
+	index    *index
+}
+
+func scanTree(root string, known map[string]fileRecord) (*tree, error) {
+	t := &tree{root: root, sizes: map[string]int64{}, modTimes: map[string]time.Time{}, records: map[string]fileRecord{}}
+
+	for _, dirName := range compared {
+		base := filepath.Join(root, dirName)
+		err := filepath.WalkDir(base, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if path == base && errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+
+			rel = filepath.ToSlash(rel)
+			t.sizes[rel] = info.Size()
+			t.modTimes[rel] = info.ModTime().UTC()
+			if record, ok := known[rel]; ok && record.Size == info.Size() && record.ModTime.Equal(t.modTimes[rel]) {
+				t.records[rel] = record
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (t *tree) sameSizes(other *tree) bool {
+	if len(t.sizes) != len(other.sizes) {
+		return false
+	}
+
+	for rel, size := range t.sizes {
+		if otherSize, ok := other.sizes[rel]; !ok || otherSize != size {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *tree) sum(rel string) (string, error) {
+	if record, ok := t.records[rel]; ok {
+		return record.SHA256, nil
+	}
+
+	file, err := os.Open(filepath.Join(t.root, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	t.records[rel] = fileRecord{Size: t.sizes[rel], ModTime: t.modTimes[rel], SHA256: sum}
+	if t.index != nil {
+		t.index.dirty = true
+	}
+	return sum, nil
+}
+
+// diff returns the sorted paths that exist on one side only or whose content
+// differs.
+func diff(a *tree, b *tree) ([]string, error) {
+	changes := []string{}
+
+	for rel, size := range a.sizes {
+		otherSize, ok := b.sizes[rel]
+		if !ok || otherSize != size {
+			changes = append(changes, rel)
+			continue
+		}
+
+		left, err := a.sum(rel)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := b.sum(rel)
+		if err != nil {
+			return nil, err
+		}
+
+		if left != right {
+			changes = append(changes, rel)
+		}
+	}
+
+	for rel := range b.sizes {
+		if _, ok := a.sizes[rel]; !ok {
+			changes = append(changes, rel)
+		}
+	}
+
+	sort.Strings(changes)
+	return changes, nil
+}
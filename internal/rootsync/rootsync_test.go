@@ -0,0 +1,198 @@
+package rootsync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"heat-save-manager/internal/marker"
+)
+
+func TestCheckReportsInSyncAndUnsavedChanges(t *testing.T) {
+	t.Parallel()
+
+	saveGamePath, profilesPath := newLayout(t)
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "alpha")
+	writeFile(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "wrap")
+	writeFile(t, filepath.Join(profilesPath, "Alpha", "savegame", "slot.sav"), "alpha")
+	writeFile(t, filepath.Join(profilesPath, "Alpha", "wraps", "wrap.txt"), "wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("Alpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	svc := NewService(saveGamePath, profilesPath, store)
+	status, err := svc.Check()
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if status.State != StateInSync || status.ActiveProfile != "Alpha" {
+		t.Fatalf("expected in sync with Alpha, got %+v", status)
+	}
+
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "alpz")
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "new.sav"), "new")
+	if err := os.Remove(filepath.Join(saveGamePath, "wraps", "wrap.txt")); err != nil {
+		t.Fatalf("remove wrap: %v", err)
+	}
+
+	status, err = svc.Check()
+	if err != nil {
+		t.Fatalf("check after changes: %v", err)
+	}
+
+	expected := []string{"savegame/new.sav", "savegame/slot.sav", "wraps/wrap.txt"}
+	if status.State != StateUnsavedChanges || status.ChangeCount != len(expected) {
+		t.Fatalf("expected unsaved changes, got %+v", status)
+	}
+
+	for i, change := range expected {
+		if status.Changes[i] != change {
+			t.Fatalf("expected changes %v, got %v", expected, status.Changes)
+		}
+	}
+}
+
+func TestCheckFindsProfileMatchingRootWhenMarkerIsWrong(t *testing.T) {
+	t.Parallel()
+
+	saveGamePath, profilesPath := newLayout(t)
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "beta")
+	writeFile(t, filepath.Join(profilesPath, "Alpha", "savegame", "slot.sav"), "alfa")
+	writeFile(t, filepath.Join(profilesPath, "Beta", "savegame", "slot.sav"), "beta")
+	for _, name := range []string{"Alpha", "Beta"} {
+		if err := os.MkdirAll(filepath.Join(profilesPath, name, "wraps"), 0o755); err != nil {
+			t.Fatalf("mkdir wraps: %v", err)
+		}
+	}
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("Alpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	status, err := NewService(saveGamePath, profilesPath, store).Check()
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if status.State != StateMatchesOtherProfile || status.MatchedProfile != "Beta" {
+		t.Fatalf("expected root to match Beta, got %+v", status)
+	}
+}
+
+func TestCheckWithoutMarkerIsUntracked(t *testing.T) {
+	t.Parallel()
+
+	saveGamePath, profilesPath := newLayout(t)
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "progress")
+
+	status, err := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath)).Check()
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if status.State != StateUntracked || status.ActiveProfile != "" {
+		t.Fatalf("expected untracked root, got %+v", status)
+	}
+}
+
+func newLayout(t *testing.T) (string, string) {
+	t.Helper()
+
+	saveGamePath := filepath.Join(t.TempDir(), "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	if err := os.MkdirAll(profilesPath, 0o755); err != nil {
+		t.Fatalf("mkdir profiles: %v", err)
+	}
+
+	return saveGamePath, profilesPath
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", filepath.Dir(path), err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestIdentifySuggestsClosestProfileWhenMarkerIsMissing(t *testing.T) {
+	t.Parallel()
+
+	saveGamePath, profilesPath := newLayout(t)
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "a.sav"), "alpha-a")
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "b.sav"), "alpha-b-played")
+	writeFile(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "alpha-wrap")
+	writeFile(t, filepath.Join(profilesPath, "Alpha", "savegame", "a.sav"), "alpha-a")
+	writeFile(t, filepath.Join(profilesPath, "Alpha", "savegame", "b.sav"), "alpha-b")
+	writeFile(t, filepath.Join(profilesPath, "Alpha", "wraps", "wrap.txt"), "alpha-wrap")
+	writeFile(t, filepath.Join(profilesPath, "Beta", "savegame", "a.sav"), "beta-a")
+	writeFile(t, filepath.Join(profilesPath, "Beta", "wraps", "wrap.txt"), "beta-wrap")
+
+	svc := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath))
+	identification, err := svc.Identify()
+	if err != nil {
+		t.Fatalf("identify: %v", err)
+	}
+
+	if !identification.MarkerMissing || identification.Suggested != "Alpha" {
+		t.Fatalf("expected Alpha to be suggested, got %+v", identification)
+	}
+
+	if len(identification.Matches) != 1 || identification.Matches[0].Exact || identification.Matches[0].Score < minSuggestScore {
+		t.Fatalf("expected one close, inexact match, got %+v", identification.Matches)
+	}
+
+	if _, err := os.Stat(filepath.Join(saveGamePath, IndexFileName)); err != nil {
+		t.Fatalf("expected fingerprint index to be written: %v", err)
+	}
+
+	again, err := svc.Identify()
+	if err != nil || again.Suggested != "Alpha" || again.Matches[0].Score != identification.Matches[0].Score {
+		t.Fatalf("expected the same result from the index, got %+v err=%v", again, err)
+	}
+}
+
+func TestIdentifyCatchesMarkerNamingTheWrongProfile(t *testing.T) {
+	t.Parallel()
+
+	saveGamePath, profilesPath := newLayout(t)
+	for _, name := range []string{"Alpha", "Beta"} {
+		writeFile(t, filepath.Join(profilesPath, name, "savegame", "slot.sav"), name)
+		writeFile(t, filepath.Join(profilesPath, name, "wraps", "wrap.txt"), "wrap")
+	}
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "Beta")
+	writeFile(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "wrap")
+
+	store := marker.NewStore(saveGamePath)
+	if err := store.WriteActiveProfile("Alpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	svc := NewService(saveGamePath, profilesPath, store)
+	identification, err := svc.Identify()
+	if err != nil {
+		t.Fatalf("identify: %v", err)
+	}
+
+	if !identification.ActiveExists || identification.Suggested != "Beta" || !identification.Matches[0].Exact {
+		t.Fatalf("expected Beta to replace the wrong marker, got %+v", identification)
+	}
+
+	writeFile(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "Alfa")
+	identification, err = svc.Identify()
+	if err != nil {
+		t.Fatalf("identify after playing: %v", err)
+	}
+
+	if identification.Suggested != "" {
+		t.Fatalf("expected no suggestion when no profile matches exactly, got %+v", identification)
+	}
+}
@@ -0,0 +1,333 @@
+package savelock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the advisory lock file inside the SaveGame root.
+const FileName = ".operation.lock"
+
+// DefaultMaxAge is how long a lock held from another machine sharing the
+// folder may go without being refreshed before it is treated as abandoned.
+// Its owner's process cannot be checked from here, so the owner refreshes the
+// lock every DefaultMaxAge/3 while it works. Locks held on this machine are
+// judged by whether their process is still alive, however long they are held.
+const DefaultMaxAge = 30 * time.Minute
+
+const partialWriteGrace = 5 * time.Second
+
+var (
+	ErrBusy                 = errors.New("savegame folder is busy")
+	ErrSaveGamePathRequired = errors.New("savegame path is required")
+)
+
+// Holder is the owner recorded in the lock file.
+type Holder struct {
+	PID        int       `json:"pid"`
+	Hostname   string    `json:"hostname"`
+	Operation  string    `json:"operation"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	// RefreshedAt is the last time the owner confirmed it still holds the
+	// lock; zero until the first refresh.
+	RefreshedAt time.Time `json:"refreshedAt,omitempty"`
+}
+
+// BusyError is returned when another process holds the lock. It matches
+// ErrBusy with errors.Is.
+type BusyError struct {
+	Holder Holder
+}
+
+func (e *BusyError) Error() string {
+	operation := e.Holder.Operation
+	if operation == "" {
+		operation = "another operation"
+	}
+
+	return fmt.Sprintf("%s: %s in progress (pid %d since %s); wait for it to finish", ErrBusy.Error(), operation, e.Holder.PID, e.Holder.AcquiredAt.Local().Format("15:04:05"))
+}
+
+func (e *BusyError) Unwrap() error {
+	return ErrBusy
+}
+
+// Locker guards one SaveGame folder across processes. Acquire is reentrant
+// on the same Locker so a service can hand it to the services it calls. A nil
+// Locker never locks.
+type Locker struct {
+	saveGamePath string
+	path         string
+	maxAge       time.Duration
+	refreshEvery time.Duration
+	now          func() time.Time
+	alive        func(pid int) bool
+
+	mu          sync.Mutex
+	depth       int
+	holder      Holder
+	stopRefresh chan struct{}
+}
+
+func New(saveGamePath string) *Locker {
+	return &Locker{
+		saveGamePath: saveGamePath,
+		path:         filepath.Join(saveGamePath, FileName),
+		maxAge:       DefaultMaxAge,
+		refreshEvery: DefaultMaxAge / 3,
+		now:          time.Now,
+		alive:        processAlive,
+	}
+}
+
+func (l *Locker) Path() string {
+	return l.path
+}
+
+// Acquire takes the lock for operation and returns the function that gives
+// it back. A stale lock left by a dead or long-gone owner is replaced.
+func (l *Locker) Acquire(operation string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	if strings.TrimSpace(l.saveGamePath) == "" {
+		return nil, ErrSaveGamePathRequired
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.depth > 0 {
+		l.depth++
+		return l.releaseFunc(), nil
+	}
+
+	holder := Holder{
+		PID:        os.Getpid(),
+		Hostname:   hostname(),
+		Operation:  operation,
+		AcquiredAt: l.now().UTC(),
+	}
+
+	if err := l.create(holder); err != nil {
+		return nil, err
+	}
+
+	l.depth = 1
+	l.holder = holder
+	l.stopRefresh = make(chan struct{})
+	go l.refresh(holder, l.stopRefresh)
+	return l.releaseFunc(), nil
+}
+
+// Current reads the lock file without taking it. ok is false when the folder
+// is free or the recorded owner is stale.
+func (l *Locker) Current() (Holder, bool, error) {
+	if l == nil {
+		return Holder{}, false, nil
+	}
+
+	holder, err := readHolder(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Holder{}, false, nil
+		}
+		return Holder{}, false, err
+	}
+
+	if l.stale(holder) {
+		return Holder{}, false, nil
+	}
+
+	return holder, true, nil
+}
+
+func (l *Locker) create(holder Holder) error {
+	content, err := json.Marshal(holder)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+
+	// One retry after clearing a stale lock; losing that race to another
+	// process reports busy like any other held lock.
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, writeErr := file.Write(content)
+			closeErr := file.Close()
+			if writeErr != nil || closeErr != nil {
+				_ = os.Remove(l.path)
+				return errors.Join(writeErr, closeErr)
+			}
+			return nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+
+		existing, readErr := readHolder(l.path)
+		if readErr == nil && !l.stale(existing) {
+			return &BusyError{Holder: existing}
+		}
+
+		// A lock file that cannot be parsed is either still being written by
+		// its owner or was cut short by a crash; only the latter is stale.
+		if readErr != nil && !errors.Is(readErr, os.ErrNotExist) && l.recentlyWritten() {
+			return &BusyError{}
+		}
+
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	existing, _ := readHolder(l.path)
+	return &BusyError{Holder: existing}
+}
+
+func (l *Locker) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(l.release)
+	}
+}
+
+func (l *Locker) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.depth == 0 {
+		return
+	}
+
+	l.depth--
+	if l.depth > 0 {
+		return
+	}
+
+	// Only remove the file if it is still ours; a peer may have broken an
+	// overdue lock and taken it over.
+	if current, err := readHolder(l.path); err == nil && current.same(l.holder) {
+		_ = os.Remove(l.path)
+	}
+
+	close(l.stopRefresh)
+	l.stopRefresh = nil
+	l.holder = Holder{}
+}
+
+// refresh rewrites the lock file with a new RefreshedAt while holder owns
+// it, so peers on other machines do not take it for abandoned.
+func (l *Locker) refresh(holder Holder, stop <-chan struct{}) {
+	if l.refreshEvery <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(l.refreshEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		l.mu.Lock()
+		if current, err := readHolder(l.path); err == nil && l.depth > 0 && current.same(holder) {
+			current.RefreshedAt = l.now().UTC()
+			_ = storeHolder(l.path, current)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (l *Locker) stale(holder Holder) bool {
+	if holder.PID <= 0 || holder.AcquiredAt.IsZero() {
+		return true
+	}
+
+	// On this machine the owner's process tells for certain; a slow switch
+	// or import of a large save may hold the lock for any length of time.
+	if holder.Hostname == "" || holder.Hostname == hostname() {
+		return !l.alive(holder.PID)
+	}
+
+	lastSeen := holder.AcquiredAt
+	if holder.RefreshedAt.After(lastSeen) {
+		lastSeen = holder.RefreshedAt
+	}
+
+	return l.maxAge > 0 && l.now().Sub(lastSeen) > l.maxAge
+}
+
+func (l *Locker) recentlyWritten() bool {
+	info, err := os.Stat(l.path)
+	return err == nil && l.now().Sub(info.ModTime()) < partialWriteGrace
+}
+
+func (h Holder) same(other Holder) bool {
+	return h.PID == other.PID && h.Hostname == other.Hostname && h.Operation == other.Operation && h.AcquiredAt.Equal(other.AcquiredAt)
+}
+
+// storeHolder replaces the lock file in one rename, so readers never see it
+// half written.
+func storeHolder(path string, holder Holder) error {
+	content, err := json.Marshal(holder)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+func readHolder(path string) (Holder, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Holder{}, err
+	}
+
+	var holder Holder
+	if err := json.Unmarshal(content, &holder); err != nil {
+		return Holder{}, fmt.Errorf("read lock file: %w", err)
+	}
+
+	return holder, nil
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+
+	return name
+}
+
+// ProcessAlive reports whether pid is a running process on this machine, the
+// same check used to judge local lock holders.
+func ProcessAlive(pid int) bool {
+	return processAlive(pid)
+}
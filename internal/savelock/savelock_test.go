@@ -0,0 +1,194 @@
+package savelock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAcquireBlocksOtherLockerUntilReleased(t *testing.T) {
+	t.Parallel()
+
+	saveGamePath := t.TempDir()
+	first := New(saveGamePath)
+	second := New(saveGamePath)
+
+	release, err := first.Acquire("switch")
+	if err != nil {
+		t.Fatalf("acquire first: %v", err)
+	}
+
+	_, err = second.Acquire("save")
+	if !errors.Is(err, ErrBusy) {
+		t.Fatalf("expected ErrBusy, got %v", err)
+	}
+
+	var busy *BusyError
+	if !errors.As(err, &busy) || busy.Holder.Operation != "switch" || busy.Holder.PID != os.Getpid() {
+		t.Fatalf("expected busy error naming the switch holder, got %#v", err)
+	}
+
+	release()
+
+	releaseSecond, err := second.Acquire("save")
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	releaseSecond()
+
+	if _, err := os.Stat(first.Path()); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file removed, got %v", err)
+	}
+}
+
+func TestAcquireIsReentrantOnSameLocker(t *testing.T) {
+	t.Parallel()
+
+	lock := New(t.TempDir())
+
+	releaseOuter, err := lock.Acquire("delete")
+	if err != nil {
+		t.Fatalf("acquire outer: %v", err)
+	}
+
+	releaseInner, err := lock.Acquire("switch")
+	if err != nil {
+		t.Fatalf("acquire inner: %v", err)
+	}
+
+	releaseInner()
+	if _, err := os.Stat(lock.Path()); err != nil {
+		t.Fatalf("expected lock still held after inner release, got %v", err)
+	}
+
+	releaseOuter()
+	if _, err := os.Stat(lock.Path()); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file removed after outer release, got %v", err)
+	}
+}
+
+func TestAcquireReplacesLockOfDeadProcess(t *testing.T) {
+	t.Parallel()
+
+	lock := New(t.TempDir())
+	lock.alive = func(pid int) bool { return pid == os.Getpid() }
+	writeHolder(t, lock.Path(), Holder{PID: 999999, Hostname: hostname(), Operation: "switch", AcquiredAt: time.Now().UTC()})
+
+	release, err := lock.Acquire("save")
+	if err != nil {
+		t.Fatalf("expected stale lock to be replaced, got %v", err)
+	}
+	defer release()
+
+	holder, ok, err := lock.Current()
+	if err != nil || !ok || holder.Operation != "save" {
+		t.Fatalf("expected save to hold the lock, got %+v ok=%v err=%v", holder, ok, err)
+	}
+}
+
+func TestAcquireReplacesOverdueLock(t *testing.T) {
+	t.Parallel()
+
+	lock := New(t.TempDir())
+	lock.alive = func(pid int) bool { return true }
+	writeHolder(t, lock.Path(), Holder{PID: 4242, Hostname: "other-machine", Operation: "import", AcquiredAt: time.Now().Add(-2 * DefaultMaxAge).UTC()})
+
+	release, err := lock.Acquire("switch")
+	if err != nil {
+		t.Fatalf("expected overdue lock to be replaced, got %v", err)
+	}
+	release()
+}
+
+func TestAcquireKeepsLockOfLiveProcess(t *testing.T) {
+	t.Parallel()
+
+	lock := New(t.TempDir())
+	lock.alive = func(pid int) bool { return true }
+	writeHolder(t, lock.Path(), Holder{PID: 4242, Hostname: hostname(), Operation: "import", AcquiredAt: time.Now().UTC()})
+
+	if _, err := lock.Acquire("switch"); !errors.Is(err, ErrBusy) {
+		t.Fatalf("expected ErrBusy, got %v", err)
+	}
+}
+
+func TestAcquireKeepsLockOfLiveProcessHeldLongerThanMaxAge(t *testing.T) {
+	t.Parallel()
+
+	lock := New(t.TempDir())
+	lock.alive = func(pid int) bool { return true }
+	writeHolder(t, lock.Path(), Holder{PID: 4242, Hostname: hostname(), Operation: "import", AcquiredAt: time.Now().Add(-3 * DefaultMaxAge).UTC()})
+
+	if _, err := lock.Acquire("switch"); !errors.Is(err, ErrBusy) {
+		t.Fatalf("expected a slow but live holder to keep the lock, got %v", err)
+	}
+}
+
+func TestHeldLockIsRefreshedForOtherMachines(t *testing.T) {
+	t.Parallel()
+
+	saveGamePath := t.TempDir()
+	lock := New(saveGamePath)
+	lock.refreshEvery = 5 * time.Millisecond
+
+	release, err := lock.Acquire("switch")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer release()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		holder, err := readHolder(lock.Path())
+		if err == nil && holder.RefreshedAt.After(holder.AcquiredAt) {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the lock to be refreshed, got %+v err=%v", holder, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A peer on another machine judges the lock by its last refresh, not by
+	// when it was first taken.
+	peer := New(saveGamePath)
+	peer.maxAge = time.Minute
+	peer.now = func() time.Time { return time.Now().Add(30 * time.Second) }
+	holder, err := readHolder(lock.Path())
+	if err != nil {
+		t.Fatalf("read lock: %v", err)
+	}
+
+	holder.Hostname = "other-machine"
+	holder.AcquiredAt = holder.AcquiredAt.Add(-time.Hour)
+	if peer.stale(holder) {
+		t.Fatalf("expected a recently refreshed lock to be kept, got %+v", holder)
+	}
+}
+
+func TestNilLockerNeverLocks(t *testing.T) {
+	t.Parallel()
+
+	var lock *Locker
+	release, err := lock.Acquire("switch")
+	if err != nil {
+		t.Fatalf("acquire on nil locker: %v", err)
+	}
+	release()
+}
+
+func writeHolder(t *testing.T, path string, holder Holder) {
+	t.Helper()
+
+	content, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatalf("marshal holder: %v", err)
+	}
+
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("write lock file: %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/config"
+)
+
+var (
+	ErrNoSigningKey        = errors.New("no signing key has been generated")
+	ErrSigningKeyExists    = errors.New("a signing key already exists")
+	ErrAuthorRequired      = errors.New("author name is required")
+	ErrPublicKeyInvalid    = errors.New("public key is invalid")
+	ErrTrustedKeyNameEmpty = errors.New("trusted key name is required")
+	ErrKeyDirRequired      = errors.New("signing key directory is required")
+)
+
+const KeyFileName = "signing_key.json"
+
+type Identity struct {
+	Author     string
+	PublicKey  ed25519.PublicKey
+	PrivateKey ed25519.PrivateKey
+	CreatedAt  time.Time
+}
+
+// Fingerprint is the short, human-comparable form of the identity's key.
+func (i Identity) Fingerprint() string {
+	return Fingerprint(i.PublicKey)
+}
+
+type keyFile struct {
+	Author    string    `json:"author"`
+	PublicKey string    `json:"publicKey"`
+	Seed      string    `json:"seed"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// KeyStore keeps the local signing keypair in the app's config directory. The
+// file is written owner-only because it holds the private seed.
+type KeyStore struct {
+	dir string
+	now func() time.Time
+}
+
+func NewKeyStore(dir string) *KeyStore {
+	return &KeyStore{dir: dir, now: time.Now}
+}
+
+func (s *KeyStore) Path() string {
+	return filepath.Join(s.dir, KeyFileName)
+}
+
+func (s *KeyStore) Load() (Identity, error) {
+	if strings.TrimSpace(s.dir) == "" {
+		return Identity{}, ErrKeyDirRequired
+	}
+
+	content, err := os.ReadFile(s.Path())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Identity{}, ErrNoSigningKey
+		}
+		return Identity{}, err
+	}
+
+	var stored keyFile
+	if err := json.Unmarshal(content, &stored); err != nil {
+		return Identity{}, err
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(stored.Seed)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return Identity{}, errors.New("signing key file is damaged")
+	}
+
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	return Identity{
+		Author:     stored.Author,
+		PublicKey:  privateKey.Public().(ed25519.PublicKey),
+		PrivateKey: privateKey,
+		CreatedAt:  stored.CreatedAt,
+	}, nil
+}
+
+// Generate creates a new keypair for author. An existing key is never
+// replaced; delete it first to rotate.
+func (s *KeyStore) Generate(author string) (Identity, error) {
+	trimmed := strings.TrimSpace(author)
+	if trimmed == "" {
+		return Identity{}, ErrAuthorRequired
+	}
+
+	if strings.TrimSpace(s.dir) == "" {
+		return Identity{}, ErrKeyDirRequired
+	}
+
+	if _, err := os.Stat(s.Path()); err == nil {
+		return Identity{}, ErrSigningKeyExists
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return Identity{}, err
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	identity := Identity{
+		Author:     trimmed,
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		CreatedAt:  s.now().UTC(),
+	}
+
+	content, err := json.MarshalIndent(keyFile{
+		Author:    identity.Author,
+		PublicKey: EncodePublicKey(publicKey),
+		Seed:      base64.StdEncoding.EncodeToString(privateKey.Seed()),
+		CreatedAt: identity.CreatedAt,
+	}, "", "  ")
+	if err != nil {
+		return Identity{}, err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return Identity{}, err
+	}
+
+	if err := os.WriteFile(s.Path(), content, 0o600); err != nil {
+		return Identity{}, err
+	}
+
+	return identity, nil
+}
+
+func (s *KeyStore) Delete() error {
+	if err := os.Remove(s.Path()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func EncodePublicKey(publicKey ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(publicKey)
+}
+
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(decoded) != ed25519.PublicKeySize {
+		return nil, ErrPublicKeyInvalid
+	}
+
+	return ed25519.PublicKey(decoded), nil
+}
+
+func Fingerprint(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:8])
+}
+
+// AddTrustedKey returns keys with publicKey trusted under name. Re-adding a
+// known key renames it instead of duplicating it.
+func AddTrustedKey(keys []config.TrustedKey, name string, publicKey string, now time.Time) ([]config.TrustedKey, error) {
+	trimmedName := strings.TrimSpace(name)
+	if trimmedName == "" {
+		return nil, ErrTrustedKeyNameEmpty
+	}
+
+	parsed, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := EncodePublicKey(parsed)
+	updated := make([]config.TrustedKey, 0, len(keys)+1)
+	found := false
+	for _, key := range keys {
+		if key.PublicKey == encoded {
+			key.Name = trimmedName
+			found = true
+		}
+		updated = append(updated, key)
+	}
+
+	if !found {
+		updated = append(updated, config.TrustedKey{Name: trimmedName, PublicKey: encoded, AddedAt: now.UTC()})
+	}
+
+	return updated, nil
+}
+
+// RemoveTrustedKey drops the key matching either its encoded public key or
+// its fingerprint and reports whether anything was removed.
+func RemoveTrustedKey(keys []config.TrustedKey, keyOrFingerprint string) ([]config.TrustedKey, bool) {
+	target := strings.TrimSpace(keyOrFingerprint)
+	updated := make([]config.TrustedKey, 0, len(keys))
+	removed := false
+	for _, key := range keys {
+		if key.PublicKey == target || trustedKeyFingerprint(key) == strings.ToLower(target) {
+			removed = true
+			continue
+		}
+		updated = append(updated, key)
+	}
+
+	return updated, removed
+}
+
+// FindTrustedKey returns the trusted entry for publicKey, if any.
+func FindTrustedKey(keys []config.TrustedKey, publicKey ed25519.PublicKey) (config.TrustedKey, bool) {
+	encoded := EncodePublicKey(publicKey)
+	for _, key := range keys {
+		if key.PublicKey == encoded {
+			return key, true
+		}
+	}
+
+	return config.TrustedKey{}, false
+}
+
+func trustedKeyFingerprint(key config.TrustedKey) string {
+	parsed, err := ParsePublicKey(key.PublicKey)
+	if err != nil {
+		return ""
+	}
+
+	return Fingerprint(parsed)
+}
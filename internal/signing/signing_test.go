@@ -0,0 +1,91 @@
+package signing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGenerateAndLoadIdentity(t *testing.T) {
+	t.Parallel()
+
+	store := NewKeyStore(filepath.Join(t.TempDir(), "config-root"))
+	if _, err := store.Load(); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("expected ErrNoSigningKey, got %v", err)
+	}
+
+	generated, err := store.Generate("  Sam  ")
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	if generated.Author != "Sam" || len(generated.Fingerprint()) != 16 {
+		t.Fatalf("unexpected identity: %+v", generated)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+
+	if !loaded.PublicKey.Equal(generated.PublicKey) || !loaded.PrivateKey.Equal(generated.PrivateKey) {
+		t.Fatal("expected loaded key to match generated key")
+	}
+
+	if _, err := store.Generate("Other"); !errors.Is(err, ErrSigningKeyExists) {
+		t.Fatalf("expected ErrSigningKeyExists, got %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(store.Path())
+		if err != nil {
+			t.Fatalf("stat key file: %v", err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Fatalf("expected owner-only key file, got %v", info.Mode().Perm())
+		}
+	}
+}
+
+func TestTrustedKeyListManagement(t *testing.T) {
+	t.Parallel()
+
+	store := NewKeyStore(t.TempDir())
+	identity, err := store.Generate("Sam")
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	encoded := EncodePublicKey(identity.PublicKey)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	keys, err := AddTrustedKey(nil, "Sam", encoded, now)
+	if err != nil {
+		t.Fatalf("add trusted key: %v", err)
+	}
+
+	keys, err = AddTrustedKey(keys, "Sam (laptop)", encoded, now)
+	if err != nil {
+		t.Fatalf("re-add trusted key: %v", err)
+	}
+
+	if len(keys) != 1 || keys[0].Name != "Sam (laptop)" {
+		t.Fatalf("expected one renamed key, got %+v", keys)
+	}
+
+	if _, ok := FindTrustedKey(keys, identity.PublicKey); !ok {
+		t.Fatal("expected key to be trusted")
+	}
+
+	if _, err := AddTrustedKey(keys, "Bad", "not-a-key", now); !errors.Is(err, ErrPublicKeyInvalid) {
+		t.Fatalf("expected ErrPublicKeyInvalid, got %v", err)
+	}
+
+	keys, removed := RemoveTrustedKey(keys, identity.Fingerprint())
+	if !removed || len(keys) != 0 {
+		t.Fatalf("expected key removal by fingerprint, got removed=%v keys=%+v", removed, keys)
+	}
+}
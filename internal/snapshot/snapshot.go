@@ -0,0 +1,545 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/fsops"
+)
+
+var (
+	ErrRootPathRequired       = errors.New("snapshot root path is required")
+	ErrProfileNameRequired    = errors.New("profile name is required")
+	ErrProfileNameInvalid     = errors.New("profile name contains invalid characters")
+	ErrSnapshotIDRequired     = errors.New("snapshot id is required")
+	ErrSnapshotIDInvalid      = errors.New("snapshot id is invalid")
+	ErrSnapshotNotFound       = errors.New("snapshot not found")
+	ErrFileOperationsRequired = errors.New("file operations are required")
+)
+
+const (
+	DirName                       = ".snapshots"
+	metadataFileName              = "snapshot.json"
+	savegameDirName               = "savegame"
+	wrapsDirName                  = "wraps"
+	defaultMaxSnapshotsPerProfile = 20
+)
+
+type Snapshot struct {
+	ID          string    `json:"id"`
+	ProfileName string    `json:"profileName"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"createdAt"`
+	FileCount   int       `json:"fileCount"`
+	TotalBytes  int64     `json:"totalBytes"`
+	// PruneWarning is set by Create when the snapshot was written but older
+	// ones could not be pruned; it is never stored with the snapshot.
+	PruneWarning string `json:"pruneWarning,omitempty"`
+}
+
+type FileEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+type Details struct {
+	Snapshot Snapshot    `json:"snapshot"`
+	Files    []FileEntry `json:"files"`
+}
+
+type Service struct {
+	rootPath   string
+	ops        fsops.Operations
+	maxPerName int
+	now        func() time.Time
+}
+
+func NewService(rootPath string, ops fsops.Operations) *Service {
+	return &Service{
+		rootPath:   rootPath,
+		ops:        ops,
+		maxPerName: defaultMaxSnapshotsPerProfile,
+		now:        time.Now,
+	}
+}
+
+func (s *Service) Create(profileName string, sourceRoot string, reason string) (Snapshot, error) {
+	name, err := validateProfileName(profileName)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := s.validateDependencies(); err != nil {
+		return Snapshot{}, err
+	}
+
+	profileDir := filepath.Join(s.rootPath, name)
+	if err := os.MkdirAll(profileDir, 0o755); err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := removeStaging(profileDir); err != nil {
+		return Snapshot{}, err
+	}
+
+	createdAt := s.now().UTC()
+	id := s.uniqueID(profileDir, createdAt)
+
+	stagingRoot, err := os.MkdirTemp(profileDir, id+".tmp-*")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer os.RemoveAll(stagingRoot)
+
+	// Snapshots never change once written, so files that are unchanged since
+	// the latest one are hard-linked from it and only changed files are
+	// copied. Repeated saves of a large profile then cost almost nothing.
+	latest := s.latestRoot(name)
+	for _, dirName := range []string{savegameDirName, wrapsDirName} {
+		reference := ""
+		if latest != "" {
+			reference = filepath.Join(latest, dirName)
+		}
+
+		if err := s.ops.SyncDir(context.Background(), filepath.Join(sourceRoot, dirName), filepath.Join(stagingRoot, dirName), reference); err != nil {
+			return Snapshot{}, err
+		}
+	}
+
+	files, err := listFiles(stagingRoot)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	item := Snapshot{
+		ID:          id,
+		ProfileName: name,
+		Reason:      strings.TrimSpace(reason),
+		CreatedAt:   createdAt,
+		FileCount:   len(files),
+	}
+	for _, file := range files {
+		item.TotalBytes += file.Size
+	}
+
+	if err := writeMetadata(stagingRoot, item); err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := os.Rename(stagingRoot, filepath.Join(profileDir, id)); err != nil {
+		return Snapshot{}, err
+	}
+
+	// The snapshot is safely written, so failing to tidy up older ones must
+	// not fail the save or restore that asked for it.
+	if err := s.prune(name); err != nil {
+		item.PruneWarning = fmt.Sprintf("older snapshots could not be pruned: %v", err)
+	}
+
+	return item, nil
+}
+
+func (s *Service) List(profileName string) ([]Snapshot, error) {
+	name, err := validateProfileName(profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(s.rootPath) == "" {
+		return nil, ErrRootPathRequired
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.rootPath, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Snapshot{}, nil
+		}
+		return nil, err
+	}
+
+	items := make([]Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.Contains(entry.Name(), ".tmp-") {
+			continue
+		}
+
+		item, err := readMetadata(filepath.Join(s.rootPath, name, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].ID > items[j].ID
+		}
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+
+	return items, nil
+}
+
+func (s *Service) Inspect(profileName string, snapshotID string) (Details, error) {
+	snapshotRoot, err := s.snapshotRoot(profileName, snapshotID)
+	if err != nil {
+		return Details{}, err
+	}
+
+	item, err := readMetadata(snapshotRoot)
+	if err != nil {
+		return Details{}, err
+	}
+
+	files, err := listFiles(snapshotRoot)
+	if err != nil {
+		return Details{}, err
+	}
+
+	return Details{Snapshot: item, Files: files}, nil
+}
+
+// CopyTo writes the snapshot's savegame and wraps trees into destinationRoot,
+// which is expected to be a fresh staging directory.
+func (s *Service) CopyTo(profileName string, snapshotID string, destinationRoot string) error {
+	if s.ops == nil {
+		return ErrFileOperationsRequired
+	}
+
+	snapshotRoot, err := s.snapshotRoot(profileName, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	for _, dirName := range []string{savegameDirName, wrapsDirName} {
+		if err := s.ops.CopyDir(context.Background(), filepath.Join(snapshotRoot, dirName), filepath.Join(destinationRoot, dirName)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) Delete(profileName string, snapshotID string) error {
+	if s.ops == nil {
+		return ErrFileOperationsRequired
+	}
+
+	snapshotRoot, err := s.snapshotRoot(profileName, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ops.RemoveDir(snapshotRoot); err != nil {
+		return err
+	}
+
+	return removeIfEmpty(filepath.Dir(snapshotRoot))
+}
+
+// Rename moves the snapshot history of oldName so it follows a renamed profile.
+func (s *Service) Rename(oldName string, newName string) error {
+	oldTrimmed, err := validateProfileName(oldName)
+	if err != nil {
+		return err
+	}
+
+	newTrimmed, err := validateProfileName(newName)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(s.rootPath) == "" {
+		return ErrRootPathRequired
+	}
+
+	oldPath := filepath.Join(s.rootPath, oldTrimmed)
+	if _, err := os.Stat(oldPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	newPath := filepath.Join(s.rootPath, newTrimmed)
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("snapshot history already exists for %s", newTrimmed)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(newPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		snapshotRoot := filepath.Join(newPath, entry.Name())
+		item, err := readMetadata(snapshotRoot)
+		if err != nil {
+			continue
+		}
+
+		item.ProfileName = newTrimmed
+		if err := writeMetadata(snapshotRoot, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteHistory removes every snapshot of a deleted profile, so a later
+// profile with the same name starts without it.
+func (s *Service) DeleteHistory(profileName string) error {
+	if s.ops == nil {
+		return ErrFileOperationsRequired
+	}
+
+	name, err := validateProfileName(profileName)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(s.rootPath) == "" {
+		return ErrRootPathRequired
+	}
+
+	return s.ops.RemoveDir(filepath.Join(s.rootPath, name))
+}
+
+// latestRoot returns the folder of the newest snapshot of profileName, or ""
+// when it has none.
+func (s *Service) latestRoot(profileName string) string {
+	items, err := s.List(profileName)
+	if err != nil || len(items) == 0 {
+		return ""
+	}
+
+	return filepath.Join(s.rootPath, profileName, items[0].ID)
+}
+
+func (s *Service) prune(profileName string) error {
+	if s.maxPerName <= 0 {
+		return nil
+	}
+
+	items, err := s.List(profileName)
+	if err != nil {
+		return err
+	}
+
+	for i := s.maxPerName; i < len(items); i++ {
+		if err := s.ops.RemoveDir(filepath.Join(s.rootPath, profileName, items[i].ID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) snapshotRoot(profileName string, snapshotID string) (string, error) {
+	name, err := validateProfileName(profileName)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := validateSnapshotID(snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(s.rootPath) == "" {
+		return "", ErrRootPathRequired
+	}
+
+	snapshotRoot := filepath.Join(s.rootPath, name, id)
+	info, err := os.Stat(filepath.Join(snapshotRoot, metadataFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrSnapshotNotFound
+		}
+		return "", err
+	}
+
+	if info.IsDir() {
+		return "", ErrSnapshotNotFound
+	}
+
+	return snapshotRoot, nil
+}
+
+func (s *Service) uniqueID(profileDir string, createdAt time.Time) string {
+	base := createdAt.Format("20060102-150405.000")
+	base = strings.ReplaceAll(base, ".", "")
+	id := base
+	for attempt := 1; ; attempt++ {
+		if _, err := os.Stat(filepath.Join(profileDir, id)); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, attempt)
+	}
+}
+
+func (s *Service) validateDependencies() error {
+	if strings.TrimSpace(s.rootPath) == "" {
+		return ErrRootPathRequired
+	}
+
+	if s.ops == nil {
+		return ErrFileOperationsRequired
+	}
+
+	return nil
+}
+
+func readMetadata(snapshotRoot string) (Snapshot, error) {
+	content, err := os.ReadFile(filepath.Join(snapshotRoot, metadataFileName))
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	var item Snapshot
+	if err := json.Unmarshal(content, &item); err != nil {
+		return Snapshot{}, err
+	}
+
+	item.ID = filepath.Base(snapshotRoot)
+	return item, nil
+}
+
+func writeMetadata(snapshotRoot string, item Snapshot) error {
+	content, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(snapshotRoot, metadataFileName), content, 0o644)
+}
+
+func listFiles(snapshotRoot string) ([]FileEntry, error) {
+	files := make([]FileEntry, 0)
+	for _, dirName := range []string{savegameDirName, wrapsDirName} {
+		base := filepath.Join(snapshotRoot, dirName)
+		err := filepath.WalkDir(base, func(path string, d os.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			relPath, err := filepath.Rel(snapshotRoot, path)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, FileEntry{
+				Path:    filepath.ToSlash(relPath),
+				Size:    info.Size(),
+				ModTime: info.ModTime().UTC(),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files, nil
+}
+
+// removeStaging drops the staging folders of snapshots that were interrupted
+// before they were renamed into place. Snapshots are only taken under the
+// SaveGame lock, so none of them can still be in use.
+func removeStaging(profileDir string) error {
+	entries, err := os.ReadDir(profileDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			if err := os.RemoveAll(filepath.Join(profileDir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func removeIfEmpty(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if len(entries) == 0 {
+		return os.Remove(path)
+	}
+
+	return nil
+}
+
+func validateProfileName(profileName string) (string, error) {
+	trimmed := strings.TrimSpace(profileName)
+	if trimmed == "" {
+		return "", ErrProfileNameRequired
+	}
+
+	if strings.ContainsAny(trimmed, `<>:"/\|?*`) {
+		return "", ErrProfileNameInvalid
+	}
+
+	if strings.HasSuffix(trimmed, ".") || strings.HasSuffix(trimmed, " ") {
+		return "", ErrProfileNameInvalid
+	}
+
+	if trimmed == "." || trimmed == ".." {
+		return "", ErrProfileNameInvalid
+	}
+
+	return trimmed, nil
+}
+
+func validateSnapshotID(snapshotID string) (string, error) {
+	trimmed := strings.TrimSpace(snapshotID)
+	if trimmed == "" {
+		return "", ErrSnapshotIDRequired
+	}
+
+	for _, r := range trimmed {
+		if (r < '0' || r > '9') && r != '-' {
+			return "", ErrSnapshotIDInvalid
+		}
+	}
+
+	return trimmed, nil
+}
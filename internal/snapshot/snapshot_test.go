@@ -0,0 +1,283 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"heat-save-manager/internal/fsops"
+)
+
+func TestCreateListAndInspectSnapshot(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profileRoot := filepath.Join(root, "Profiles", "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save-data")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap")
+
+	svc := NewService(filepath.Join(root, DirName), fsops.NewLocal())
+	svc.now = func() time.Time { return time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC) }
+
+	created, err := svc.Create("ProfileAlpha", profileRoot, "save")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	if created.FileCount != 2 || created.TotalBytes != int64(len("save-data")+len("wrap")) {
+		t.Fatalf("unexpected snapshot totals: %+v", created)
+	}
+
+	items, err := svc.List("ProfileAlpha")
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+
+	if len(items) != 1 || items[0].ID != created.ID || items[0].Reason != "save" {
+		t.Fatalf("unexpected snapshot list: %+v", items)
+	}
+
+	details, err := svc.Inspect("ProfileAlpha", created.ID)
+	if err != nil {
+		t.Fatalf("inspect snapshot: %v", err)
+	}
+
+	if len(details.Files) != 2 || details.Files[0].Path != "savegame/slot.sav" || details.Files[1].Path != "wraps/wrap.txt" {
+		t.Fatalf("unexpected snapshot files: %+v", details.Files)
+	}
+}
+
+func TestCreateKeepsSnapshotImmutableAfterSourceChanges(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profileRoot := filepath.Join(root, "Profiles", "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "v1")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap")
+
+	svc := NewService(filepath.Join(root, DirName), fsops.NewLocal())
+	created, err := svc.Create("ProfileAlpha", profileRoot, "save")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "v2")
+
+	restoreRoot := filepath.Join(root, "restore")
+	if err := svc.CopyTo("ProfileAlpha", created.ID, restoreRoot); err != nil {
+		t.Fatalf("copy snapshot: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(restoreRoot, "savegame", "slot.sav"), "v1")
+	assertFileContent(t, filepath.Join(restoreRoot, "wraps", "wrap.txt"), "wrap")
+}
+
+func TestCreateLinksFilesUnchangedSinceLatestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profileRoot := filepath.Join(root, "Profiles", "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "v1")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap")
+
+	svc := NewService(filepath.Join(root, DirName), fsops.NewLocal())
+	first, err := svc.Create("ProfileAlpha", profileRoot, "save")
+	if err != nil {
+		t.Fatalf("create first snapshot: %v", err)
+	}
+
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "version-two")
+	second, err := svc.Create("ProfileAlpha", profileRoot, "save")
+	if err != nil {
+		t.Fatalf("create second snapshot: %v", err)
+	}
+
+	snapshotFile := func(id string, path string) os.FileInfo {
+		t.Helper()
+		info, err := os.Stat(filepath.Join(root, DirName, "ProfileAlpha", id, filepath.FromSlash(path)))
+		if err != nil {
+			t.Fatalf("stat %s in %s: %v", path, id, err)
+		}
+		return info
+	}
+
+	if !os.SameFile(snapshotFile(first.ID, "wraps/wrap.txt"), snapshotFile(second.ID, "wraps/wrap.txt")) {
+		t.Fatal("expected the unchanged wrap to be linked from the previous snapshot")
+	}
+
+	if os.SameFile(snapshotFile(first.ID, "savegame/slot.sav"), snapshotFile(second.ID, "savegame/slot.sav")) {
+		t.Fatal("expected the changed save to be copied")
+	}
+
+	assertFileContent(t, filepath.Join(root, DirName, "ProfileAlpha", first.ID, "savegame", "slot.sav"), "v1")
+	assertFileContent(t, filepath.Join(root, DirName, "ProfileAlpha", second.ID, "savegame", "slot.sav"), "version-two")
+}
+
+func TestCreatePrunesOldestSnapshots(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profileRoot := filepath.Join(root, "Profiles", "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap")
+
+	svc := NewService(filepath.Join(root, DirName), fsops.NewLocal())
+	svc.maxPerName = 2
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		at := base.Add(time.Duration(i) * time.Minute)
+		svc.now = func() time.Time { return at }
+
+		created, err := svc.Create("ProfileAlpha", profileRoot, "save")
+		if err != nil {
+			t.Fatalf("create snapshot %d: %v", i, err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	items, err := svc.List("ProfileAlpha")
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+
+	if len(items) != 2 || items[0].ID != ids[2] || items[1].ID != ids[1] {
+		t.Fatalf("expected newest two snapshots, got %+v", items)
+	}
+}
+
+func TestCreateReportsPruneFailureAsWarning(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profileRoot := filepath.Join(root, "Profiles", "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap")
+
+	svc := NewService(filepath.Join(root, DirName), failingRemoveOps{fsops.NewLocal()})
+	svc.maxPerName = 1
+
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var created Snapshot
+	for i := 0; i < 2; i++ {
+		at := base.Add(time.Duration(i) * time.Minute)
+		svc.now = func() time.Time { return at }
+
+		var err error
+		created, err = svc.Create("ProfileAlpha", profileRoot, "save")
+		if err != nil {
+			t.Fatalf("create snapshot %d: %v", i, err)
+		}
+	}
+
+	if created.PruneWarning == "" {
+		t.Fatalf("expected a prune warning, got %+v", created)
+	}
+
+	if _, err := svc.Inspect("ProfileAlpha", created.ID); err != nil {
+		t.Fatalf("expected the new snapshot to be kept: %v", err)
+	}
+}
+
+type failingRemoveOps struct {
+	*fsops.Local
+}
+
+func (failingRemoveOps) RemoveDir(string) error {
+	return errors.New("remove failed")
+}
+
+func TestCreateRemovesStagingLeftByInterruptedSnapshots(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profileRoot := filepath.Join(root, "Profiles", "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap")
+
+	leftover := filepath.Join(root, DirName, "ProfileAlpha", "20260301-100000000.tmp-12345")
+	writeFile(t, filepath.Join(leftover, "savegame.sync-1", "slot.sav"), "half-written")
+
+	svc := NewService(filepath.Join(root, DirName), fsops.NewLocal())
+	if _, err := svc.Create("ProfileAlpha", profileRoot, "save"); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Fatalf("expected the interrupted snapshot's staging removed, got %v", err)
+	}
+}
+
+func TestDeleteAndRenameSnapshots(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	profileRoot := filepath.Join(root, "Profiles", "ProfileAlpha")
+	writeFile(t, filepath.Join(profileRoot, "savegame", "slot.sav"), "save")
+	writeFile(t, filepath.Join(profileRoot, "wraps", "wrap.txt"), "wrap")
+
+	svc := NewService(filepath.Join(root, DirName), fsops.NewLocal())
+	first, err := svc.Create("ProfileAlpha", profileRoot, "save")
+	if err != nil {
+		t.Fatalf("create first snapshot: %v", err)
+	}
+
+	second, err := svc.Create("ProfileAlpha", profileRoot, "save")
+	if err != nil {
+		t.Fatalf("create second snapshot: %v", err)
+	}
+
+	if err := svc.Delete("ProfileAlpha", first.ID); err != nil {
+		t.Fatalf("delete snapshot: %v", err)
+	}
+
+	if _, err := svc.Inspect("ProfileAlpha", first.ID); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Fatalf("expected ErrSnapshotNotFound, got %v", err)
+	}
+
+	if err := svc.Rename("ProfileAlpha", "ProfileBeta"); err != nil {
+		t.Fatalf("rename snapshots: %v", err)
+	}
+
+	items, err := svc.List("ProfileBeta")
+	if err != nil {
+		t.Fatalf("list renamed snapshots: %v", err)
+	}
+
+	if len(items) != 1 || items[0].ID != second.ID || items[0].ProfileName != "ProfileBeta" {
+		t.Fatalf("unexpected renamed snapshots: %+v", items)
+	}
+}
+
+func TestInspectRejectsInvalidSnapshotID(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(t.TempDir(), fsops.NewLocal())
+	if _, err := svc.Inspect("ProfileAlpha", "../escape"); !errors.Is(err, ErrSnapshotIDInvalid) {
+		t.Fatalf("expected ErrSnapshotIDInvalid, got %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func assertFileContent(t *testing.T, path string, expected string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(data) != expected {
+		t.Fatalf("expected %q, got %q", expected, string(data))
+	}
+}
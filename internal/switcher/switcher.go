@@ -1,6 +1,7 @@
 package switcher
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -9,7 +10,9 @@ import (
 	"time"
 
 	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/journal"
 	"heat-save-manager/internal/profiles"
+	"heat-save-manager/internal/savelock"
 )
 
 var (
@@ -32,17 +35,40 @@ type Result struct {
 	ProfileName string
 	SwitchedAt  time.Time
 	RolledBack  bool
+	BackupID    string
+	// BackupWarning is set when the switch succeeded but its pre-switch
+	// backup could not be kept; the backup is then left where the switch
+	// put it, at the path the warning names.
+	BackupWarning string
 }
 
 type MarkerWriter interface {
 	WriteActiveProfile(profileName string) error
 }
 
+type GameGuard interface {
+	Check() error
+}
+
+// BackupKeeper takes ownership of the pre-switch backup tree after a
+// successful switch instead of letting it be deleted.
+type BackupKeeper interface {
+	Keep(backupRoot string, fromProfile string, toProfile string) (string, error)
+}
+
+type markerReader interface {
+	ReadActiveProfile() (string, error)
+}
+
 type Service struct {
 	saveGamePath string
 	profilesPath string
 	marker       MarkerWriter
 	ops          fsops.Operations
+	guard        GameGuard
+	backups      BackupKeeper
+	journal      *journal.Journal
+	lock         *savelock.Locker
 	now          func() time.Time
 }
 
@@ -56,7 +82,36 @@ func NewService(saveGamePath string, profilesPath string, marker MarkerWriter, o
 	}
 }
 
+func (s *Service) SetGameGuard(guard GameGuard) {
+	s.guard = guard
+}
+
+func (s *Service) SetBackupKeeper(backups BackupKeeper) {
+	s.backups = backups
+}
+
+// SetJournal records each switch step so an interrupted switch can be
+// recovered on the next start.
+func (s *Service) SetJournal(j *journal.Journal) {
+	s.journal = j
+}
+
+// SetLocker makes Switch hold the SaveGame folder lock. Passing a locker the
+// caller already holds is fine; it is reentrant.
+func (s *Service) SetLocker(lock *savelock.Locker) {
+	s.lock = lock
+}
+
 func (s *Service) Switch(params Params) (Result, error) {
+	return s.SwitchContext(context.Background(), params)
+}
+
+// SwitchContext is Switch with cancellation and progress reporting through
+// ctx; the caller plans the progress totals. The root folders are moved aside
+// into the backup rather than copied, so only the profile is copied.
+// Cancelling before the profile is fully copied into the root save rolls the
+// root back; once the copy is complete the switch finishes.
+func (s *Service) SwitchContext(ctx context.Context, params Params) (Result, error) {
 	profileName, err := validateProfileName(params.ProfileName)
 	if err != nil {
 		return Result{}, err
@@ -83,6 +138,25 @@ func (s *Service) Switch(params Params) (Result, error) {
 		return Result{}, err
 	}
 
+	release, err := s.lock.Acquire("switch")
+	if err != nil {
+		return Result{}, err
+	}
+	defer release()
+
+	if s.guard != nil {
+		if err := s.guard.Check(); err != nil {
+			return Result{}, err
+		}
+	}
+
+	targetSavegame := filepath.Join(s.saveGamePath, savegameDirName)
+	targetWraps := filepath.Join(s.saveGamePath, wrapsDirName)
+	profileSavegame := filepath.Join(profileRoot, savegameDirName)
+	profileWraps := filepath.Join(profileRoot, wrapsDirName)
+
+	previousProfile := s.readPreviousProfile()
+
 	backupParent := filepath.Join(s.saveGamePath, ".backup")
 	if err := os.MkdirAll(backupParent, 0o755); err != nil {
 		return Result{}, err
@@ -93,58 +167,174 @@ func (s *Service) Switch(params Params) (Result, error) {
 		return Result{}, err
 	}
 
+	record, err := s.journal.Begin(journal.Entry{
+		Operation:       journal.OperationSwitch,
+		Phase:           journal.PhaseStaging,
+		SwitchTarget:    profileName,
+		PreviousProfile: previousProfile,
+		BackupRoot:      backupRoot,
+	})
+	if err != nil {
+		_ = s.cleanupBackupTree(backupRoot)
+		return Result{}, fmt.Errorf("record switch: %w", err)
+	}
+
+	// Staging folders the copies below create next to the root folders are
+	// journaled too, so a crash never leaves them behind.
+	ctx = record.Track(ctx)
+
 	backupSavegame := filepath.Join(backupRoot, savegameDirName)
 	backupWraps := filepath.Join(backupRoot, wrapsDirName)
 
-	hadSavegame, err := s.backupIfExists(filepath.Join(s.saveGamePath, savegameDirName), backupSavegame)
+	hadSavegame, err := dirExists(targetSavegame)
 	if err != nil {
-		return Result{}, err
+		return Result{}, s.abandon(record, backupRoot, err)
 	}
 
-	hadWraps, err := s.backupIfExists(filepath.Join(s.saveGamePath, wrapsDirName), backupWraps)
+	hadWraps, err := dirExists(targetWraps)
 	if err != nil {
-		return Result{}, err
+		return Result{}, s.abandon(record, backupRoot, err)
 	}
 
-	targetSavegame := filepath.Join(s.saveGamePath, savegameDirName)
-	targetWraps := filepath.Join(s.saveGamePath, wrapsDirName)
-	profileSavegame := filepath.Join(profileRoot, savegameDirName)
-	profileWraps := filepath.Join(profileRoot, wrapsDirName)
+	// The phase is recorded before anything is moved: from here on recovery
+	// puts back whatever already sits in the backup.
+	err = record.Advance(journal.PhaseReplacing, func(entry *journal.Entry) {
+		entry.HadSavegame = hadSavegame
+		entry.HadWraps = hadWraps
+	})
+	if err != nil {
+		return Result{}, s.abandon(record, backupRoot, fmt.Errorf("record switch: %w", err))
+	}
+
+	// Rollback must run to completion even when ctx is what stopped the switch.
+	rollbackCtx := context.WithoutCancel(ctx)
 
-	if err := s.ops.ReplaceDir(profileSavegame, targetSavegame); err != nil {
-		rollbackErr := s.rollback(targetSavegame, targetWraps, backupSavegame, backupWraps, hadSavegame, hadWraps)
+	err = s.moveAside(ctx, targetSavegame, backupSavegame, hadSavegame)
+	if err == nil {
+		err = s.moveAside(ctx, targetWraps, backupWraps, hadWraps)
+	}
+	if err != nil {
+		rollbackErr := s.rollback(rollbackCtx, targetSavegame, targetWraps, backupSavegame, backupWraps, hadSavegame, hadWraps)
 		if rollbackErr != nil {
 			return Result{ProfileName: profileName, RolledBack: true}, fmt.Errorf("switch failed: %w; rollback failed: %v", err, rollbackErr)
 		}
 
+		_ = record.Finish()
 		return Result{ProfileName: profileName, RolledBack: true}, err
 	}
 
-	if err := s.ops.ReplaceDir(profileWraps, targetWraps); err != nil {
-		rollbackErr := s.rollback(targetSavegame, targetWraps, backupSavegame, backupWraps, hadSavegame, hadWraps)
+	if err := s.fill(ctx, profileSavegame, targetSavegame, backupSavegame); err != nil {
+		rollbackErr := s.rollback(rollbackCtx, targetSavegame, targetWraps, backupSavegame, backupWraps, hadSavegame, hadWraps)
 		if rollbackErr != nil {
 			return Result{ProfileName: profileName, RolledBack: true}, fmt.Errorf("switch failed: %w; rollback failed: %v", err, rollbackErr)
 		}
 
+		_ = record.Finish()
 		return Result{ProfileName: profileName, RolledBack: true}, err
 	}
 
+	if err := s.fill(ctx, profileWraps, targetWraps, backupWraps); err != nil {
+		rollbackErr := s.rollback(rollbackCtx, targetSavegame, targetWraps, backupSavegame, backupWraps, hadSavegame, hadWraps)
+		if rollbackErr != nil {
+			return Result{ProfileName: profileName, RolledBack: true}, fmt.Errorf("switch failed: %w; rollback failed: %v", err, rollbackErr)
+		}
+
+		_ = record.Finish()
+		return Result{ProfileName: profileName, RolledBack: true}, err
+	}
+
+	if err := record.Advance(journal.PhaseCommitting, nil); err != nil {
+		rollbackErr := s.rollback(rollbackCtx, targetSavegame, targetWraps, backupSavegame, backupWraps, hadSavegame, hadWraps)
+		if rollbackErr != nil {
+			return Result{ProfileName: profileName, RolledBack: true}, fmt.Errorf("record switch: %w; rollback failed: %v", err, rollbackErr)
+		}
+
+		_ = record.Finish()
+		return Result{ProfileName: profileName, RolledBack: true}, fmt.Errorf("record switch: %w", err)
+	}
+
 	if err := s.marker.WriteActiveProfile(profileName); err != nil {
-		rollbackErr := s.rollback(targetSavegame, targetWraps, backupSavegame, backupWraps, hadSavegame, hadWraps)
+		rollbackErr := s.rollback(rollbackCtx, targetSavegame, targetWraps, backupSavegame, backupWraps, hadSavegame, hadWraps)
 		if rollbackErr != nil {
 			return Result{ProfileName: profileName, RolledBack: true}, fmt.Errorf("marker update failed: %w; rollback failed: %v", err, rollbackErr)
 		}
 
+		_ = record.Finish()
 		return Result{ProfileName: profileName, RolledBack: true}, err
 	}
 
-	_ = s.cleanupBackupTree(backupRoot)
+	backupID, keepErr := s.keepBackup(backupRoot, previousProfile, profileName)
+	if backupID != "" || keepErr == nil {
+		_ = s.cleanupBackupTree(backupRoot)
+	}
+	_ = record.Finish()
 
-	return Result{
+	result := Result{
 		ProfileName: profileName,
 		SwitchedAt:  s.now().UTC(),
 		RolledBack:  false,
-	}, nil
+		BackupID:    backupID,
+	}
+	if keepErr != nil {
+		result.BackupWarning = backupWarning(backupID, backupRoot, keepErr)
+	}
+
+	return result, nil
+}
+
+// fill copies a profile folder into the root. Without a backup keeper the
+// moved-aside root folder is deleted after the switch, so files it shares with
+// the profile are taken over from it instead of being copied; a kept backup
+// must not share files with the live root the game writes to.
+func (s *Service) fill(ctx context.Context, source string, target string, movedAside string) error {
+	if s.backups != nil {
+		return s.ops.ReplaceDir(ctx, source, target)
+	}
+
+	return s.ops.SyncDir(ctx, source, target, movedAside)
+}
+
+// abandon drops a switch that failed before the root save was touched.
+func (s *Service) abandon(record *journal.Record, backupRoot string, cause error) error {
+	if err := s.cleanupBackupTree(backupRoot); err != nil {
+		return fmt.Errorf("%w; cleanup failed: %v", cause, err)
+	}
+
+	_ = record.Finish()
+	return cause
+}
+
+// keepBackup hands the backup tree to the keeper. An error with an ID means
+// the backup was kept but tidying up older ones failed; without an ID the
+// tree is still at backupRoot and must not be removed.
+func (s *Service) keepBackup(backupRoot string, fromProfile string, toProfile string) (string, error) {
+	if s.backups == nil {
+		return "", nil
+	}
+
+	return s.backups.Keep(backupRoot, fromProfile, toProfile)
+}
+
+func backupWarning(backupID string, backupRoot string, err error) string {
+	if backupID != "" {
+		return fmt.Sprintf("pre-switch backup %s was kept, but older backups could not be pruned: %v", backupID, err)
+	}
+
+	return fmt.Sprintf("pre-switch backup could not be kept and was left at %s: %v", backupRoot, err)
+}
+
+func (s *Service) readPreviousProfile() string {
+	reader, ok := s.marker.(markerReader)
+	if !ok {
+		return ""
+	}
+
+	previous, err := reader.ReadActiveProfile()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(previous)
 }
 
 func validateProfileName(profileName string) (string, error) {
@@ -168,8 +358,8 @@ func validateProfileName(profileName string) (string, error) {
 	return trimmed, nil
 }
 
-func (s *Service) backupIfExists(source string, destination string) (bool, error) {
-	info, err := os.Stat(source)
+func dirExists(path string) (bool, error) {
+	info, err := os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return false, nil
@@ -179,34 +369,50 @@ func (s *Service) backupIfExists(source string, destination string) (bool, error
 	}
 
 	if !info.IsDir() {
-		return false, fmt.Errorf("expected directory: %s", source)
+		return false, fmt.Errorf("expected directory: %s", path)
 	}
 
-	if err := s.ops.CopyDir(source, destination); err != nil {
-		return false, err
+	return true, nil
+}
+
+func (s *Service) moveAside(ctx context.Context, source string, backup string, exists bool) error {
+	if !exists {
+		return nil
 	}
 
-	return true, nil
+	return s.ops.MoveDir(ctx, source, backup)
 }
 
-func (s *Service) rollback(targetSavegame string, targetWraps string, backupSavegame string, backupWraps string, hadSavegame bool, hadWraps bool) error {
-	if err := s.restoreDir(targetSavegame, backupSavegame, hadSavegame); err != nil {
+func (s *Service) rollback(ctx context.Context, targetSavegame string, targetWraps string, backupSavegame string, backupWraps string, hadSavegame bool, hadWraps bool) error {
+	if err := s.restoreDir(ctx, targetSavegame, backupSavegame, hadSavegame); err != nil {
 		return err
 	}
 
-	if err := s.restoreDir(targetWraps, backupWraps, hadWraps); err != nil {
+	if err := s.restoreDir(ctx, targetWraps, backupWraps, hadWraps); err != nil {
 		return err
 	}
 
 	return s.cleanupBackupTree(filepath.Dir(backupSavegame))
 }
 
-func (s *Service) restoreDir(target string, backup string, hadOriginal bool) error {
+// restoreDir puts the original folder back. When it had one but the backup
+// holds nothing, it was never moved aside and target is still the original.
+func (s *Service) restoreDir(ctx context.Context, target string, backup string, hadOriginal bool) error {
 	if hadOriginal {
-		return s.ops.ReplaceDir(backup, target)
+		if exists, err := dirExists(backup); err != nil || !exists {
+			return err
+		}
+	}
+
+	if err := s.ops.RemoveDir(target); err != nil {
+		return err
+	}
+
+	if !hadOriginal {
+		return nil
 	}
 
-	return s.ops.RemoveDir(target)
+	return s.ops.MoveDir(ctx, backup, target)
 }
 
 func (s *Service) cleanupBackupTree(backupRoot string) error {
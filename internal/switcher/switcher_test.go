@@ -1,9 +1,11 @@
 package switcher
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -56,6 +58,33 @@ func TestSwitchReplacesRootAndUpdatesMarker(t *testing.T) {
 	assertBackupRootMissing(t, saveGamePath)
 }
 
+func TestSwitchMovesRootAsideInsteadOfCopyingIt(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createProfile(t, profilesPath, "ProfileBeta", "beta-save", "beta-wrap")
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "alpha-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "alpha-wrap")
+
+	ops := &countingOps{base: fsops.NewLocal()}
+	service := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), ops)
+
+	if _, err := service.Switch(Params{ProfileName: "ProfileBeta"}); err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	if ops.copyCalls != 0 || ops.moveCalls != 2 || ops.replaceCalls != 0 || ops.syncCalls != 2 {
+		t.Fatalf("expected 2 moves and 2 syncs without extra copies, got %+v", ops)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "beta-save")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "beta-wrap")
+	assertBackupRootMissing(t, saveGamePath)
+}
+
 func TestSwitchRollsBackWhenMarkerWriteFails(t *testing.T) {
 	t.Parallel()
 
@@ -213,6 +242,132 @@ func TestSwitchRequiresValidInputs(t *testing.T) {
 	}
 }
 
+func TestSwitchHandsBackupToKeeperOnSuccess(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	profileName := "ProfileBeta"
+
+	createProfile(t, profilesPath, profileName, "new-save", "new-wrap")
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "old-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "old-wrap")
+
+	markerStore := marker.NewStore(saveGamePath)
+	if err := markerStore.WriteActiveProfile("ProfileAlpha"); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+
+	keeper := &recordingKeeper{destination: filepath.Join(root, "kept")}
+	service := NewService(saveGamePath, profilesPath, markerStore, fsops.NewLocal())
+	service.SetBackupKeeper(keeper)
+
+	result, err := service.Switch(Params{ProfileName: profileName})
+	if err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	if result.BackupID != "kept-1" {
+		t.Fatalf("expected backup id from keeper, got %q", result.BackupID)
+	}
+
+	if keeper.fromProfile != "ProfileAlpha" || keeper.toProfile != profileName {
+		t.Fatalf("unexpected keeper profiles: from %q to %q", keeper.fromProfile, keeper.toProfile)
+	}
+
+	assertFileContent(t, filepath.Join(keeper.destination, "savegame", "slot.sav"), "old-save")
+	assertFileContent(t, filepath.Join(keeper.destination, "wraps", "wrap.txt"), "old-wrap")
+	assertBackupRootMissing(t, saveGamePath)
+}
+
+type recordingKeeper struct {
+	destination string
+	fromProfile string
+	toProfile   string
+}
+
+func (k *recordingKeeper) Keep(backupRoot string, fromProfile string, toProfile string) (string, error) {
+	k.fromProfile = fromProfile
+	k.toProfile = toProfile
+	if err := os.Rename(backupRoot, k.destination); err != nil {
+		return "", err
+	}
+
+	return "kept-1", nil
+}
+
+func TestSwitchKeepsBackupTreeAndWarnsWhenKeeperFails(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+
+	createProfile(t, profilesPath, "ProfileBeta", "new-save", "new-wrap")
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "old-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "old-wrap")
+
+	service := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	service.SetBackupKeeper(failingKeeper{})
+
+	result, err := service.Switch(Params{ProfileName: "ProfileBeta"})
+	if err != nil {
+		t.Fatalf("switch profile: %v", err)
+	}
+
+	if result.BackupID != "" || !strings.Contains(result.BackupWarning, "disk full") {
+		t.Fatalf("expected a backup warning, got %+v", result)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "new-save")
+
+	kept, err := filepath.Glob(filepath.Join(saveGamePath, ".backup", "switch-*", "savegame", "slot.sav"))
+	if err != nil || len(kept) != 1 {
+		t.Fatalf("expected the backup tree to stay in place, got %v err=%v", kept, err)
+	}
+	assertFileContent(t, kept[0], "old-save")
+}
+
+type failingKeeper struct{}
+
+func (failingKeeper) Keep(backupRoot string, fromProfile string, toProfile string) (string, error) {
+	return "", errors.New("disk full")
+}
+
+func TestSwitchRefusesWhileGameIsRunning(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	saveGamePath := filepath.Join(root, "SaveGame")
+	profilesPath := filepath.Join(saveGamePath, "Profiles")
+	profileName := "ProfileAlpha"
+
+	createProfile(t, profilesPath, profileName, "new-save", "new-wrap")
+	createDirWithFile(t, filepath.Join(saveGamePath, "savegame"), "slot.sav", "old-save")
+	createDirWithFile(t, filepath.Join(saveGamePath, "wraps"), "wrap.txt", "old-wrap")
+
+	service := NewService(saveGamePath, profilesPath, marker.NewStore(saveGamePath), fsops.NewLocal())
+	service.SetGameGuard(runningGuard{})
+
+	_, err := service.Switch(Params{ProfileName: profileName})
+	if !errors.Is(err, errGameRunningForTest) {
+		t.Fatalf("expected guard error, got %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(saveGamePath, "savegame", "slot.sav"), "old-save")
+	assertFileContent(t, filepath.Join(saveGamePath, "wraps", "wrap.txt"), "old-wrap")
+	assertBackupRootMissing(t, saveGamePath)
+}
+
+var errGameRunningForTest = errors.New("game is running")
+
+type runningGuard struct{}
+
+func (runningGuard) Check() error {
+	return errGameRunningForTest
+}
+
 type failingMarker struct{}
 
 func (f failingMarker) WriteActiveProfile(profileName string) error {
@@ -223,19 +378,28 @@ func (f failingMarker) WriteActiveProfile(profileName string) error {
 type failOnSecondReplaceOps struct {
 	base         *fsops.Local
 	replaceCalls int
+	syncCalls    int
 }
 
-func (f *failOnSecondReplaceOps) CopyDir(source string, destination string) error {
-	return f.base.CopyDir(source, destination)
+func (f *failOnSecondReplaceOps) CopyDir(ctx context.Context, source string, destination string) error {
+	return f.base.CopyDir(ctx, source, destination)
 }
 
-func (f *failOnSecondReplaceOps) ReplaceDir(source string, destination string) error {
+func (f *failOnSecondReplaceOps) ReplaceDir(ctx context.Context, source string, destination string) error {
 	f.replaceCalls++
 	if f.replaceCalls == 2 {
 		return errors.New("forced replace failure")
 	}
 
-	return f.base.ReplaceDir(source, destination)
+	return f.base.ReplaceDir(ctx, source, destination)
+}
+
+func (f *failOnSecondReplaceOps) MoveDir(ctx context.Context, source string, destination string) error {
+	return f.base.MoveDir(ctx, source, destination)
+}
+
+func (f *failOnSecondReplaceOps) SyncDir(ctx context.Context, source string, destination string, reference string) error {
+	return f.ReplaceDir(ctx, source, destination)
 }
 
 func (f *failOnSecondReplaceOps) RemoveDir(path string) error {
@@ -245,20 +409,21 @@ func (f *failOnSecondReplaceOps) RemoveDir(path string) error {
 type failOnFirstReplaceAfterDeleteOps struct {
 	base         *fsops.Local
 	replaceCalls int
+	syncCalls    int
 }
 
-func (f *failOnFirstReplaceAfterDeleteOps) CopyDir(source string, destination string) error {
-	return f.base.CopyDir(source, destination)
+func (f *failOnFirstReplaceAfterDeleteOps) CopyDir(ctx context.Context, source string, destination string) error {
+	return f.base.CopyDir(ctx, source, destination)
 }
 
-func (f *failOnFirstReplaceAfterDeleteOps) ReplaceDir(source string, destination string) error {
+func (f *failOnFirstReplaceAfterDeleteOps) ReplaceDir(ctx context.Context, source string, destination string) error {
 	f.replaceCalls++
 	if f.replaceCalls == 1 {
 		tmpDir := destination + ".forced-failure"
 		if err := os.RemoveAll(tmpDir); err != nil {
 			return err
 		}
-		if err := f.base.CopyDir(source, tmpDir); err != nil {
+		if err := f.base.CopyDir(ctx, source, tmpDir); err != nil {
 			return err
 		}
 		if err := os.RemoveAll(destination); err != nil {
@@ -269,7 +434,15 @@ func (f *failOnFirstReplaceAfterDeleteOps) ReplaceDir(source string, destination
 		return errors.New("forced first replace failure after deleting destination")
 	}
 
-	return f.base.ReplaceDir(source, destination)
+	return f.base.ReplaceDir(ctx, source, destination)
+}
+
+func (f *failOnFirstReplaceAfterDeleteOps) MoveDir(ctx context.Context, source string, destination string) error {
+	return f.base.MoveDir(ctx, source, destination)
+}
+
+func (f *failOnFirstReplaceAfterDeleteOps) SyncDir(ctx context.Context, source string, destination string, reference string) error {
+	return f.ReplaceDir(ctx, source, destination)
 }
 
 func (f *failOnFirstReplaceAfterDeleteOps) RemoveDir(path string) error {
@@ -318,3 +491,35 @@ func assertBackupRootMissing(t *testing.T, saveGamePath string) {
 		t.Fatalf("expected .backup root to be removed, got %v", err)
 	}
 }
+
+type countingOps struct {
+	base         *fsops.Local
+	copyCalls    int
+	moveCalls    int
+	replaceCalls int
+	syncCalls    int
+}
+
+func (f *countingOps) CopyDir(ctx context.Context, source string, destination string) error {
+	f.copyCalls++
+	return f.base.CopyDir(ctx, source, destination)
+}
+
+func (f *countingOps) ReplaceDir(ctx context.Context, source string, destination string) error {
+	f.replaceCalls++
+	return f.base.ReplaceDir(ctx, source, destination)
+}
+
+func (f *countingOps) MoveDir(ctx context.Context, source string, destination string) error {
+	f.moveCalls++
+	return f.base.MoveDir(ctx, source, destination)
+}
+
+func (f *countingOps) SyncDir(ctx context.Context, source string, destination string, reference string) error {
+	f.syncCalls++
+	return f.base.SyncDir(ctx, source, destination, reference)
+}
+
+func (f *countingOps) RemoveDir(path string) error {
+	return f.base.RemoveDir(path)
+}
@@ -0,0 +1,94 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/audit"
+	"heat-save-manager/internal/lifecycle"
+	"heat-save-manager/internal/oprunner"
+	"heat-save-manager/internal/savelock"
+)
+
+// Record runs fn and appends its outcome to the audit log, so a change shows
+// up in the same log whether the desktop app or the command-line interface
+// made it. fn may fill in details such as RolledBack on the entry it is given.
+func (w Workspace) Record(kind string, target string, paths []string, fn func(entry *audit.Entry) error) error {
+	entry := audit.Entry{Operation: kind, Target: strings.TrimSpace(target), Paths: compactPaths(paths)}
+	started := time.Now()
+	err := fn(&entry)
+
+	w.RecordResult(entry, started, err)
+	return err
+}
+
+// RecordResult appends entry for an operation that started at started and
+// finished with err.
+func (w Workspace) RecordResult(entry audit.Entry, started time.Time, err error) {
+	entry.Time = started.UTC()
+	entry.DurationMs = time.Since(started).Milliseconds()
+	entry.Outcome = auditOutcome(err)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	// A full disk or unwritable config folder must not turn a finished
+	// operation into a failed one.
+	_ = w.AuditLog.Append(entry)
+}
+
+// RecordRecovery appends one entry per interrupted operation recovery dealt
+// with, or a single failed entry when recovery could not run.
+func (w Workspace) RecordRecovery(started time.Time, actions []lifecycle.RecoveryAction, err error) {
+	if err != nil {
+		w.RecordResult(audit.Entry{Operation: "recover", Paths: compactPaths([]string{w.SaveGamePath})}, started, err)
+		return
+	}
+
+	for _, action := range actions {
+		entry := audit.Entry{Operation: "recover-" + action.Operation, Target: action.Profile, Paths: compactPaths([]string{w.SaveGamePath})}
+		entry.RolledBack = action.Outcome == lifecycle.RecoveryRolledBack
+		var actionErr error
+		if action.Outcome == lifecycle.RecoveryFailed {
+			actionErr = errors.New(action.Error)
+		}
+		w.RecordResult(entry, started, actionErr)
+	}
+}
+
+// ProfileDir is where profileName lives, or the Profiles folder when no name
+// is given.
+func (w Workspace) ProfileDir(profileName string) string {
+	if strings.TrimSpace(w.ProfilesPath) == "" {
+		return ""
+	}
+
+	return filepath.Join(w.ProfilesPath, strings.TrimSpace(profileName))
+}
+
+func auditOutcome(err error) string {
+	switch {
+	case err == nil:
+		return audit.OutcomeSucceeded
+	case errors.Is(err, context.Canceled):
+		return audit.OutcomeCancelled
+	case errors.Is(err, oprunner.ErrAlreadyQueued), errors.Is(err, oprunner.ErrQueueFull), errors.Is(err, savelock.ErrBusy):
+		return audit.OutcomeRejected
+	default:
+		return audit.OutcomeFailed
+	}
+}
+
+func compactPaths(paths []string) []string {
+	result := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if strings.TrimSpace(path) != "" {
+			result = append(result, path)
+		}
+	}
+
+	return result
+}
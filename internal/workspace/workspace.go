@@ -0,0 +1,162 @@
+package workspace
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"heat-save-manager/internal/audit"
+	"heat-save-manager/internal/backups"
+	"heat-save-manager/internal/bundle"
+	"heat-save-manager/internal/config"
+	"heat-save-manager/internal/fsops"
+	"heat-save-manager/internal/gameproc"
+	"heat-save-manager/internal/health"
+	"heat-save-manager/internal/journal"
+	"heat-save-manager/internal/lifecycle"
+	"heat-save-manager/internal/marker"
+	"heat-save-manager/internal/profiles"
+	"heat-save-manager/internal/rootsync"
+	"heat-save-manager/internal/savelock"
+	"heat-save-manager/internal/snapshot"
+	"heat-save-manager/internal/switcher"
+)
+
+// Workspace wires the profile services for one SaveGame folder according to
+// the app settings. The desktop app and the command-line interface both build
+// their services through it so they behave the same way.
+type Workspace struct {
+	SaveGamePath string
+	ProfilesPath string
+	Config       config.AppConfig
+	GameDetector gameproc.Detector
+	AppVersion   string
+	// AuditLog receives the entries written through Record; nil records
+	// nothing.
+	AuditLog *audit.Log
+}
+
+func (w Workspace) MarkerStore() *marker.Store {
+	return marker.NewStore(w.SaveGamePath)
+}
+
+func (w Workspace) Profiles() *profiles.Service {
+	return profiles.NewService(w.ProfilesPath)
+}
+
+// RootSync compares the root save with the active profile's stored copy.
+func (w Workspace) RootSync() *rootsync.Service {
+	return rootsync.NewService(w.SaveGamePath, w.ProfilesPath, w.MarkerStore())
+}
+
+func (w Workspace) Health() *health.Service {
+	return health.NewService(w.SaveGamePath, w.ProfilesPath)
+}
+
+func (w Workspace) Bundles() *bundle.Service {
+	service := bundle.NewService(w.ProfilesPath)
+	service.SetAppVersion(w.AppVersion)
+	service.SetFileOperations(w.fileOps())
+	service.SetTrustedKeys(w.Config.TrustedKeys)
+	service.SetJournal(w.Journal())
+	service.SetSnapshotService(w.Snapshots())
+	service.SetMarker(w.MarkerStore())
+
+	// The lifecycle shares the import's lock so it can reload the root save
+	// while the import still holds it.
+	lock := w.Locker()
+	keeper := w.Lifecycle()
+	keeper.SetLocker(lock)
+	service.SetLocker(lock)
+	service.SetActiveProfileKeeper(keeper)
+	return service
+}
+
+// RecoverInterrupted settles the operations a crash left half-done and
+// removes the decrypted bundle archives it left in the temp folder. Those are
+// private to the user, so failing to remove one does not fail recovery.
+func (w Workspace) RecoverInterrupted() ([]lifecycle.RecoveryAction, error) {
+	actions, err := w.Lifecycle().RecoverInterrupted()
+	if err != nil {
+		return actions, err
+	}
+
+	_ = bundle.RemovePlaintextLeftovers()
+	return actions, nil
+}
+
+func (w Workspace) Lifecycle() *lifecycle.Service {
+	service := lifecycle.NewService(w.SaveGamePath, w.ProfilesPath, w.MarkerStore(), w.fileOps())
+	service.SetSnapshotService(w.Snapshots())
+	service.SetGameGuard(w.GameGuard())
+	service.SetJournal(w.Journal())
+	service.SetLocker(w.Locker())
+	if w.Config.BackupBeforeSwitch {
+		service.SetSwitchBackups(w.SwitchBackups())
+	}
+
+	return service
+}
+
+// fileOps returns the local file operations configured by the copy settings;
+// a CopyWorkers value below two copies one file at a time.
+func (w Workspace) fileOps() *fsops.Local {
+	ops := fsops.NewParallelLocal(w.Config.CopyWorkers)
+	ops.SetCompareContent(w.Config.SyncCompareContent)
+	return ops
+}
+
+// Journal returns nil without a SaveGame folder so services skip journaling
+// instead of writing entries relative to the working directory.
+func (w Workspace) Journal() *journal.Journal {
+	dir := w.managedPath(journal.DirName)
+	if dir == "" {
+		return nil
+	}
+
+	return journal.New(dir)
+}
+
+// Locker returns the cross-process lock on the SaveGame folder, or nil when no
+// folder is configured.
+func (w Workspace) Locker() *savelock.Locker {
+	if strings.TrimSpace(w.SaveGamePath) == "" {
+		return nil
+	}
+
+	return savelock.New(w.SaveGamePath)
+}
+
+func (w Workspace) Snapshots() *snapshot.Service {
+	return snapshot.NewService(w.managedPath(snapshot.DirName), w.fileOps())
+}
+
+// SwitchBackups returns the pre-switch backup store regardless of the
+// BackupBeforeSwitch setting so existing backups stay reachable.
+func (w Workspace) SwitchBackups() *backups.Service {
+	service := backups.NewService(w.managedPath(backups.DirName), w.fileOps(), backups.Retention{
+		MaxCount: w.Config.SwitchBackupMaxCount,
+		MaxAge:   time.Duration(w.Config.SwitchBackupMaxAgeDays) * 24 * time.Hour,
+	})
+	service.SetJournal(w.Journal())
+	service.SetMarker(w.MarkerStore())
+	return service
+}
+
+// GameGuard returns nil when the game-running check is disabled in settings,
+// which is how users explicitly override the guard.
+func (w Workspace) GameGuard() switcher.GameGuard {
+	if w.GameDetector == nil || !w.Config.CheckGameRunning {
+		return nil
+	}
+
+	return gameproc.NewGuard(w.GameDetector)
+}
+
+func (w Workspace) managedPath(dirName string) string {
+	if strings.TrimSpace(w.SaveGamePath) == "" {
+		return ""
+	}
+
+	return filepath.Join(w.SaveGamePath, dirName)
+}